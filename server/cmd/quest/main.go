@@ -2,10 +2,12 @@
 // @version 1.0.0
 // @description API для управления процедурами розыска посылок и оформления заявлений о повреждении или утрате
 // @host localhost:8000
+// @securityScheme BasicAuth http basic Учётные данные администратора (ADMIN_USER, ADMIN_PASSWORD)
 package main
 
 import (
 	"log"
+	"os"
 	"tech-quest/internal/app"
 	"tech-quest/internal/configs"
 	"tech-quest/pkg/database"
@@ -16,5 +18,23 @@ func main() {
 	if err := database.RunMigrations(); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			printCommandError(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if configs.Configs.SeedOnStartup {
+		report, err := seedFixtures(false)
+		if err != nil {
+			printCommandError(err)
+			log.Fatalf("Failed to seed fixtures")
+		}
+		log.Printf(
+			"Fixtures seeded: %d created, %d updated, %d unchanged, %d skipped",
+			report.Created, report.Updated, report.Unchanged, report.Skipped,
+		)
+	}
 	app.App()
 }
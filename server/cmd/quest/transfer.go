@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"tech-quest/fixtures"
+	"tech-quest/internal/configs"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/database"
+	"tech-quest/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// runCommand выполняет подкоманду вместо запуска сервера:
+//
+//	quest export [-format json|yaml|csv] [-o файл]
+//	quest import [-format json|yaml|csv] [-dry-run] <файл|->
+//	quest seed [-dry-run]
+func runCommand(name string, args []string) error {
+	switch name {
+	case "export":
+		return runExport(args)
+	case "import":
+		return runImport(args)
+	case "seed":
+		return runSeed(args)
+	}
+	return fmt.Errorf("unknown command %q, expected export, import or seed", name)
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "", "json, yaml или csv; по умолчанию по расширению -o, иначе json")
+	output := flags.String("o", "", "файл для записи; по умолчанию stdout")
+	_ = flags.Parse(args)
+
+	if *format == "" {
+		*format = transferFormatByPath(*output)
+	}
+	service, db, err := newProcedureService()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	data, err := service.Export(*format)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "json, yaml или csv; по умолчанию по расширению файла, иначе json")
+	dryRun := flags.Bool("dry-run", false, "только проверить файл и показать, что изменится")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: quest import [-format json|yaml|csv] [-dry-run] <file|->")
+	}
+
+	path := flags.Arg(0)
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = transferFormatByPath(path)
+	}
+	service, db, err := newProcedureService()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	report, err := service.Import(*format, data, *dryRun)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "только показать, что изменится")
+	_ = flags.Parse(args)
+
+	report, err := seedFixtures(*dryRun)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// seedFixtures загружает фикстуры процедур, встроенные в бинарный файл
+func seedFixtures(dryRun bool) (*models.SeedReport, error) {
+	procedures, err := fs.Sub(fixtures.Procedures, "procedures")
+	if err != nil {
+		return nil, err
+	}
+	service, db, err := newProcedureService()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return service.Seed(procedures, dryRun)
+}
+
+// newProcedureService открывает собственный пул соединений; вызывающий закрывает его
+func newProcedureService() (*services.ProcedureService, *sqlx.DB, error) {
+	db, err := database.NewDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	return services.NewProcedureService(
+		repository.NewProcedureRepository(db, configs.Configs.ProcedureMaxDepth),
+		repository.NewProcedureTypeRepository(db),
+	), db, nil
+}
+
+func transferFormatByPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return services.TransferYAML
+	case ".csv":
+		return services.TransferCSV
+	}
+	return services.TransferJSON
+}
+
+// printCommandError выводит ошибку подкоманды; ошибки API печатаются целиком,
+// чтобы были видны детали по каждой строке импорта
+func printCommandError(err error) {
+	var appErr *errors.Error
+	if stderrors.As(err, &appErr) {
+		encoder := json.NewEncoder(os.Stderr)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(appErr)
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
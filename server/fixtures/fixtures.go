@@ -0,0 +1,11 @@
+// Package fixtures содержит начальное содержимое, встроенное в бинарный файл.
+// Применяется командой quest seed или при старте с SEED_ON_STARTUP=true.
+package fixtures
+
+import "embed"
+
+// Procedures — файлы процедур в формате экспорта (.yaml, .yml или .json)
+// в каталоге procedures; применяются в порядке имён файлов
+//
+//go:embed procedures
+var Procedures embed.FS
@@ -1,10 +1,16 @@
 package app
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"regexp"
 	"strings"
+	"syscall"
 	"tech-quest/internal/configs"
 	"tech-quest/internal/container"
 	"tech-quest/internal/routes"
+	"tech-quest/internal/services"
 	"tech-quest/pkg/errors"
 
 	"github.com/gofiber/fiber/v3"
@@ -12,12 +18,35 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/logger"
 	recover2 "github.com/gofiber/fiber/v3/middleware/recover"
 	"github.com/gofiber/fiber/v3/middleware/static"
+	"github.com/valyala/fasthttp"
 )
 
+// claimUploadPath — путь загрузки документов заявления POST /api/v1/claims/:id/documents
+var claimUploadPath = regexp.MustCompile(`(?i)^/api/v1/claims/[^/]+/documents/?$`)
+
+// claimUploadBodyLimit поднимает лимит тела только для загрузки документов заявления.
+// Лимит выбирается по заголовкам до чтения тела, поэтому остальные маршруты
+// по-прежнему не принимают тела больше BodyLimit.
+func claimUploadBodyLimit(limit int) func(*fasthttp.RequestHeader) fasthttp.RequestConfig {
+	return func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		path, _, _ := strings.Cut(string(header.RequestURI()), "?")
+		if header.IsPost() && claimUploadPath.MatchString(path) {
+			return fasthttp.RequestConfig{MaxRequestBodySize: limit}
+		}
+		return fasthttp.RequestConfig{}
+	}
+}
+
 func App() {
-	appConfig := fiber.Config{ErrorHandler: errors.HandlerErrorFormatter, StrictRouting: false}
-	app := fiber.New(appConfig)
 	cfg := configs.Configs
+	appConfig := fiber.Config{
+		ErrorHandler:  errors.HandlerErrorFormatter,
+		StrictRouting: false,
+		// тело multipart-запроса чуть больше самих файлов
+		BodyLimit: int(cfg.AttachmentMaxSize) + 1<<20,
+	}
+	app := fiber.New(appConfig)
+	app.Server().HeaderReceived = claimUploadBodyLimit(int(cfg.ClaimDocumentMaxSize*services.MaxClaimUploadFiles) + 1<<20)
 	app.Use(recover2.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: strings.Split(cfg.CORSAllowOrigins, ","),
@@ -26,6 +55,11 @@ func App() {
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"ETag",
+			"Last-Modified",
+			"Accept-Ranges",
+			"Content-Range",
+			"Content-Disposition",
 		},
 		AllowCredentials: true,
 		MaxAge:           cfg.CORSMaxAge,
@@ -37,7 +71,30 @@ func App() {
 	}))
 	api := app.Group("api/v1/")
 	c := container.NewContainer(api)
-	routes.RegisterRoutes(api, c.Handlers().ProcedureHandler)
+	handlers := c.Handlers()
+	routes.RegisterRoutes(
+		api,
+		handlers.ProcedureHandler,
+		handlers.RevisionHandler,
+		handlers.TranslationHandler,
+		handlers.ProcedureTypeHandler,
+		handlers.TagHandler,
+		handlers.AttachmentHandler,
+		handlers.ClaimHandler,
+		handlers.ClaimDocumentHandler,
+		handlers.SearchRequestHandler,
+		handlers.CacheHandler,
+		handlers.StreamHandler,
+		handlers.AdminAuth,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c.StartBackground(ctx)
+	go func() {
+		<-ctx.Done()
+		_ = app.Shutdown()
+	}()
 	if err := app.Listen(":8000"); err != nil {
 		panic(err)
 	}
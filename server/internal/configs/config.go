@@ -2,7 +2,9 @@ package configs
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -17,11 +19,74 @@ type config struct {
 	DBName           string `env:"POSTGRES_DB" env-default:"quest"`
 	DBSSLMode        string `env:"DB_SSLMODE" env-default:"disable"`
 	CORSAllowOrigins string `env:"CORS_ALLOW_ORIGINS" env-default:"http://localhost:5173,http://localhost:3000"`
-	CORSAllowMethods string `env:"CORS_ALLOW_METHODS" env-default:"GET,POST,PUT,DELETE,OPTIONS"`
-	CORSAllowHeaders string `env:"CORS_ALLOW_HEADERS" env-default:"Origin,Content-Type,Accept,Authorization"`
+	CORSAllowMethods string `env:"CORS_ALLOW_METHODS" env-default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSAllowHeaders string `env:"CORS_ALLOW_HEADERS" env-default:"Origin,Content-Type,Accept,Authorization,If-Match,If-None-Match,If-Modified-Since"`
 	CORSMaxAge       int    `env:"CORS_MAX_AGE" env-default:"3600"`
 	SwaggerUser      string `env:"SWAGGER_USER" env-default:"admin"`
 	SwaggerPassword  string `env:"SWAGGER_PASSWORD" env-default:"admin"`
+
+	// SeedOnStartup загружает встроенные фикстуры процедур при запуске сервера (то же, что quest seed)
+	SeedOnStartup bool `env:"SEED_ON_STARTUP" env-default:"false"`
+
+	// Basic-авторизация административных операций; пустой пароль закрывает их для всех
+	AdminUser     string `env:"ADMIN_USER" env-default:"admin"`
+	AdminPassword string `env:"ADMIN_PASSWORD"`
+
+	// RequireIfMatch включает строгий режим: PUT, PATCH и DELETE процедур без If-Match получают 428
+	RequireIfMatch bool `env:"REQUIRE_IF_MATCH" env-default:"false"`
+
+	// Cache-Control для публичного чтения процедур; пустое значение отключает заголовок
+	CacheControlProcedureList   string `env:"CACHE_CONTROL_PROCEDURE_LIST" env-default:"public, max-age=60, stale-while-revalidate=300"`
+	CacheControlProcedure       string `env:"CACHE_CONTROL_PROCEDURE" env-default:"public, max-age=60, stale-while-revalidate=300"`
+	CacheControlProcedureByType string `env:"CACHE_CONTROL_PROCEDURE_BY_TYPE" env-default:"public, max-age=300"`
+
+	// Кеш чтения процедур в памяти экземпляра
+	ProcedureCacheEnabled bool          `env:"PROCEDURE_CACHE_ENABLED" env-default:"true"`
+	ProcedureCacheTTL     time.Duration `env:"PROCEDURE_CACHE_TTL" env-default:"30s"`
+	ProcedureCacheSize    int           `env:"PROCEDURE_CACHE_SIZE" env-default:"1000"`
+
+	// Поток изменений процедур (SSE): сколько последних событий хранить для Last-Event-ID
+	// и как часто слать heartbeat, чтобы прокси не закрывали простаивающее соединение
+	ProcedureStreamBuffer    int           `env:"PROCEDURE_STREAM_BUFFER" env-default:"256"`
+	ProcedureStreamHeartbeat time.Duration `env:"PROCEDURE_STREAM_HEARTBEAT" env-default:"15s"`
+
+	// Максимальная глубина вложенности процедур; процедуры верхнего уровня — уровень 1
+	ProcedureMaxDepth int `env:"PROCEDURE_MAX_DEPTH" env-default:"5"`
+
+	ProcedureSchedulerInterval time.Duration `env:"PROCEDURE_SCHEDULER_INTERVAL" env-default:"1m"`
+	// Сколько процедура хранится в корзине до окончательного удаления; 0 отключает очистку
+	ProcedureTrashRetention time.Duration `env:"PROCEDURE_TRASH_RETENTION" env-default:"720h"`
+
+	// Вложения: ATTACHMENT_STORAGE=local хранит файлы в ATTACHMENT_LOCAL_DIR,
+	// s3 — в бакете S3-совместимого хранилища (AWS S3, MinIO)
+	AttachmentStorage      string `env:"ATTACHMENT_STORAGE" env-default:"local"`
+	AttachmentLocalDir     string `env:"ATTACHMENT_LOCAL_DIR" env-default:"data/attachments"`
+	AttachmentMaxSize      int64  `env:"ATTACHMENT_MAX_SIZE" env-default:"10485760"`
+	AttachmentAllowedTypes string `env:"ATTACHMENT_ALLOWED_TYPES" env-default:"application/pdf,image/png,image/jpeg,text/plain,application/msword,application/vnd.ms-excel,application/vnd.openxmlformats-officedocument.wordprocessingml.document,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"`
+	S3Endpoint             string `env:"S3_ENDPOINT"`
+	S3Region               string `env:"S3_REGION" env-default:"us-east-1"`
+	S3Bucket               string `env:"S3_BUCKET"`
+	S3AccessKey            string `env:"S3_ACCESS_KEY"`
+	S3SecretKey            string `env:"S3_SECRET_KEY"`
+
+	// Документы заявлений: размер файла, лимиты на требование и на заявление, типы файлов
+	// для требований без своего content_types и большая сторона превью фотографий
+	ClaimDocumentMaxSize       int64  `env:"CLAIM_DOCUMENT_MAX_SIZE" env-default:"10485760"`
+	ClaimDocumentMaxPerCode    int    `env:"CLAIM_DOCUMENT_MAX_PER_CODE" env-default:"10"`
+	ClaimDocumentMaxPerClaim   int    `env:"CLAIM_DOCUMENT_MAX_PER_CLAIM" env-default:"30"`
+	ClaimDocumentAllowedTypes  string `env:"CLAIM_DOCUMENT_ALLOWED_TYPES" env-default:"application/pdf,image/jpeg,image/png"`
+	ClaimDocumentThumbnailSize int    `env:"CLAIM_DOCUMENT_THUMBNAIL_SIZE" env-default:"320"`
+
+	// Розыск отправлений: срок ответа клиенту по виду розыска, период фоновой проверки
+	// просрочки и интервал повторной эскалации; нулевой интервал эскалирует запрос один раз
+	SearchRequestSLADomestic        time.Duration `env:"SEARCH_REQUEST_SLA_DOMESTIC" env-default:"720h"`
+	SearchRequestSLAInternational   time.Duration `env:"SEARCH_REQUEST_SLA_INTERNATIONAL" env-default:"1440h"`
+	SearchRequestCheckInterval      time.Duration `env:"SEARCH_REQUEST_CHECK_INTERVAL" env-default:"5m"`
+	SearchRequestEscalationInterval time.Duration `env:"SEARCH_REQUEST_ESCALATION_INTERVAL" env-default:"72h"`
+
+	DefaultLocale    string `env:"DEFAULT_LOCALE" env-default:"ru"`
+	SupportedLocales string `env:"SUPPORTED_LOCALES" env-default:"ru,en,kk,uz"`
+	LocaleFallback   string `env:"LOCALE_FALLBACK" env-default:"ru"`
 }
 
 func LoadConfig() {
@@ -31,4 +96,31 @@ func LoadConfig() {
 	if err := cleanenv.ReadEnv(&Configs); err != nil {
 		panic(err)
 	}
+	if err := Configs.validate(); err != nil {
+		panic(err)
+	}
+}
+
+// validate отклоняет значения, с которыми сервис упадёт или будет работать неверно
+func (c *config) validate() error {
+	positive := []struct {
+		name  string
+		value int64
+	}{
+		{"PROCEDURE_SCHEDULER_INTERVAL", int64(c.ProcedureSchedulerInterval)},
+		{"PROCEDURE_STREAM_HEARTBEAT", int64(c.ProcedureStreamHeartbeat)},
+		{"SEARCH_REQUEST_SLA_DOMESTIC", int64(c.SearchRequestSLADomestic)},
+		{"SEARCH_REQUEST_SLA_INTERNATIONAL", int64(c.SearchRequestSLAInternational)},
+		{"SEARCH_REQUEST_CHECK_INTERVAL", int64(c.SearchRequestCheckInterval)},
+		{"CLAIM_DOCUMENT_MAX_SIZE", c.ClaimDocumentMaxSize},
+		{"CLAIM_DOCUMENT_MAX_PER_CODE", int64(c.ClaimDocumentMaxPerCode)},
+		{"CLAIM_DOCUMENT_MAX_PER_CLAIM", int64(c.ClaimDocumentMaxPerClaim)},
+		{"CLAIM_DOCUMENT_THUMBNAIL_SIZE", int64(c.ClaimDocumentThumbnailSize)},
+	}
+	for _, p := range positive {
+		if p.value <= 0 {
+			return fmt.Errorf("config: %s must be positive", p.name)
+		}
+	}
+	return nil
 }
@@ -1,42 +1,213 @@
 package container
 
 import (
+	"context"
+	"fmt"
 	"github.com/gofiber/fiber/v3"
 	"github.com/jmoiron/sqlx"
 	"log"
+	"strings"
+	"tech-quest/internal/configs"
+	"tech-quest/internal/domain/models"
 	"tech-quest/internal/handlers"
 	"tech-quest/internal/repository"
 	"tech-quest/internal/services"
 	"tech-quest/pkg/database"
+	"tech-quest/pkg/i18n"
+	"tech-quest/pkg/storage"
+	"time"
 )
 
 type Services struct {
-	ProcedureService *services.ProcedureService
+	ProcedureService     *services.ProcedureService
+	RevisionService      *services.RevisionService
+	ProcedureScheduler   *services.ProcedureScheduler
+	TranslationService   *services.TranslationService
+	TypeService          *services.ProcedureTypeService
+	TagService           *services.TagService
+	AttachmentService    *services.AttachmentService
+	ClaimService         *services.ClaimService
+	ClaimDocumentService *services.ClaimDocumentService
+	SearchRequestService *services.SearchRequestService
+	SearchRequestMonitor *services.SearchRequestMonitor
+	ProcedureChanges     *services.ProcedureChanges
+	ProcedureStream      *services.ProcedureStream
 }
 
 func (c *Container) NewServices() *Services {
+	cfg := configs.Configs
+	negotiator := i18n.NewNegotiator(
+		cfg.DefaultLocale,
+		strings.Split(cfg.SupportedLocales, ","),
+		strings.Split(cfg.LocaleFallback, ","),
+	)
+	procedureChanges := services.NewProcedureChanges(database.NewListener(services.ProcedureChangeChannel))
+	if cache := c.repo.ProcedureCache; cache != nil {
+		procedureChanges.Subscribe(func(models.ProcedureChange) {
+			cache.Invalidate()
+		})
+	}
+	procedureStream := services.NewProcedureStream(c.repo.ProcedureRepository, cfg.ProcedureStreamBuffer)
+	procedureChanges.Subscribe(procedureStream.OnChange)
+	claimService := services.NewClaimService(c.repo.ClaimRepository, c.repo.ProcedureRepository)
 	return &Services{
-		ProcedureService: services.NewProcedureService(c.repo.ProcedureRepository),
+		ProcedureService: services.NewProcedureService(c.repo.ProcedureRepository, c.repo.TypeRepository),
+		RevisionService:  services.NewRevisionService(c.repo.RevisionRepository, c.repo.ProcedureRepository),
+		ProcedureScheduler: services.NewProcedureScheduler(
+			c.repo.ProcedureRepository,
+			cfg.ProcedureSchedulerInterval,
+			cfg.ProcedureTrashRetention,
+		),
+		TranslationService: services.NewTranslationService(c.repo.TranslationRepository, negotiator),
+		TypeService:        services.NewProcedureTypeService(c.repo.TypeRepository),
+		TagService:         services.NewTagService(c.repo.TagRepository),
+		AttachmentService: services.NewAttachmentService(
+			c.repo.AttachmentRepository,
+			c.repo.AttachmentStorage,
+			cfg.AttachmentMaxSize,
+			strings.Split(cfg.AttachmentAllowedTypes, ","),
+		),
+		ClaimService: claimService,
+		// Документы заявлений лежат в хранилище вложений под префиксом claims/ и
+		// отдаются только через администраторские маршруты заявлений
+		ClaimDocumentService: services.NewClaimDocumentService(
+			claimService,
+			c.repo.ClaimRepository,
+			c.repo.AttachmentStorage,
+			services.ClaimDocumentLimits{
+				MaxSize:       cfg.ClaimDocumentMaxSize,
+				MaxPerCode:    cfg.ClaimDocumentMaxPerCode,
+				MaxPerClaim:   cfg.ClaimDocumentMaxPerClaim,
+				AllowedTypes:  strings.Split(cfg.ClaimDocumentAllowedTypes, ","),
+				ThumbnailSize: cfg.ClaimDocumentThumbnailSize,
+			},
+		),
+		SearchRequestService: services.NewSearchRequestService(
+			c.repo.SearchRequestRepository,
+			map[models.SearchRequestKind]time.Duration{
+				models.SearchDomestic:      cfg.SearchRequestSLADomestic,
+				models.SearchInternational: cfg.SearchRequestSLAInternational,
+			},
+		),
+		SearchRequestMonitor: services.NewSearchRequestMonitor(
+			c.repo.SearchRequestRepository,
+			cfg.SearchRequestCheckInterval,
+			cfg.SearchRequestEscalationInterval,
+		),
+		ProcedureChanges: procedureChanges,
+		ProcedureStream:  procedureStream,
 	}
 }
 
 type Handlers struct {
-	ProcedureHandler *handlers.ProcedureHandler
+	ProcedureHandler     *handlers.ProcedureHandler
+	RevisionHandler      *handlers.RevisionHandler
+	TranslationHandler   *handlers.TranslationHandler
+	ProcedureTypeHandler *handlers.ProcedureTypeHandler
+	TagHandler           *handlers.TagHandler
+	AttachmentHandler    *handlers.AttachmentHandler
+	ClaimHandler         *handlers.ClaimHandler
+	ClaimDocumentHandler *handlers.ClaimDocumentHandler
+	SearchRequestHandler *handlers.SearchRequestHandler
+	CacheHandler         *handlers.CacheHandler
+	StreamHandler        *handlers.ProcedureStreamHandler
+	AdminAuth            fiber.Handler
 }
 
 func (c *Container) NewHandlers() *Handlers {
+	var procedureCache handlers.CacheStatsProvider
+	if c.repo.ProcedureCache != nil {
+		procedureCache = c.repo.ProcedureCache
+	}
 	return &Handlers{
-		ProcedureHandler: handlers.NewProcedureHandler(c.services.ProcedureService),
+		ProcedureHandler: handlers.NewProcedureHandler(
+			c.services.ProcedureService,
+			c.services.RevisionService,
+			c.services.TranslationService,
+			configs.Configs.RequireIfMatch,
+			handlers.CachePolicies{
+				List:   configs.Configs.CacheControlProcedureList,
+				Item:   configs.Configs.CacheControlProcedure,
+				ByType: configs.Configs.CacheControlProcedureByType,
+			},
+		),
+		RevisionHandler:      handlers.NewRevisionHandler(c.services.RevisionService),
+		TranslationHandler:   handlers.NewTranslationHandler(c.services.TranslationService),
+		ProcedureTypeHandler: handlers.NewProcedureTypeHandler(c.services.TypeService),
+		TagHandler:           handlers.NewTagHandler(c.services.TagService),
+		AttachmentHandler:    handlers.NewAttachmentHandler(c.services.AttachmentService),
+		ClaimHandler:         handlers.NewClaimHandler(c.services.ClaimService),
+		ClaimDocumentHandler: handlers.NewClaimDocumentHandler(c.services.ClaimDocumentService),
+		SearchRequestHandler: handlers.NewSearchRequestHandler(c.services.SearchRequestService),
+		CacheHandler:         handlers.NewCacheHandler(procedureCache),
+		StreamHandler: handlers.NewProcedureStreamHandler(
+			c.services.ProcedureStream,
+			configs.Configs.ProcedureStreamHeartbeat,
+		),
+		AdminAuth: handlers.NewAdminAuth(configs.Configs.AdminUser, configs.Configs.AdminPassword),
 	}
 }
 
 type Repository struct {
-	ProcedureRepository *repository.ProcedureRepository
+	ProcedureRepository     repository.ProcedureRepos
+	RevisionRepository      *repository.RevisionRepository
+	TranslationRepository   *repository.TranslationRepository
+	TypeRepository          *repository.ProcedureTypeRepository
+	TagRepository           *repository.TagRepository
+	AttachmentRepository    *repository.AttachmentRepository
+	ClaimRepository         *repository.ClaimRepository
+	SearchRequestRepository *repository.SearchRequestRepository
+	// AttachmentStorage — хранилище файлов вложений, выбранное ATTACHMENT_STORAGE
+	AttachmentStorage storage.Storage
+
+	// ProcedureCache — кеш, которым обёрнут ProcedureRepository; nil, если кеш выключен
+	ProcedureCache *repository.CachedProcedureRepository
 }
 
 func (c *Container) NewRepository() *Repository {
-	return &Repository{
-		ProcedureRepository: repository.NewProcedureRepository(c.db),
+	cfg := configs.Configs
+	repo := &Repository{
+		ProcedureRepository:     repository.NewProcedureRepository(c.db, cfg.ProcedureMaxDepth),
+		RevisionRepository:      repository.NewRevisionRepository(c.db),
+		TranslationRepository:   repository.NewTranslationRepository(c.db),
+		TypeRepository:          repository.NewProcedureTypeRepository(c.db),
+		TagRepository:           repository.NewTagRepository(c.db),
+		AttachmentRepository:    repository.NewAttachmentRepository(c.db),
+		ClaimRepository:         repository.NewClaimRepository(c.db),
+		SearchRequestRepository: repository.NewSearchRequestRepository(c.db),
+	}
+	attachmentStorage, err := newAttachmentStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
+	repo.AttachmentStorage = attachmentStorage
+	if cfg.ProcedureCacheEnabled {
+		repo.ProcedureCache = repository.NewCachedProcedureRepository(
+			repo.ProcedureRepository,
+			cfg.ProcedureCacheTTL,
+			cfg.ProcedureCacheSize,
+		)
+		repo.ProcedureRepository = repo.ProcedureCache
+	}
+	return repo
+}
+
+// newAttachmentStorage выбирает хранилище вложений по ATTACHMENT_STORAGE
+func newAttachmentStorage() (storage.Storage, error) {
+	cfg := configs.Configs
+	switch cfg.AttachmentStorage {
+	case "local":
+		return storage.NewLocal(cfg.AttachmentLocalDir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown ATTACHMENT_STORAGE %q, expected local or s3", cfg.AttachmentStorage)
 	}
 }
 
@@ -70,3 +241,11 @@ func (c *Container) Router() fiber.Router {
 func (c *Container) Handlers() *Handlers {
 	return c.handlers
 }
+
+// StartBackground запускает фоновые задачи, работающие до отмены ctx
+func (c *Container) StartBackground(ctx context.Context) {
+	c.services.ProcedureScheduler.Start(ctx)
+	c.services.SearchRequestMonitor.Start(ctx)
+	c.services.ProcedureStream.Start(ctx)
+	c.services.ProcedureChanges.Start(ctx)
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment — файл, который можно скачать из элемента содержимого процедуры
+// (бланк заявления, образец документа). Сам файл лежит в хранилище под StorageKey.
+type Attachment struct {
+	ID          int    `json:"id" db:"id"`
+	FileName    string `json:"file_name" db:"file_name"`
+	ContentType string `json:"content_type" db:"content_type"`
+	Size        int64  `json:"size" db:"size"`
+	// Checksum — SHA-256 содержимого в hex, используется как ETag
+	Checksum   string    `json:"checksum" db:"checksum"`
+	StorageKey string    `json:"-" db:"storage_key"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"time"
+)
+
+// ClaimKind — вид заявления: об утрате или о повреждении посылки
+type ClaimKind string
+
+const (
+	ClaimLoss   ClaimKind = "loss"
+	ClaimDamage ClaimKind = "damage"
+)
+
+func (k ClaimKind) IsValid() bool {
+	switch k {
+	case ClaimLoss, ClaimDamage:
+		return true
+	}
+	return false
+}
+
+// ClaimStatus — этап рассмотрения заявления
+type ClaimStatus string
+
+const (
+	ClaimSubmitted         ClaimStatus = "submitted"
+	ClaimUnderReview       ClaimStatus = "under_review"
+	ClaimAwaitingDocuments ClaimStatus = "awaiting_documents"
+	ClaimApproved          ClaimStatus = "approved"
+	ClaimRejected          ClaimStatus = "rejected"
+	ClaimCompensated       ClaimStatus = "compensated"
+	ClaimClosed            ClaimStatus = "closed"
+)
+
+func (s ClaimStatus) IsValid() bool {
+	switch s {
+	case ClaimSubmitted, ClaimUnderReview, ClaimAwaitingDocuments, ClaimApproved,
+		ClaimRejected, ClaimCompensated, ClaimClosed:
+		return true
+	}
+	return false
+}
+
+// AcceptsDocuments сообщает, можно ли в этом статусе приложить к заявлению документы
+func (s ClaimStatus) AcceptsDocuments() bool {
+	switch s {
+	case ClaimSubmitted, ClaimUnderReview, ClaimAwaitingDocuments:
+		return true
+	}
+	return false
+}
+
+// ClaimStatusChange — запись истории статусов заявления. У первой записи From пуст.
+type ClaimStatusChange struct {
+	ID        int          `json:"id" db:"id"`
+	From      *ClaimStatus `json:"from" db:"from_status"`
+	To        ClaimStatus  `json:"to" db:"to_status"`
+	Reason    string       `json:"reason" db:"reason"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
+
+// ClaimTransition — запрос на смену статуса заявления
+type ClaimTransition struct {
+	Status ClaimStatus `json:"status"`
+	Reason string      `json:"reason"`
+}
+
+// Claim — заявление клиента об утрате или повреждении посылки.
+// Для связи нужен хотя бы один из контактов: email или телефон.
+type Claim struct {
+	ID             int         `json:"id" db:"id"`
+	TrackingNumber string      `json:"tracking_number" db:"tracking_number"`
+	Kind           ClaimKind   `json:"kind" db:"kind"`
+	Status         ClaimStatus `json:"status" db:"status"`
+	ClaimantName   string      `json:"claimant_name" db:"claimant_name"`
+	ClaimantEmail  string      `json:"claimant_email,omitempty" db:"claimant_email"`
+	ClaimantPhone  string      `json:"claimant_phone,omitempty" db:"claimant_phone"`
+	// DeclaredValue — объявленная ценность отправления в минимальных единицах валюты (копейках)
+	DeclaredValue int64 `json:"declared_value" db:"declared_value"`
+	// Currency — код валюты ISO 4217, по умолчанию RUB
+	Currency    string    `json:"currency" db:"currency"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// History — история статусов от подачи заявления; в списках не загружается
+	History []ClaimStatusChange `json:"history,omitempty" db:"-"`
+}
+
+// ClaimDocument — документ, приложенный к заявлению. Code — код требования
+// из чек-листа, например recipient_passport.
+type ClaimDocument struct {
+	ID          int    `json:"id" db:"id"`
+	ClaimID     int    `json:"claim_id" db:"claim_id"`
+	Code        string `json:"code" db:"code"`
+	FileName    string `json:"file_name" db:"file_name"`
+	ContentType string `json:"content_type" db:"content_type"`
+	Size        int64  `json:"size" db:"size"`
+	// Checksum — SHA-256 сохранённого содержимого в hex; у фотографий — уже без метаданных
+	Checksum   string `json:"checksum" db:"checksum"`
+	StorageKey string `json:"-" db:"storage_key"`
+	// ThumbnailKey — ключ превью в хранилище; пуст у документов, не являющихся изображениями
+	ThumbnailKey string    `json:"-" db:"thumbnail_key"`
+	HasThumbnail bool      `json:"has_thumbnail" db:"has_thumbnail"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	// Duplicate — файл уже был загружен по этому требованию, возвращён прежний документ
+	Duplicate bool `json:"duplicate,omitempty" db:"-"`
+}
+
+// ClaimChecklistItem — требование к документу и приложенные по нему документы
+type ClaimChecklistItem struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Required bool   `json:"required"`
+	// ProcedureID — процедура, из которой взято требование
+	ProcedureID int `json:"procedure_id"`
+	// ContentTypes — типы файлов, которые принимаются по требованию; пусто — типы по умолчанию
+	ContentTypes []string `json:"content_types,omitempty"`
+	Satisfied    bool     `json:"satisfied"`
+	DocumentIDs  []int    `json:"document_ids"`
+}
+
+// ClaimChecklist — полнота документов заявления. Complete означает, что требования
+// найдены и приложены все обязательные документы; Missing — коды недостающих обязательных.
+type ClaimChecklist struct {
+	ClaimID  int                  `json:"claim_id"`
+	Kind     ClaimKind            `json:"kind"`
+	Complete bool                 `json:"complete"`
+	Missing  []string             `json:"missing"`
+	Items    []ClaimChecklistItem `json:"items"`
+}
+
+// ClaimFilter — параметры выборки страницы заявлений, новые первыми.
+// After — ID последнего заявления предыдущей страницы.
+type ClaimFilter struct {
+	Limit          int
+	After          int
+	Kind           ClaimKind
+	TrackingNumber string
+}
+
+// ClaimPage — страница заявлений с курсором на следующую страницу
+type ClaimPage struct {
+	Items      []Claim `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	Total      int     `json:"total"`
+}
@@ -1,16 +1,232 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"time"
 )
 
 type Procedure struct {
-	ID         int       `json:"id" db:"id"`
-	Title      string    `json:"title" db:"title"`
-	Type       string    `json:"type" db:"type"`
-	Content    string    `json:"content" db:"content"`
-	SortOrder  int       `json:"sort_order" db:"sort_order"`
-	IsExpanded bool      `json:"is_expanded" db:"is_expanded"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID   int    `json:"id" db:"id"`
+	Slug string `json:"slug" db:"slug"`
+	// ParentID — родительская процедура; nil у процедур верхнего уровня
+	ParentID    *int             `json:"parent_id" db:"parent_id"`
+	Title       string           `json:"title" db:"title"`
+	Type        string           `json:"type" db:"type"`
+	Content     ProcedureContent `json:"content" db:"content"`
+	SortOrder   int              `json:"sort_order" db:"sort_order"`
+	IsExpanded  bool             `json:"is_expanded" db:"is_expanded"`
+	Status      ProcedureStatus  `json:"status" db:"status"`
+	Version     int              `json:"version" db:"version"`
+	PublishAt   *time.Time       `json:"publish_at,omitempty" db:"publish_at"`
+	UnpublishAt *time.Time       `json:"unpublish_at,omitempty" db:"unpublish_at"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+	// DeletedAt заполнен у процедур в корзине
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Tags — slug'и назначенных тегов; nil означает, что теги не загружались
+	Tags []string `json:"tags" db:"-"`
+
+	// Locale — язык, на котором отданы title и content; заполняется при локализации
+	Locale string `json:"locale,omitempty" db:"-"`
+	// ParentSlug — slug родителя из файла импорта; по нему репозиторий находит ParentID
+	ParentSlug string `json:"-" db:"-"`
+}
+
+// ProcedureStatus — этап жизненного цикла процедуры.
+// Публично доступны только процедуры в статусе published.
+type ProcedureStatus string
+
+const (
+	ProcedureDraft     ProcedureStatus = "draft"
+	ProcedureInReview  ProcedureStatus = "in_review"
+	ProcedurePublished ProcedureStatus = "published"
+	ProcedureArchived  ProcedureStatus = "archived"
+)
+
+func (s ProcedureStatus) IsValid() bool {
+	switch s {
+	case ProcedureDraft, ProcedureInReview, ProcedurePublished, ProcedureArchived:
+		return true
+	}
+	return false
+}
+
+// ProcedureNode — процедура с подпроцедурами, упорядоченными по sort_order
+type ProcedureNode struct {
+	Procedure
+	Children []ProcedureNode `json:"children"`
+}
+
+// ProcedureParentChange — запрос на перенос процедуры вместе с поддеревом
+// к другому родителю; ParentID == nil переносит её на верхний уровень
+type ProcedureParentChange struct {
+	ParentID *int `json:"parent_id"`
+}
+
+// ChildrenOnDelete определяет, что происходит с подпроцедурами удаляемой процедуры
+type ChildrenOnDelete string
+
+const (
+	// ChildrenReject запрещает удалять процедуру, у которой есть подпроцедуры
+	ChildrenReject ChildrenOnDelete = "reject"
+	// ChildrenCascade перемещает в корзину всё поддерево
+	ChildrenCascade ChildrenOnDelete = "cascade"
+	// ChildrenReparent переносит подпроцедуры к родителю удаляемой процедуры
+	ChildrenReparent ChildrenOnDelete = "reparent"
+)
+
+func (m ChildrenOnDelete) IsValid() bool {
+	switch m {
+	case ChildrenReject, ChildrenCascade, ChildrenReparent:
+		return true
+	}
+	return false
+}
+
+// ProcedureStatusChange — запрос на смену статуса процедуры
+type ProcedureStatusChange struct {
+	Status ProcedureStatus `json:"status"`
+}
+
+// ContentItemType определяет вид элемента содержимого процедуры
+type ContentItemType string
+
+const (
+	ContentItemText     ContentItemType = "text"
+	ContentItemLink     ContentItemType = "link"
+	ContentItemDocument ContentItemType = "document"
+	ContentItemWarning  ContentItemType = "warning"
+	ContentItemStep     ContentItemType = "step"
+)
+
+// ContentItemTypes перечисляет все допустимые виды элементов содержимого
+var ContentItemTypes = []ContentItemType{
+	ContentItemText,
+	ContentItemLink,
+	ContentItemDocument,
+	ContentItemWarning,
+	ContentItemStep,
+}
+
+func (t ContentItemType) IsValid() bool {
+	for _, known := range ContentItemTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentItem — один элемент содержимого процедуры
+type ContentItem struct {
+	Type  ContentItemType `json:"type"`
+	Value string          `json:"value"`
+	URL   string          `json:"url,omitempty"`
+	// AttachmentID ссылается на вложение, которое скачивается из элемента типа document
+	AttachmentID int `json:"attachment_id,omitempty"`
+	// Requirement делает элемент типа document пунктом чек-листа документов заявления
+	Requirement *DocumentRequirement `json:"requirement,omitempty"`
+}
+
+// DocumentRequirement — машиночитаемое требование к документу заявления.
+// Code совпадает с кодом загруженного документа; пустой ClaimKinds означает,
+// что документ нужен для заявлений любого вида. ContentTypes ограничивает типы
+// загружаемых файлов; пустой список — типы по умолчанию из CLAIM_DOCUMENT_ALLOWED_TYPES.
+type DocumentRequirement struct {
+	Code         string      `json:"code"`
+	Required     bool        `json:"required"`
+	ClaimKinds   []ClaimKind `json:"claim_kinds,omitempty"`
+	ContentTypes []string    `json:"content_types,omitempty"`
+}
+
+// AppliesTo сообщает, нужен ли документ для заявления вида kind
+func (r DocumentRequirement) AppliesTo(kind ClaimKind) bool {
+	return len(r.ClaimKinds) == 0 || slices.Contains(r.ClaimKinds, kind)
+}
+
+// ProcedureContent хранится в колонке JSONB как массив элементов
+type ProcedureContent []ContentItem
+
+func (c ProcedureContent) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *ProcedureContent) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = ProcedureContent{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported procedure content type %T", src)
+	}
+	items := ProcedureContent{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("failed to decode procedure content: %w", err)
+	}
+	*c = items
+	return nil
+}
+
+// ProcedureOrder — позиция процедуры в общем порядке сортировки
+type ProcedureOrder struct {
+	ID        int `json:"id" db:"id"`
+	SortOrder int `json:"sort_order" db:"sort_order"`
+}
+
+// ReorderRequest задаёт новый порядок процедур: либо полный список IDs,
+// либо перемещение одной процедуры относительно другой. Type ограничивает
+// область сортировки процедурами одного типа.
+type ReorderRequest struct {
+	IDs  []int          `json:"ids,omitempty"`
+	Type string         `json:"type,omitempty"`
+	Move *MoveOperation `json:"move,omitempty"`
+}
+
+// MoveOperation перемещает процедуру ID перед Before или после After
+type MoveOperation struct {
+	ID     int `json:"id"`
+	Before int `json:"before,omitempty"`
+	After  int `json:"after,omitempty"`
+}
+
+// SearchResult — процедура, найденная полнотекстовым поиском.
+// Snippet содержит фрагмент текста с совпадениями, выделенными тегом <mark>.
+type SearchResult struct {
+	Procedure Procedure `json:"procedure"`
+	Rank      float64   `json:"rank"`
+	Snippet   string    `json:"snippet"`
+}
+
+// ProcedureSortFields — поля, по которым можно сортировать список процедур.
+// Префикс "-" в параметре sort означает сортировку по убыванию.
+var ProcedureSortFields = []string{"sort_order", "title", "updated_at"}
+
+// ProcedureFilter — параметры выборки страницы процедур
+type ProcedureFilter struct {
+	Limit        int
+	After        string
+	Sort         string
+	Types        []string
+	UpdatedSince *time.Time
+	IsExpanded   *bool
+	Tags         TagFilter
+}
+
+// ProcedurePage — страница списка процедур с курсором на следующую страницу
+type ProcedurePage struct {
+	Items      []Procedure `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Total      int         `json:"total"`
+	Facets     []TagFacet  `json:"facets"`
 }
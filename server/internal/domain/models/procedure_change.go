@@ -0,0 +1,44 @@
+package models
+
+// ProcedureChangeOp — вид изменения, о котором сообщает Postgres
+type ProcedureChangeOp string
+
+const (
+	ProcedureChangeInsert ProcedureChangeOp = "insert"
+	ProcedureChangeUpdate ProcedureChangeOp = "update"
+	ProcedureChangeDelete ProcedureChangeOp = "delete"
+	// ProcedureChangeReorder — у процедуры изменилась только позиция
+	ProcedureChangeReorder ProcedureChangeOp = "reorder"
+	// ProcedureChangeResync — соединение с базой восстановлено после обрыва,
+	// часть уведомлений могла потеряться, и производные данные нужно перестроить
+	ProcedureChangeResync ProcedureChangeOp = "resync"
+)
+
+// ProcedureChange — уведомление из канала procedure_changes.
+// Table — procedures, procedure_translations или procedure_tags, ID — ID процедуры.
+type ProcedureChange struct {
+	Table string            `json:"table"`
+	Op    ProcedureChangeOp `json:"op"`
+	ID    int               `json:"id"`
+}
+
+// ProcedureEventType — тип события потока /procedures/stream
+type ProcedureEventType string
+
+const (
+	ProcedureEventCreated   ProcedureEventType = "created"
+	ProcedureEventUpdated   ProcedureEventType = "updated"
+	ProcedureEventDeleted   ProcedureEventType = "deleted"
+	ProcedureEventReordered ProcedureEventType = "reordered"
+	// ProcedureEventResync просит клиента перечитать список целиком:
+	// пропущенные события восстановить не удалось
+	ProcedureEventResync ProcedureEventType = "resync"
+)
+
+// ProcedureEvent — событие потока процедур. Data — уже сериализованный payload:
+// процедура для created/updated/reordered, {"id": ...} для deleted.
+type ProcedureEvent struct {
+	ID   string
+	Type ProcedureEventType
+	Data []byte
+}
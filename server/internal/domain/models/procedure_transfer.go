@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// ProcedureRecord — процедура в файле импорта и экспорта. Процедуры сопоставляются
+// по slug, поэтому ID, версия и служебные даты в файл не попадают, а родитель
+// записывается slug'ом в Parent.
+type ProcedureRecord struct {
+	Slug        string           `json:"slug"`
+	Parent      string           `json:"parent,omitempty"`
+	Title       string           `json:"title"`
+	Type        string           `json:"type"`
+	Status      ProcedureStatus  `json:"status"`
+	SortOrder   int              `json:"sort_order"`
+	IsExpanded  bool             `json:"is_expanded"`
+	PublishAt   *time.Time       `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time       `json:"unpublish_at,omitempty"`
+	Content     ProcedureContent `json:"content"`
+}
+
+func NewProcedureRecord(procedure Procedure) ProcedureRecord {
+	return ProcedureRecord{
+		Slug:        procedure.Slug,
+		Title:       procedure.Title,
+		Type:        procedure.Type,
+		Status:      procedure.Status,
+		SortOrder:   procedure.SortOrder,
+		IsExpanded:  procedure.IsExpanded,
+		PublishAt:   procedure.PublishAt,
+		UnpublishAt: procedure.UnpublishAt,
+		Content:     procedure.Content,
+	}
+}
+
+func (r ProcedureRecord) Procedure() Procedure {
+	return Procedure{
+		Slug:        r.Slug,
+		ParentSlug:  r.Parent,
+		Title:       r.Title,
+		Type:        r.Type,
+		Status:      r.Status,
+		SortOrder:   r.SortOrder,
+		IsExpanded:  r.IsExpanded,
+		PublishAt:   r.PublishAt,
+		UnpublishAt: r.UnpublishAt,
+		Content:     r.Content,
+	}
+}
+
+// ImportAction — что импорт сделал с процедурой
+type ImportAction string
+
+const (
+	ImportCreated   ImportAction = "created"
+	ImportUpdated   ImportAction = "updated"
+	ImportUnchanged ImportAction = "unchanged"
+	// ImportSkipped — фикстура не применена: процедуру меняли или удаляли после загрузки
+	ImportSkipped ImportAction = "skipped"
+)
+
+// ImportRow — результат импорта одной строки файла; Index считается с нуля
+type ImportRow struct {
+	Index  int          `json:"index"`
+	Slug   string       `json:"slug"`
+	ID     int          `json:"id"`
+	Action ImportAction `json:"action"`
+}
+
+// ImportReport — итог импорта. При DryRun изменения не сохранены.
+type ImportReport struct {
+	DryRun    bool        `json:"dry_run"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Rows      []ImportRow `json:"rows"`
+}
+
+// ProcedureFixture — процедура из файла фикстур. Checksum — хеш содержимого
+// фикстуры, по нему seed понимает, изменился ли файл с прошлой загрузки.
+type ProcedureFixture struct {
+	File      string
+	Procedure Procedure
+	Checksum  string
+}
+
+// SeedRow — результат загрузки одной фикстуры
+type SeedRow struct {
+	File   string       `json:"file"`
+	Slug   string       `json:"slug"`
+	ID     int          `json:"id,omitempty"`
+	Action ImportAction `json:"action"`
+}
+
+// SeedReport — итог загрузки фикстур. При DryRun изменения не сохранены.
+type SeedReport struct {
+	DryRun    bool      `json:"dry_run"`
+	Created   int       `json:"created"`
+	Updated   int       `json:"updated"`
+	Unchanged int       `json:"unchanged"`
+	Skipped   int       `json:"skipped"`
+	Rows      []SeedRow `json:"rows"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcedureType — справочник типов процедур, на который ссылается Procedure.Type
+type ProcedureType struct {
+	Slug        string    `json:"slug" db:"slug"`
+	DisplayName string    `json:"display_name" db:"display_name"`
+	Description string    `json:"description" db:"description"`
+	Icon        string    `json:"icon" db:"icon"`
+	SortOrder   int       `json:"sort_order" db:"sort_order"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
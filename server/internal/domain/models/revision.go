@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RevisionAction — операция, породившая ревизию процедуры
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+)
+
+type ProcedureRevision struct {
+	ID          int               `json:"id" db:"id"`
+	ProcedureID int               `json:"procedure_id" db:"procedure_id"`
+	Revision    int               `json:"revision" db:"revision"`
+	Action      RevisionAction    `json:"action" db:"action"`
+	Snapshot    ProcedureSnapshot `json:"snapshot" db:"snapshot"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+}
+
+// ProcedureSnapshot — состояние процедуры на момент ревизии, хранится в JSONB
+type ProcedureSnapshot struct {
+	Procedure
+}
+
+func (s ProcedureSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s.Procedure)
+}
+
+func (s *ProcedureSnapshot) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported procedure snapshot type %T", src)
+	}
+	if err := json.Unmarshal(data, &s.Procedure); err != nil {
+		return fmt.Errorf("failed to decode procedure snapshot: %w", err)
+	}
+	return nil
+}
+
+// FieldChange описывает изменение одного поля между ревизиями
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RevisionDiff struct {
+	ProcedureID int           `json:"procedure_id"`
+	From        int           `json:"from"`
+	To          int           `json:"to"`
+	Changes     []FieldChange `json:"changes"`
+}
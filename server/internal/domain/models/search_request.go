@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchRequestKind — вид розыска: по внутреннему или международному отправлению.
+// От вида зависит срок ответа (SLA).
+type SearchRequestKind string
+
+const (
+	SearchDomestic      SearchRequestKind = "domestic"
+	SearchInternational SearchRequestKind = "international"
+)
+
+func (k SearchRequestKind) IsValid() bool {
+	switch k {
+	case SearchDomestic, SearchInternational:
+		return true
+	}
+	return false
+}
+
+// SearchRequestStatus — состояние розыска: открыт или завершён с результатом
+type SearchRequestStatus string
+
+const (
+	SearchOpen     SearchRequestStatus = "open"
+	SearchFound    SearchRequestStatus = "found"
+	SearchNotFound SearchRequestStatus = "not_found"
+)
+
+func (s SearchRequestStatus) IsValid() bool {
+	switch s {
+	case SearchOpen, SearchFound, SearchNotFound:
+		return true
+	}
+	return false
+}
+
+// SearchRequest — запрос на розыск отправления. DueAt — срок ответа клиенту,
+// рассчитанный от даты подачи по SLA вида розыска.
+type SearchRequest struct {
+	ID             int                 `json:"id" db:"id"`
+	TrackingNumber string              `json:"tracking_number" db:"tracking_number"`
+	Kind           SearchRequestKind   `json:"kind" db:"kind"`
+	Status         SearchRequestStatus `json:"status" db:"status"`
+	ContactEmail   string              `json:"contact_email,omitempty" db:"contact_email"`
+	ContactPhone   string              `json:"contact_phone,omitempty" db:"contact_phone"`
+	// Resolution — итог розыска, который сообщается клиенту
+	Resolution string    `json:"resolution,omitempty" db:"resolution"`
+	DueAt      time.Time `json:"due_at" db:"due_at"`
+	// Overdue — запрос открыт, а срок ответа уже прошёл
+	Overdue bool `json:"overdue" db:"overdue"`
+	// OverdueAt — когда фоновая проверка впервые отметила просрочку
+	OverdueAt *time.Time `json:"overdue_at,omitempty" db:"overdue_at"`
+	// EscalationLevel растёт при каждой эскалации просроченного запроса
+	EscalationLevel int        `json:"escalation_level" db:"escalation_level"`
+	EscalatedAt     *time.Time `json:"escalated_at,omitempty" db:"escalated_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SearchRequestResolution — завершение розыска с результатом found или not_found
+type SearchRequestResolution struct {
+	Status     SearchRequestStatus `json:"status"`
+	Resolution string              `json:"resolution"`
+}
+
+// SearchRequestFilter — параметры выборки страницы запросов на розыск, новые первыми.
+// Overdue оставляет только открытые запросы с прошедшим сроком ответа.
+type SearchRequestFilter struct {
+	Limit          int
+	After          int
+	Status         SearchRequestStatus
+	TrackingNumber string
+	Overdue        bool
+}
+
+// SearchRequestPage — страница запросов на розыск с курсором на следующую страницу
+type SearchRequestPage struct {
+	Items      []SearchRequest `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Total      int             `json:"total"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag — метка, которую можно назначить процедурам в дополнение к типу
+type Tag struct {
+	Slug      string    `json:"slug" db:"slug"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Режимы фильтра по тегам: all — процедура должна иметь все теги, any — хотя бы один
+const (
+	TagMatchAll = "all"
+	TagMatchAny = "any"
+)
+
+// TagFilter отбирает процедуры по тегам. Пустой Slugs не ограничивает выборку.
+type TagFilter struct {
+	Slugs []string
+	Mode  string
+}
+
+// TagFacet — число процедур с тегом среди отобранных фильтром
+type TagFacet struct {
+	Tag   string `json:"tag" db:"tag"`
+	Count int    `json:"count" db:"count"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcedureTranslation — перевод заголовка и содержимого процедуры на локаль
+type ProcedureTranslation struct {
+	ProcedureID int              `json:"procedure_id" db:"procedure_id"`
+	Locale      string           `json:"locale" db:"locale"`
+	Title       string           `json:"title" db:"title"`
+	Content     ProcedureContent `json:"content" db:"content"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// ProcedureRef — краткая ссылка на процедуру для отчётов
+type ProcedureRef struct {
+	ID    int    `json:"id" db:"id"`
+	Title string `json:"title" db:"title"`
+	Type  string `json:"type" db:"type"`
+}
+
+// MissingTranslations — процедуры без перевода на локаль
+type MissingTranslations struct {
+	Locale     string         `json:"locale"`
+	Procedures []ProcedureRef `json:"procedures"`
+}
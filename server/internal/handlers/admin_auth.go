@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/basicauth"
+)
+
+// NewAdminAuth защищает административные маршруты Basic-авторизацией.
+// Пока пароль не задан, доступ закрыт для всех.
+func NewAdminAuth(user, password string) fiber.Handler {
+	return basicauth.New(basicauth.Config{
+		Realm: "Admin",
+		Authorizer: func(u, p string, _ fiber.Ctx) bool {
+			if password == "" {
+				return false
+			}
+			userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+			passwordOK := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+			return userOK && passwordOK
+		},
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type AttachmentHandler struct {
+	service *services.AttachmentService
+}
+
+func NewAttachmentHandler(service *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+// GetAll возвращает список вложений
+// @Summary Получить вложения
+// @Description Возвращает все загруженные вложения, недавно загруженные первыми
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Attachment
+// @Router /attachments [get]
+func (h *AttachmentHandler) GetAll(c fiber.Ctx) error {
+	attachments, err := h.service.GetAll()
+	if err != nil {
+		return err
+	}
+	return c.JSON(attachments)
+}
+
+// GetByID возвращает описание вложения
+// @Summary Получить вложение
+// @Description Возвращает имя, тип, размер и контрольную сумму вложения
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID вложения"
+// @Success 200 {object} models.Attachment
+// @Failure 404 {object} errors.Error
+// @Router /attachments/{id} [get]
+func (h *AttachmentHandler) GetByID(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	attachment, err := h.service.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(attachment)
+}
+
+// Upload загружает вложение
+// @Summary Загрузить вложение
+// @Description Принимает файл в поле file формы multipart/form-data. Тип определяется по содержимому
+// @Description и должен входить в ATTACHMENT_ALLOWED_TYPES, размер ограничен ATTACHMENT_MAX_SIZE.
+// @Description Загруженное вложение указывается в attachment_id элемента содержимого типа document
+// @Tags attachments
+// @Accept mpfd
+// @Produce json
+// @Security BasicAuth
+// @Param file formData file true "Файл"
+// @Success 201 {object} models.Attachment
+// @Failure 401 "Unauthorized"
+// @Failure 400 {object} errors.Error
+// @Failure 413 {object} errors.Error
+// @Failure 415 {object} errors.Error
+// @Router /attachments [post]
+func (h *AttachmentHandler) Upload(c fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "file form field is required")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "failed to read file: "+err.Error())
+	}
+	defer file.Close()
+	attachment, err := h.service.Upload(header.Filename, file)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(attachment)
+}
+
+// Download отдаёт содержимое вложения
+// @Summary Скачать вложение
+// @Description Отдаёт файл с Content-Disposition: attachment. Поддерживает запрос одного диапазона
+// @Description через заголовок Range (206 Partial Content) и условный запрос по ETag
+// @Tags attachments
+// @Produce octet-stream
+// @Param id path int true "ID вложения"
+// @Param Range header string false "Диапазон байтов, например bytes=0-1023"
+// @Param If-None-Match header string false "ETag ранее полученного файла"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Success 304 "Not Modified"
+// @Failure 404 {object} errors.Error
+// @Failure 416 "Range Not Satisfiable"
+// @Router /attachments/{id}/download [get]
+func (h *AttachmentHandler) Download(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	attachment, err := h.service.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	etag := `"` + attachment.Checksum + `"`
+	c.Attachment(attachment.FileName)
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, attachment.CreatedAt.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if notModified(c, etag, attachment.CreatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	offset, length := int64(0), attachment.Size
+	status := fiber.StatusOK
+	// If-Range с другим ETag означает, что у клиента старая версия: отдаём файл целиком
+	ifRange := c.Get(fiber.HeaderIfRange)
+	if c.Get(fiber.HeaderRange) != "" && (ifRange == "" || ifRange == etag) {
+		ranges, err := c.Range(attachment.Size)
+		switch {
+		case stderrors.Is(err, fiber.ErrRequestedRangeNotSatisfiable):
+			return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+		case err == nil && len(ranges.Ranges) == 1:
+			// Несколько диапазонов (multipart/byteranges) не поддерживаются: отдаётся весь файл
+			offset = ranges.Ranges[0].Start
+			length = ranges.Ranges[0].End - offset + 1
+			status = fiber.StatusPartialContent
+			c.Set(fiber.HeaderContentRange, "bytes "+strconv.FormatInt(offset, 10)+"-"+
+				strconv.FormatInt(ranges.Ranges[0].End, 10)+"/"+strconv.FormatInt(attachment.Size, 10))
+		}
+	}
+	body, err := h.service.Open(attachment, offset, length)
+	if err != nil {
+		return err
+	}
+	c.Status(status)
+	return c.SendStream(body, int(length))
+}
+
+// Delete удаляет вложение
+// @Summary Удалить вложение
+// @Description Удаляет вложение и файл, если на него не ссылается содержимое процедур и переводов
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID вложения"
+// @Success 204 "No Content"
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /attachments/{id} [delete]
+func (h *AttachmentHandler) Delete(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	if err := h.service.Delete(id); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
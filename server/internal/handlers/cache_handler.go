@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/domain/models"
+)
+
+// CacheStatsProvider отдаёт счётчики кеша чтения процедур
+type CacheStatsProvider interface {
+	Stats() models.CacheStats
+}
+
+type CacheHandler struct {
+	procedures CacheStatsProvider
+}
+
+// NewCacheHandler создаёт CacheHandler. procedures равен nil, если кеш выключен.
+func NewCacheHandler(procedures CacheStatsProvider) *CacheHandler {
+	return &CacheHandler{procedures: procedures}
+}
+
+// Stats возвращает статистику кеша процедур
+// @Summary Статистика кеша процедур
+// @Description Возвращает число попаданий, промахов, вытеснений и сбросов кеша чтения процедур в этом экземпляре.
+// @Description Только для администраторов
+// @Tags cache
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {object} models.CacheStats
+// @Failure 401 "Unauthorized"
+// @Router /cache/stats [get]
+func (h *CacheHandler) Stats(c fiber.Ctx) error {
+	if h.procedures == nil {
+		return c.JSON(models.CacheStats{})
+	}
+	return c.JSON(h.procedures.Stats())
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"mime/multipart"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type ClaimDocumentHandler struct {
+	service *services.ClaimDocumentService
+}
+
+func NewClaimDocumentHandler(service *services.ClaimDocumentService) *ClaimDocumentHandler {
+	return &ClaimDocumentHandler{service: service}
+}
+
+// GetAll возвращает документы заявления
+// @Summary Получить документы заявления
+// @Description Возвращает документы, приложенные к заявлению, в порядке загрузки. Только для администраторов
+// @Tags claims
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID заявления"
+// @Success 200 {array} models.ClaimDocument
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /claims/{id}/documents [get]
+func (h *ClaimDocumentHandler) GetAll(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	documents, err := h.service.List(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(documents)
+}
+
+// Upload прикладывает документы к заявлению
+// @Summary Загрузить документы заявления
+// @Description Принимает до 5 файлов в поле file формы multipart/form-data по требованию code из чек-листа заявления.
+// @Description tracking_number должен совпадать с трек-номером заявления. Тип определяется по содержимому и должен входить
+// @Description в content_types требования, размер ограничен CLAIM_DOCUMENT_MAX_SIZE. Из JPEG и PNG удаляются метаданные
+// @Description (EXIF, XMP, IPTC), для них строится превью. Уже загруженный по требованию файл возвращается с duplicate=true.
+// @Description Ответ — документы в порядке файлов формы; 201, если сохранён хотя бы один новый файл
+// @Tags claims
+// @Accept mpfd
+// @Produce json
+// @Param id path int true "ID заявления"
+// @Param tracking_number formData string true "Трек-номер отправления"
+// @Param code formData string true "Код требования, например damage_photos"
+// @Param file formData file true "Файл"
+// @Success 200 {array} models.ClaimDocument
+// @Success 201 {array} models.ClaimDocument
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 413 {object} errors.Error
+// @Failure 415 {object} errors.Error
+// @Router /claims/{id}/documents [post]
+func (h *ClaimDocumentHandler) Upload(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "multipart form is required: "+err.Error())
+	}
+	headers := form.File["file"]
+	if len(headers) == 0 {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "file form field is required")
+	}
+	uploads := make([]services.ClaimUpload, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "failed to read file: "+err.Error())
+		}
+		defer func(file multipart.File) {
+			_ = file.Close()
+		}(file)
+		uploads = append(uploads, services.ClaimUpload{FileName: header.Filename, Body: file})
+	}
+
+	documents, err := h.service.Upload(id, c.FormValue("tracking_number"), c.FormValue("code"), uploads)
+	if err != nil {
+		return err
+	}
+	status := fiber.StatusOK
+	for _, document := range documents {
+		if !document.Duplicate {
+			status = fiber.StatusCreated
+			break
+		}
+	}
+	return c.Status(status).JSON(documents)
+}
+
+// Download отдаёт файл документа заявления
+// @Summary Скачать документ заявления
+// @Description Отдаёт файл с Content-Disposition: attachment. Только для администраторов
+// @Tags claims
+// @Produce octet-stream
+// @Security BasicAuth
+// @Param id path int true "ID заявления"
+// @Param document path int true "ID документа"
+// @Success 200 {file} file
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /claims/{id}/documents/{document}/download [get]
+func (h *ClaimDocumentHandler) Download(c fiber.Ctx) error {
+	return h.send(c, false)
+}
+
+// Thumbnail отдаёт превью изображения
+// @Summary Получить превью документа заявления
+// @Description Отдаёт уменьшенную копию фотографии в JPEG. У документов, не являющихся изображениями, превью нет.
+// @Description Только для администраторов
+// @Tags claims
+// @Produce jpeg
+// @Security BasicAuth
+// @Param id path int true "ID заявления"
+// @Param document path int true "ID документа"
+// @Success 200 {file} file
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /claims/{id}/documents/{document}/thumbnail [get]
+func (h *ClaimDocumentHandler) Thumbnail(c fiber.Ctx) error {
+	return h.send(c, true)
+}
+
+func (h *ClaimDocumentHandler) send(c fiber.Ctx, thumbnail bool) error {
+	claimID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	documentID, err := strconv.Atoi(c.Params("document"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid document parameter")
+	}
+	document, body, err := h.service.Open(claimID, documentID, thumbnail)
+	if err != nil {
+		return err
+	}
+	// Документы содержат персональные данные: общие кеши не должны их сохранять
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	if thumbnail {
+		c.Set(fiber.HeaderContentType, "image/jpeg")
+		return c.SendStream(body)
+	}
+	c.Attachment(document.FileName)
+	c.Set(fiber.HeaderContentType, document.ContentType)
+	c.Set(fiber.HeaderETag, `"`+document.Checksum+`"`)
+	return c.SendStream(body, int(document.Size))
+}
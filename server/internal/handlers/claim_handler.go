@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type ClaimHandler struct {
+	service *services.ClaimService
+}
+
+func NewClaimHandler(service *services.ClaimService) *ClaimHandler {
+	return &ClaimHandler{service: service}
+}
+
+// GetAll возвращает страницу заявлений
+// @Summary Получить список заявлений
+// @Description Возвращает заявления об утрате или повреждении, новые первыми. Пагинация по курсору: next_cursor передаётся в after.
+// @Description Только для администраторов
+// @Tags claims
+// @Produce json
+// @Security BasicAuth
+// @Param limit query int false "Размер страницы (по умолчанию 50, не больше 100)"
+// @Param after query string false "Курсор следующей страницы"
+// @Param kind query string false "Вид заявления: loss или damage"
+// @Param tracking_number query string false "Трек-номер отправления"
+// @Success 200 {object} models.ClaimPage
+// @Failure 400 {object} errors.Error
+// @Failure 401 "Unauthorized"
+// @Router /claims [get]
+func (h *ClaimHandler) GetAll(c fiber.Ctx) error {
+	filter := models.ClaimFilter{
+		Kind:           models.ClaimKind(c.Query("kind")),
+		TrackingNumber: c.Query("tracking_number"),
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid limit parameter")
+		}
+		filter.Limit = limit
+	}
+	if raw := c.Query("after"); raw != "" {
+		after, err := strconv.Atoi(raw)
+		if err != nil || after <= 0 {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid after parameter")
+		}
+		filter.After = after
+	}
+	page, err := h.service.List(filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(page)
+}
+
+// GetByID возвращает заявление
+// @Summary Получить заявление
+// @Description Возвращает заявление по ID. Только для администраторов
+// @Tags claims
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID заявления"
+// @Success 200 {object} models.Claim
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /claims/{id} [get]
+func (h *ClaimHandler) GetByID(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	claim, err := h.service.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(claim)
+}
+
+// Checklist возвращает чек-лист документов заявления
+// @Summary Проверить полноту документов заявления
+// @Description Сверяет приложенные документы с требованиями процедур loss_or_damage_docs и damage_additional_docs
+// @Description для вида заявления. complete=false означает, что на рассмотрение заявление не перейдёт:
+// @Description в missing перечислены коды недостающих обязательных документов. Только для администраторов
+// @Tags claims
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID заявления"
+// @Success 200 {object} models.ClaimChecklist
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /claims/{id}/checklist [get]
+func (h *ClaimHandler) Checklist(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	checklist, err := h.service.Checklist(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(checklist)
+}
+
+// Transition меняет статус заявления
+// @Summary Сменить статус заявления
+// @Description Переводит заявление в новый статус и дописывает переход в историю. Разрешены переходы
+// @Description submitted→under_review→awaiting_documents→under_review, under_review/awaiting_documents→approved или rejected,
+// @Description submitted→rejected, approved→compensated, compensated/rejected→closed. Для awaiting_documents и rejected нужна причина.
+// @Description Перевод в under_review возможен, только когда приложены все обязательные документы чек-листа.
+// @Description Только для администраторов
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID заявления"
+// @Param transition body models.ClaimTransition true "Новый статус и причина"
+// @Success 200 {object} models.Claim
+// @Failure 400 {object} errors.Error
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /claims/{id}/transitions [post]
+func (h *ClaimHandler) Transition(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	var transition models.ClaimTransition
+	if err := c.Bind().Body(&transition); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	claim, err := h.service.Transition(id, transition)
+	if err != nil {
+		return err
+	}
+	return c.JSON(claim)
+}
+
+// Create регистрирует заявление
+// @Summary Подать заявление об утрате или повреждении
+// @Description Регистрирует заявление в статусе submitted. Нужен хотя бы один контакт: claimant_email или claimant_phone.
+// @Description declared_value указывается в копейках, currency по умолчанию RUB
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Param claim body models.Claim true "Заявление"
+// @Success 201 {object} models.Claim
+// @Failure 400 {object} errors.Error
+// @Router /claims [post]
+func (h *ClaimHandler) Create(c fiber.Ctx) error {
+	var claim models.Claim
+	if err := c.Bind().Body(&claim); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	if err := h.service.Create(&claim); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(claim)
+}
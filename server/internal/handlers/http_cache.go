@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// CachePolicies — значения Cache-Control для публичных маршрутов чтения процедур.
+// Пустое значение отключает заголовок для маршрута.
+type CachePolicies struct {
+	List   string
+	Item   string
+	ByType string
+}
+
+// sendCached отдаёт body как JSON с валидаторами ETag и Last-Modified и отвечает 304,
+// если копия клиента актуальна. ETag — хеш тела ответа, поэтому меняется при любом
+// изменении представления, включая переводы. tagPrefix добавляется в начало тега.
+// Списки передают время последнего изменения любой процедуры, а не максимум updated_at
+// видимых строк: тот не растёт, когда процедура удаляется, снимается с публикации
+// или теряет тег, и If-Modified-Since вернул бы устаревший 304.
+func sendCached(c fiber.Ctx, cacheControl, tagPrefix string, lastModified time.Time, body interface{}) error {
+	data, err := c.App().Config().JSONEncoder(body)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	etag := hex.EncodeToString(sum[:12])
+	if tagPrefix != "" {
+		etag = tagPrefix + "-" + etag
+	}
+	etag = `"` + etag + `"`
+
+	c.Set(fiber.HeaderETag, etag)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, cacheControl)
+	}
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Send(data)
+}
+
+// notModified проверяет условные заголовки по RFC 9110: If-None-Match имеет приоритет,
+// If-Modified-Since учитывается только без него
+func notModified(c fiber.Ctx, etag string, lastModified time.Time) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, candidate := range strings.Split(noneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	modifiedSince := c.Get(fiber.HeaderIfModifiedSince)
+	if modifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(modifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
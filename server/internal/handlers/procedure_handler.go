@@ -3,43 +3,231 @@ package handlers
 import (
 	"github.com/gofiber/fiber/v3"
 	"strconv"
+	"strings"
 	"tech-quest/internal/domain/models"
 	"tech-quest/internal/services"
 	"tech-quest/pkg/errors"
+	"tech-quest/pkg/jsonpatch"
+	"time"
 )
 
 type ProcedureHandler struct {
-	service *services.ProcedureService
+	service      *services.ProcedureService
+	revisions    *services.RevisionService
+	translations *services.TranslationService
+	// requireIfMatch — изменения без If-Match отклоняются с 428
+	requireIfMatch bool
+	cache          CachePolicies
 }
 
-func NewProcedureHandler(service *services.ProcedureService) *ProcedureHandler {
-	return &ProcedureHandler{service: service}
+func NewProcedureHandler(
+	service *services.ProcedureService,
+	revisions *services.RevisionService,
+	translations *services.TranslationService,
+	requireIfMatch bool,
+	cache CachePolicies,
+) *ProcedureHandler {
+	return &ProcedureHandler{
+		service:        service,
+		revisions:      revisions,
+		translations:   translations,
+		requireIfMatch: requireIfMatch,
+		cache:          cache,
+	}
+}
+
+// setETag выставляет ETag по версии процедуры. Локализованное представление
+// получает отдельный тег, но If-Match сравнивает только версию.
+func setETag(c fiber.Ctx, procedure *models.Procedure) {
+	c.Set(fiber.HeaderETag, `"`+versionTag(procedure)+`"`)
+}
+
+func versionTag(procedure *models.Procedure) string {
+	tag := strconv.Itoa(procedure.Version)
+	if procedure.Locale != "" {
+		tag += "-" + procedure.Locale
+	}
+	return tag
+}
+
+// ifMatchVersion возвращает версию из If-Match; 0 означает, что условие не задано
+// или передан "*". Слабые теги никогда не совпадают при сравнении для If-Match.
+func (h *ProcedureHandler) ifMatchVersion(c fiber.Ctx) (int, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		if h.requireIfMatch {
+			return 0, errors.NewError(
+				fiber.StatusPreconditionRequired,
+				errors.ErrorDetail{
+					Code:   errors.PreconditionRequiredCode,
+					Detail: "If-Match header is required, use the ETag from GET /procedures/{id}/preview",
+				},
+			)
+		}
+		return 0, nil
+	}
+	if header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, errors.NewSimpleError(fiber.StatusBadRequest, "If-Match with several entity tags is not supported")
+	}
+	tag := strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`)
+	version, err := strconv.Atoi(strings.SplitN(tag, "-", 2)[0])
+	if strings.HasPrefix(header, "W/") || err != nil || version <= 0 {
+		return 0, errors.NewError(
+			fiber.StatusPreconditionFailed,
+			errors.ErrorDetail{
+				Code:   errors.PreconditionFailedCode,
+				Detail: "If-Match does not match the current version of the procedure",
+			},
+		)
+	}
+	return version, nil
 }
 
-// GetAll возвращает все процедуры
-// @Summary Получить все процедуры
-// @Description Возвращает список всех процедур, отсортированных по sort_order
+// localize применяет переводы по ?lang= и Accept-Language и выставляет Content-Language,
+// если все процедуры отданы на одном языке
+func (h *ProcedureHandler) localize(c fiber.Ctx, procedures []models.Procedure) error {
+	chain, err := h.translations.Negotiate(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+	if err != nil {
+		return err
+	}
+	if err := h.translations.Localize(procedures, chain); err != nil {
+		return err
+	}
+	c.Vary(fiber.HeaderAcceptLanguage)
+	if len(procedures) == 0 {
+		return nil
+	}
+	for _, procedure := range procedures[1:] {
+		if procedure.Locale != procedures[0].Locale {
+			return nil
+		}
+	}
+	c.Set(fiber.HeaderContentLanguage, procedures[0].Locale)
+	return nil
+}
+
+// GetAll возвращает страницу процедур
+// @Summary Получить список процедур
+// @Description Возвращает страницу опубликованных процедур. Пагинация по курсору: next_cursor передаётся в after.
+// @Description С параметром as_of возвращает состояние процедур на указанный момент, без пагинации и фильтров
 // @Tags procedures
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Procedure
+// @Param limit query int false "Размер страницы (по умолчанию 50, не больше 100)"
+// @Param after query string false "Курсор следующей страницы"
+// @Param sort query string false "Поле сортировки: sort_order, title, updated_at; префикс - для убывания"
+// @Param type query []string false "Фильтр по типам (можно повторять или перечислить через запятую)"
+// @Param updated_since query string false "Только процедуры, изменённые начиная с момента (RFC 3339)"
+// @Param is_expanded query bool false "Фильтр по признаку раскрытия"
+// @Param tag query []string false "Фильтр по тегам (можно повторять или перечислить через запятую)"
+// @Param tag_mode query string false "all — процедура имеет все теги (по умолчанию), any — хотя бы один"
+// @Param as_of query string false "Момент времени в формате RFC 3339"
+// @Param lang query string false "Язык ответа, имеет приоритет над Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки"
+// @Param If-None-Match header string false "ETag ранее полученного ответа"
+// @Param If-Modified-Since header string false "Last-Modified ранее полученного ответа"
+// @Success 200 {object} models.ProcedurePage
+// @Success 304 "Not Modified"
+// @Failure 400 {object} errors.Error
 // @Router /procedures [get]
 func (h *ProcedureHandler) GetAll(c fiber.Ctx) error {
-	procedures, err := h.service.GetAll()
+	if asOf := c.Query("as_of"); asOf != "" {
+		t, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid as_of parameter: expected RFC 3339 timestamp")
+		}
+		procedures, err := h.revisions.GetAllAsOf(t)
+		if err != nil {
+			return err
+		}
+		return c.JSON(models.ProcedurePage{Items: procedures, Total: len(procedures)})
+	}
+	filter, err := parseProcedureFilter(c)
 	if err != nil {
 		return err
 	}
-	return c.JSON(procedures)
+	// Время изменения читается до данных: изменение между чтениями даст лишний 200, а не устаревший 304
+	modified, err := h.service.LastModified()
+	if err != nil {
+		return err
+	}
+	page, err := h.service.List(filter)
+	if err != nil {
+		return err
+	}
+	if err := h.localize(c, page.Items); err != nil {
+		return err
+	}
+	return sendCached(c, h.cache.List, "", modified, page)
+}
+
+func parseProcedureFilter(c fiber.Ctx) (models.ProcedureFilter, error) {
+	filter := models.ProcedureFilter{
+		After: c.Query("after"),
+		Sort:  c.Query("sort"),
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, errors.NewSimpleError(fiber.StatusBadRequest, "invalid limit parameter")
+		}
+		filter.Limit = limit
+	}
+	for _, value := range c.RequestCtx().QueryArgs().PeekMulti("type") {
+		for _, procedureType := range strings.Split(string(value), ",") {
+			if procedureType = strings.TrimSpace(procedureType); procedureType != "" {
+				filter.Types = append(filter.Types, procedureType)
+			}
+		}
+	}
+	if raw := c.Query("updated_since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, errors.NewSimpleError(fiber.StatusBadRequest, "invalid updated_since parameter: expected RFC 3339 timestamp")
+		}
+		filter.UpdatedSince = &t
+	}
+	if raw := c.Query("is_expanded"); raw != "" {
+		isExpanded, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.NewSimpleError(fiber.StatusBadRequest, "invalid is_expanded parameter")
+		}
+		filter.IsExpanded = &isExpanded
+	}
+	filter.Tags = parseTagFilter(c)
+	return filter, nil
+}
+
+// parseTagFilter читает повторяющийся параметр tag и tag_mode
+func parseTagFilter(c fiber.Ctx) models.TagFilter {
+	filter := models.TagFilter{Mode: c.Query("tag_mode")}
+	for _, value := range c.RequestCtx().QueryArgs().PeekMulti("tag") {
+		for _, tag := range strings.Split(string(value), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Slugs = append(filter.Slugs, tag)
+			}
+		}
+	}
+	return filter
 }
 
 // GetByID возвращает процедуру по ID
 // @Summary Получить процедуру по ID
-// @Description Возвращает процедуру по указанному ID
+// @Description Возвращает опубликованную процедуру по указанному ID
 // @Tags procedures
 // @Accept json
 // @Produce json
 // @Param id path int true "ID процедуры"
+// @Param lang query string false "Язык ответа, имеет приоритет над Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки"
+// @Param If-None-Match header string false "ETag ранее полученного ответа"
+// @Param If-Modified-Since header string false "Last-Modified ранее полученного ответа"
 // @Success 200 {object} models.Procedure
+// @Header 200 {string} ETag "Версия процедуры и хеш представления"
+// @Success 304 "Not Modified"
 // @Failure 404 {object} errors.Error
 // @Router /procedures/{id} [get]
 func (h *ProcedureHandler) GetByID(c fiber.Ctx) error {
@@ -51,33 +239,163 @@ func (h *ProcedureHandler) GetByID(c fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	localized := []models.Procedure{*procedure}
+	if err := h.localize(c, localized); err != nil {
+		return err
+	}
+	return sendCached(c, h.cache.Item, versionTag(&localized[0]), localized[0].UpdatedAt, localized[0])
+}
+
+// Tree возвращает опубликованные процедуры деревом
+// @Summary Получить дерево процедур
+// @Description Возвращает опубликованные процедуры верхнего уровня с вложенными подпроцедурами в порядке sort_order.
+// @Description Подпроцедуры неопубликованных процедур не возвращаются
+// @Tags procedures
+// @Produce json
+// @Param lang query string false "Язык ответа, имеет приоритет над Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки"
+// @Param If-None-Match header string false "ETag ранее полученного ответа"
+// @Param If-Modified-Since header string false "Last-Modified ранее полученного ответа"
+// @Success 200 {array} models.ProcedureNode
+// @Success 304 "Not Modified"
+// @Router /procedures/tree [get]
+func (h *ProcedureHandler) Tree(c fiber.Ctx) error {
+	modified, err := h.service.LastModified()
+	if err != nil {
+		return err
+	}
+	procedures, err := h.service.GetAll()
+	if err != nil {
+		return err
+	}
+	if err := h.localize(c, procedures); err != nil {
+		return err
+	}
+	return sendCached(c, h.cache.List, "", modified, services.BuildProcedureTree(procedures))
+}
+
+// PreviewAll возвращает процедуры во всех статусах
+// @Summary Предпросмотр всех процедур
+// @Description Возвращает процедуры во всех статусах (draft, in_review, published, archived) для редакторов.
+// @Description Только для администраторов
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {array} models.Procedure
+// @Failure 401 "Unauthorized"
+// @Router /procedures/preview [get]
+func (h *ProcedureHandler) PreviewAll(c fiber.Ctx) error {
+	procedures, err := h.service.PreviewAll()
+	if err != nil {
+		return err
+	}
+	return c.JSON(procedures)
+}
+
+// Preview возвращает процедуру в любом статусе
+// @Summary Предпросмотр процедуры
+// @Description Возвращает процедуру по ID независимо от статуса публикации. Только для администраторов
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID процедуры"
+// @Success 200 {object} models.Procedure
+// @Header 200 {string} ETag "Версия процедуры"
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/preview [get]
+func (h *ProcedureHandler) Preview(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	procedure, err := h.service.Preview(id)
+	if err != nil {
+		return err
+	}
+	setETag(c, procedure)
 	return c.JSON(procedure)
 }
 
 // GetByType возвращает процедуры по типу
 // @Summary Получить процедуры по типу
-// @Description Возвращает список процедур указанного типа
+// @Description Возвращает список опубликованных процедур указанного типа, с фильтром по тегам
 // @Tags procedures
 // @Accept json
 // @Produce json
 // @Param type path string true "Тип процедуры"
+// @Param tag query []string false "Фильтр по тегам (можно повторять или перечислить через запятую)"
+// @Param tag_mode query string false "all — процедура имеет все теги (по умолчанию), any — хотя бы один"
+// @Param lang query string false "Язык ответа, имеет приоритет над Accept-Language"
+// @Param Accept-Language header string false "Предпочитаемые языки"
+// @Param If-None-Match header string false "ETag ранее полученного ответа"
+// @Param If-Modified-Since header string false "Last-Modified ранее полученного ответа"
 // @Success 200 {array} models.Procedure
+// @Success 304 "Not Modified"
 // @Router /procedures/type/{type} [get]
 func (h *ProcedureHandler) GetByType(c fiber.Ctx) error {
 	procedureType := c.Params("type")
 	if procedureType == "" {
 		return errors.NewSimpleError(fiber.StatusBadRequest, "type parameter is required")
 	}
-	procedures, err := h.service.GetByType(procedureType)
+	modified, err := h.service.LastModified()
 	if err != nil {
 		return err
 	}
-	return c.JSON(procedures)
+	procedures, err := h.service.GetByType(procedureType, parseTagFilter(c))
+	if err != nil {
+		return err
+	}
+	if err := h.localize(c, procedures); err != nil {
+		return err
+	}
+	return sendCached(c, h.cache.ByType, "", modified, procedures)
+}
+
+// Search выполняет полнотекстовый поиск процедур
+// @Summary Полнотекстовый поиск процедур
+// @Description Ищет опубликованные процедуры по заголовку и содержимому, включая переводы на выбранный язык
+// @Tags procedures
+// @Produce json
+// @Param q query string true "Поисковый запрос (поддерживается синтаксис websearch: \"фраза\", OR, -слово)"
+// @Param lang query string false "Язык поиска"
+// @Param Accept-Language header string false "Предпочитаемые языки"
+// @Param limit query int false "Максимум результатов (по умолчанию 20, не больше 50)"
+// @Success 200 {array} models.SearchResult
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /procedures/search [get]
+func (h *ProcedureHandler) Search(c fiber.Ctx) error {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid limit parameter")
+		}
+		limit = parsed
+	}
+	chain, err := h.translations.Negotiate(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+	if err != nil {
+		return err
+	}
+	results, err := h.service.Search(c.Query("q"), chain[0], limit)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		if results[i].Procedure.Locale == "" {
+			results[i].Procedure.Locale = h.translations.DefaultLocale()
+		}
+	}
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return c.JSON(results)
 }
 
 // Create создает новую процедуру
 // @Summary Создать новую процедуру
-// @Description Создает новую процедуру с указанными данными
+// @Description Создает новую процедуру с указанными данными в статусе draft или in_review
 // @Tags procedures
 // @Accept json
 // @Produce json
@@ -104,43 +422,340 @@ func (h *ProcedureHandler) Create(c fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "ID процедуры"
 // @Param procedure body models.Procedure true "Обновленные данные процедуры"
+// @Param If-Match header string false "ETag процедуры, полученный при чтении"
 // @Success 200 {object} models.Procedure
+// @Header 200 {string} ETag "Новая версия процедуры"
 // @Failure 400 {object} errors.Error
 // @Failure 404 {object} errors.Error
+// @Failure 412 {object} errors.Error
+// @Failure 428 {object} errors.Error
 // @Router /procedures/{id} [put]
 func (h *ProcedureHandler) Update(c fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
 		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
 	}
+	version, err := h.ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
 	var procedure models.Procedure
 	if err := c.Bind().Body(&procedure); err != nil {
 		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
 	}
 	procedure.ID = id
+	procedure.Version = version
 	if err := h.service.Update(&procedure); err != nil {
 		return err
 	}
+	setETag(c, &procedure)
+	return c.JSON(procedure)
+}
+
+// Patch частично обновляет процедуру
+// @Summary Частично обновить процедуру
+// @Description Принимает JSON Merge Patch (application/merge-patch+json, RFC 7396)
+// @Description или JSON Patch (application/json-patch+json, RFC 6902). Патч применяется к сохранённой
+// @Description процедуре, результат проверяется как при PUT, записываются только изменённые поля
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param patch body object true "Merge patch или массив операций JSON Patch"
+// @Param If-Match header string false "ETag процедуры, полученный при чтении"
+// @Success 200 {object} models.Procedure
+// @Header 200 {string} ETag "Новая версия процедуры"
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 412 {object} errors.Error
+// @Failure 415 {object} errors.Error
+// @Failure 422 {object} errors.Error
+// @Failure 428 {object} errors.Error
+// @Router /procedures/{id} [patch]
+func (h *ProcedureHandler) Patch(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	c.Set("Accept-Patch", jsonpatch.MergePatchContentType+", "+jsonpatch.JSONPatchContentType)
+	version, err := h.ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+	procedure, err := h.service.Patch(id, version, contentType, c.Body())
+	if err != nil {
+		return err
+	}
+	setETag(c, procedure)
+	return c.JSON(procedure)
+}
+
+// Export выгружает процедуры в файл
+// @Summary Экспорт процедур
+// @Description Выгружает процедуры во всех статусах, кроме корзины, в формате json, yaml или csv.
+// @Description Родитель подпроцедуры записывается его slug'ом в поле parent. Файл можно загрузить обратно
+// @Description через POST /procedures/import. Только для администраторов
+// @Tags procedures
+// @Produce json
+// @Produce application/yaml
+// @Produce text/csv
+// @Security BasicAuth
+// @Param format query string false "Формат файла: json (по умолчанию), yaml или csv"
+// @Success 200 {array} models.ProcedureRecord
+// @Failure 400 {object} errors.Error
+// @Failure 401 "Unauthorized"
+// @Router /procedures/export [get]
+func (h *ProcedureHandler) Export(c fiber.Ctx) error {
+	format := c.Query("format", services.TransferJSON)
+	data, err := h.service.Export(format)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, services.TransferContentTypes[format]+"; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="procedures.`+format+`"`)
+	return c.Send(data)
+}
+
+// Import загружает процедуры из файла
+// @Summary Импорт процедур
+// @Description Создаёт или обновляет процедуры по slug из файла в формате json, yaml или csv.
+// @Description Файл применяется целиком в одной транзакции; при ошибках возвращаются ошибки всех строк
+// @Description с attr вида rows[<индекс>].<поле>, и ничего не сохраняется. Процедуры из корзины восстанавливаются.
+// @Description parent — slug родителя из файла или из базы; без него процедура становится процедурой верхнего уровня.
+// @Description Статус существующей процедуры меняется только по разрешённому переходу, новые процедуры
+// @Description импортируются как draft или in_review, иначе 409. С dry_run=true изменения проверяются, но не сохраняются
+// @Tags procedures
+// @Accept json
+// @Accept application/yaml
+// @Accept text/csv
+// @Produce json
+// @Param format query string false "Формат файла; по умолчанию определяется по Content-Type"
+// @Param dry_run query bool false "Только проверить файл и показать, что изменится"
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 422 {object} errors.Error
+// @Router /procedures/import [post]
+func (h *ProcedureHandler) Import(c fiber.Ctx) error {
+	format := c.Query("format")
+	if format == "" {
+		format = transferFormatByContentType(c.Get(fiber.HeaderContentType))
+	}
+	dryRun, err := strconv.ParseBool(c.Query("dry_run", "false"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid dry_run parameter")
+	}
+	report, err := h.service.Import(format, c.Body(), dryRun)
+	if err != nil {
+		return err
+	}
+	return c.JSON(report)
+}
+
+func transferFormatByContentType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return services.TransferYAML
+	case "text/csv":
+		return services.TransferCSV
+	}
+	return services.TransferJSON
+}
+
+// Trash возвращает процедуры в корзине
+// @Summary Корзина процедур
+// @Description Возвращает удалённые процедуры, недавно удалённые первыми.
+// @Description Процедуры окончательно удаляются по истечении срока хранения в корзине. Только для администраторов
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {array} models.Procedure
+// @Failure 401 "Unauthorized"
+// @Router /procedures/trash [get]
+func (h *ProcedureHandler) Trash(c fiber.Ctx) error {
+	procedures, err := h.service.Trash()
+	if err != nil {
+		return err
+	}
+	return c.JSON(procedures)
+}
+
+// Restore возвращает процедуру из корзины
+// @Summary Восстановить процедуру из корзины
+// @Description Возвращает процедуру из корзины с тем статусом, который был до удаления. Только для администраторов
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID процедуры"
+// @Success 200 {object} models.Procedure
+// @Header 200 {string} ETag "Версия процедуры"
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/restore [post]
+func (h *ProcedureHandler) Restore(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	procedure, err := h.service.Restore(id)
+	if err != nil {
+		return err
+	}
+	setETag(c, procedure)
 	return c.JSON(procedure)
 }
 
-// Delete удаляет процедуру по ID
+// Purge окончательно удаляет процедуру из корзины
+// @Summary Окончательно удалить процедуру
+// @Description Удаляет процедуру из корзины вместе с переводами и историей ревизий. Только для администраторов
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID процедуры"
+// @Success 204 "No Content"
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /procedures/trash/{id} [delete]
+func (h *ProcedureHandler) Purge(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	if err := h.service.Purge(id); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Delete перемещает процедуру в корзину
 // @Summary Удалить процедуру
-// @Description Удаляет процедуру по указанному ID
+// @Description Перемещает процедуру в корзину; её можно вернуть через POST /procedures/{id}/restore.
+// @Description Параметр children задаёт судьбу подпроцедур: reject (по умолчанию) отклоняет удаление,
+// @Description cascade перемещает в корзину всё поддерево, reparent переносит подпроцедуры к родителю удаляемой
 // @Tags procedures
 // @Accept json
 // @Produce json
 // @Param id path int true "ID процедуры"
+// @Param children query string false "reject, cascade или reparent"
+// @Param If-Match header string false "ETag процедуры, полученный при чтении"
 // @Success 204 "No Content"
+// @Failure 400 {object} errors.Error
 // @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 412 {object} errors.Error
+// @Failure 428 {object} errors.Error
 // @Router /procedures/{id} [delete]
 func (h *ProcedureHandler) Delete(c fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
 		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
 	}
-	if err := h.service.Delete(id); err != nil {
+	version, err := h.ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	children := models.ChildrenOnDelete(c.Query("children"))
+	if err := h.service.Delete(id, version, children); err != nil {
 		return err
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// Move переносит процедуру к другому родителю
+// @Summary Перенести процедуру
+// @Description Переносит процедуру вместе с подпроцедурами под parent_id или на верхний уровень, если parent_id равен null.
+// @Description Процедура становится последней среди подпроцедур нового родителя
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param move body models.ProcedureParentChange true "Новый родитель"
+// @Param If-Match header string false "ETag процедуры, полученный при чтении"
+// @Success 200 {object} models.Procedure
+// @Header 200 {string} ETag "Новая версия процедуры"
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 412 {object} errors.Error
+// @Failure 422 {object} errors.Error
+// @Failure 428 {object} errors.Error
+// @Router /procedures/{id}/move [post]
+func (h *ProcedureHandler) Move(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	version, err := h.ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	var change models.ProcedureParentChange
+	if err := c.Bind().Body(&change); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	procedure, err := h.service.Move(id, version, change.ParentID)
+	if err != nil {
+		return err
+	}
+	setETag(c, procedure)
+	return c.JSON(procedure)
+}
+
+// Transition меняет статус процедуры
+// @Summary Сменить статус процедуры
+// @Description Переводит процедуру в новый статус. Разрешены переходы draft→in_review→published→archived, возврат в draft и draft→archived
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param status body models.ProcedureStatusChange true "Новый статус"
+// @Success 200 {object} models.Procedure
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /procedures/{id}/status [post]
+func (h *ProcedureHandler) Transition(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	var change models.ProcedureStatusChange
+	if err := c.Bind().Body(&change); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	procedure, err := h.service.Transition(id, change.Status)
+	if err != nil {
+		return err
+	}
+	setETag(c, procedure)
+	return c.JSON(procedure)
+}
+
+// Reorder меняет порядок процедур
+// @Summary Изменить порядок процедур
+// @Description Атомарно переписывает sort_order. Принимает полный упорядоченный список ids
+// @Description (опционально в пределах type) либо операцию move: {"id": X, "before": Y} или {"id": X, "after": Y}
+// @Tags procedures
+// @Accept json
+// @Produce json
+// @Param reorder body models.ReorderRequest true "Новый порядок"
+// @Success 200 {array} models.ProcedureOrder
+// @Failure 400 {object} errors.Error
+// @Failure 422 {object} errors.Error
+// @Router /procedures/reorder [post]
+func (h *ProcedureHandler) Reorder(c fiber.Ctx) error {
+	var req models.ReorderRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	order, err := h.service.Reorder(&req)
+	if err != nil {
+		return err
+	}
+	return c.JSON(order)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// procedureStreamRetry — пауза перед переподключением EventSource, мс
+const procedureStreamRetry = 3000
+
+type ProcedureStreamHandler struct {
+	stream    *services.ProcedureStream
+	heartbeat time.Duration
+}
+
+func NewProcedureStreamHandler(stream *services.ProcedureStream, heartbeat time.Duration) *ProcedureStreamHandler {
+	return &ProcedureStreamHandler{stream: stream, heartbeat: heartbeat}
+}
+
+// Stream отдаёт изменения процедур через Server-Sent Events
+// @Summary Поток изменений процедур
+// @Description Server-Sent Events с типами created, updated, deleted, reordered и resync.
+// @Description created/updated/reordered содержат процедуру, deleted — {"id": ...}.
+// @Description resync означает, что пропущенные события восстановить нельзя и список нужно перечитать.
+// @Description Переподключение с Last-Event-ID продолжает поток из буфера последних событий
+// @Tags procedures
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Param last_event_id query string false "То же, что Last-Event-ID, для клиентов без поддержки заголовка"
+// @Success 200 {string} string "Поток событий"
+// @Router /procedures/stream [get]
+func (h *ProcedureStreamHandler) Stream(c fiber.Ctx) error {
+	lastEventID := c.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	events, backlog, unsubscribe := h.stream.Subscribe(lastEventID)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	heartbeat := h.heartbeat
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		fmt.Fprintf(w, "retry: %d\n\n", procedureStreamRetry)
+		for _, event := range backlog {
+			writeProcedureEvent(w, event)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				writeProcedureEvent(w, event)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func writeProcedureEvent(w *bufio.Writer, event models.ProcedureEvent) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
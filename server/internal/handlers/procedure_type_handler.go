@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type ProcedureTypeHandler struct {
+	service *services.ProcedureTypeService
+}
+
+func NewProcedureTypeHandler(service *services.ProcedureTypeService) *ProcedureTypeHandler {
+	return &ProcedureTypeHandler{service: service}
+}
+
+// GetAll возвращает справочник типов процедур
+// @Summary Получить типы процедур
+// @Description Возвращает все типы процедур, отсортированные по sort_order, для группировки разделов
+// @Tags procedure-types
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.ProcedureType
+// @Router /procedure-types [get]
+func (h *ProcedureTypeHandler) GetAll(c fiber.Ctx) error {
+	types, err := h.service.GetAll()
+	if err != nil {
+		return err
+	}
+	return c.JSON(types)
+}
+
+// GetBySlug возвращает тип процедуры
+// @Summary Получить тип процедуры
+// @Description Возвращает тип процедуры по slug
+// @Tags procedure-types
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug типа"
+// @Success 200 {object} models.ProcedureType
+// @Failure 404 {object} errors.Error
+// @Router /procedure-types/{slug} [get]
+func (h *ProcedureTypeHandler) GetBySlug(c fiber.Ctx) error {
+	procedureType, err := h.service.GetBySlug(c.Params("slug"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(procedureType)
+}
+
+// Create создает тип процедуры
+// @Summary Создать тип процедуры
+// @Description Добавляет новый тип процедуры в справочник
+// @Tags procedure-types
+// @Accept json
+// @Produce json
+// @Param type body models.ProcedureType true "Тип процедуры"
+// @Success 201 {object} models.ProcedureType
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /procedure-types [post]
+func (h *ProcedureTypeHandler) Create(c fiber.Ctx) error {
+	var procedureType models.ProcedureType
+	if err := c.Bind().Body(&procedureType); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	if err := h.service.Create(&procedureType); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(procedureType)
+}
+
+// Update обновляет тип процедуры
+// @Summary Обновить тип процедуры
+// @Description Обновляет название, описание, иконку и порядок типа. Slug не меняется
+// @Tags procedure-types
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug типа"
+// @Param type body models.ProcedureType true "Тип процедуры"
+// @Success 200 {object} models.ProcedureType
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /procedure-types/{slug} [put]
+func (h *ProcedureTypeHandler) Update(c fiber.Ctx) error {
+	var procedureType models.ProcedureType
+	if err := c.Bind().Body(&procedureType); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	procedureType.Slug = c.Params("slug")
+	if err := h.service.Update(&procedureType); err != nil {
+		return err
+	}
+	return c.JSON(procedureType)
+}
+
+// Delete удаляет тип процедуры
+// @Summary Удалить тип процедуры
+// @Description Удаляет тип процедуры, если на него не ссылается ни одна процедура
+// @Tags procedure-types
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug типа"
+// @Success 204 "No Content"
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /procedure-types/{slug} [delete]
+func (h *ProcedureTypeHandler) Delete(c fiber.Ctx) error {
+	if err := h.service.Delete(c.Params("slug")); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type RevisionHandler struct {
+	service *services.RevisionService
+}
+
+func NewRevisionHandler(service *services.RevisionService) *RevisionHandler {
+	return &RevisionHandler{service: service}
+}
+
+// GetByProcedure возвращает историю ревизий процедуры
+// @Summary Получить ревизии процедуры
+// @Description Возвращает все ревизии процедуры, начиная с последней. Доступно и для удалённых процедур
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Success 200 {array} models.ProcedureRevision
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/revisions [get]
+func (h *RevisionHandler) GetByProcedure(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	revisions, err := h.service.GetByProcedure(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(revisions)
+}
+
+// Get возвращает ревизию процедуры по номеру
+// @Summary Получить ревизию процедуры
+// @Description Возвращает снимок процедуры на момент указанной ревизии
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} models.ProcedureRevision
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/revisions/{rev} [get]
+func (h *RevisionHandler) Get(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	rev, err := strconv.Atoi(c.Params("rev"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid rev parameter")
+	}
+	revision, err := h.service.Get(id, rev)
+	if err != nil {
+		return err
+	}
+	return c.JSON(revision)
+}
+
+// Diff возвращает различия между двумя ревизиями
+// @Summary Сравнить ревизии процедуры
+// @Description Возвращает список полей, изменившихся между ревизиями from и to
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param from query int true "Исходная ревизия"
+// @Param to query int true "Целевая ревизия"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/revisions/diff [get]
+func (h *RevisionHandler) Diff(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid from parameter")
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid to parameter")
+	}
+	diff, err := h.service.Diff(id, from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(diff)
+}
+
+// Restore восстанавливает процедуру из ревизии
+// @Summary Откатить процедуру к ревизии
+// @Description Восстанавливает состояние процедуры из указанной ревизии и создаёт новую ревизию restore.
+// @Description Если ревизия ссылается на удалённые с тех пор вложения, возвращается 422
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} models.Procedure
+// @Failure 404 {object} errors.Error
+// @Failure 422 {object} errors.Error
+// @Router /procedures/{id}/revisions/{rev}/restore [post]
+func (h *RevisionHandler) Restore(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	rev, err := strconv.Atoi(c.Params("rev"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid rev parameter")
+	}
+	procedure, err := h.service.Restore(id, rev)
+	if err != nil {
+		return err
+	}
+	setETag(c, procedure)
+	return c.JSON(procedure)
+}
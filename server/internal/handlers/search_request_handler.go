@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type SearchRequestHandler struct {
+	service *services.SearchRequestService
+}
+
+func NewSearchRequestHandler(service *services.SearchRequestService) *SearchRequestHandler {
+	return &SearchRequestHandler{service: service}
+}
+
+// GetAll возвращает страницу запросов на розыск
+// @Summary Получить запросы на розыск
+// @Description Возвращает запросы на розыск, новые первыми. overdue=true оставляет открытые запросы с истёкшим сроком ответа.
+// @Description Пагинация по курсору: next_cursor передаётся в after. Только для администраторов
+// @Tags search-requests
+// @Produce json
+// @Security BasicAuth
+// @Param limit query int false "Размер страницы (по умолчанию 50, не больше 100)"
+// @Param after query string false "Курсор следующей страницы"
+// @Param status query string false "Статус: open, found или not_found"
+// @Param tracking_number query string false "Трек-номер отправления"
+// @Param overdue query bool false "Только просроченные"
+// @Success 200 {object} models.SearchRequestPage
+// @Failure 400 {object} errors.Error
+// @Failure 401 "Unauthorized"
+// @Router /search-requests [get]
+func (h *SearchRequestHandler) GetAll(c fiber.Ctx) error {
+	filter := models.SearchRequestFilter{
+		Status:         models.SearchRequestStatus(c.Query("status")),
+		TrackingNumber: c.Query("tracking_number"),
+	}
+	if raw := c.Query("overdue"); raw != "" {
+		overdue, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid overdue parameter")
+		}
+		filter.Overdue = overdue
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid limit parameter")
+		}
+		filter.Limit = limit
+	}
+	if raw := c.Query("after"); raw != "" {
+		after, err := strconv.Atoi(raw)
+		if err != nil || after <= 0 {
+			return errors.NewSimpleError(fiber.StatusBadRequest, "invalid after parameter")
+		}
+		filter.After = after
+	}
+	page, err := h.service.List(filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(page)
+}
+
+// GetByID возвращает запрос на розыск
+// @Summary Получить запрос на розыск
+// @Description Возвращает запрос на розыск по ID со сроком ответа и уровнем эскалации. Только для администраторов
+// @Tags search-requests
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID запроса"
+// @Success 200 {object} models.SearchRequest
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Router /search-requests/{id} [get]
+func (h *SearchRequestHandler) GetByID(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	request, err := h.service.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(request)
+}
+
+// Create регистрирует запрос на розыск
+// @Summary Подать запрос на розыск отправления
+// @Description Регистрирует открытый запрос на розыск. Срок ответа due_at рассчитывается по SLA вида розыска:
+// @Description SEARCH_REQUEST_SLA_DOMESTIC для domestic, SEARCH_REQUEST_SLA_INTERNATIONAL для international.
+// @Description Нужен хотя бы один контакт: contact_email или contact_phone
+// @Tags search-requests
+// @Accept json
+// @Produce json
+// @Param request body models.SearchRequest true "Запрос на розыск"
+// @Success 201 {object} models.SearchRequest
+// @Failure 400 {object} errors.Error
+// @Router /search-requests [post]
+func (h *SearchRequestHandler) Create(c fiber.Ctx) error {
+	var request models.SearchRequest
+	if err := c.Bind().Body(&request); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	if err := h.service.Create(&request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+// Resolve завершает розыск
+// @Summary Завершить розыск
+// @Description Завершает открытый запрос результатом found или not_found с итогом для клиента. Только для администраторов
+// @Tags search-requests
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "ID запроса"
+// @Param resolution body models.SearchRequestResolution true "Результат розыска"
+// @Success 200 {object} models.SearchRequest
+// @Failure 400 {object} errors.Error
+// @Failure 401 "Unauthorized"
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /search-requests/{id}/resolve [post]
+func (h *SearchRequestHandler) Resolve(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	var resolution models.SearchRequestResolution
+	if err := c.Bind().Body(&resolution); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	request, err := h.service.Resolve(id, resolution)
+	if err != nil {
+		return err
+	}
+	return c.JSON(request)
+}
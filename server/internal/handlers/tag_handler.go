@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type TagHandler struct {
+	service *services.TagService
+}
+
+func NewTagHandler(service *services.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// GetAll возвращает все теги
+// @Summary Получить теги
+// @Description Возвращает все теги, отсортированные по slug
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Tag
+// @Router /tags [get]
+func (h *TagHandler) GetAll(c fiber.Ctx) error {
+	tags, err := h.service.GetAll()
+	if err != nil {
+		return err
+	}
+	return c.JSON(tags)
+}
+
+// GetBySlug возвращает тег
+// @Summary Получить тег
+// @Description Возвращает тег по slug
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug тега"
+// @Success 200 {object} models.Tag
+// @Failure 404 {object} errors.Error
+// @Router /tags/{slug} [get]
+func (h *TagHandler) GetBySlug(c fiber.Ctx) error {
+	tag, err := h.service.GetBySlug(c.Params("slug"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(tag)
+}
+
+// Create создает тег
+// @Summary Создать тег
+// @Description Добавляет новый тег, который затем можно назначать процедурам
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body models.Tag true "Тег"
+// @Success 201 {object} models.Tag
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Router /tags [post]
+func (h *TagHandler) Create(c fiber.Ctx) error {
+	var tag models.Tag
+	if err := c.Bind().Body(&tag); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	if err := h.service.Create(&tag); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(tag)
+}
+
+// Update обновляет тег
+// @Summary Обновить тег
+// @Description Обновляет название тега. Slug не меняется
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug тега"
+// @Param tag body models.Tag true "Тег"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Router /tags/{slug} [put]
+func (h *TagHandler) Update(c fiber.Ctx) error {
+	var tag models.Tag
+	if err := c.Bind().Body(&tag); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	tag.Slug = c.Params("slug")
+	if err := h.service.Update(&tag); err != nil {
+		return err
+	}
+	return c.JSON(tag)
+}
+
+// Delete удаляет тег
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех процедур
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param slug path string true "Slug тега"
+// @Success 204 "No Content"
+// @Failure 404 {object} errors.Error
+// @Router /tags/{slug} [delete]
+func (h *TagHandler) Delete(c fiber.Ctx) error {
+	if err := h.service.Delete(c.Params("slug")); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services"
+	"tech-quest/pkg/errors"
+)
+
+type TranslationHandler struct {
+	service *services.TranslationService
+}
+
+func NewTranslationHandler(service *services.TranslationService) *TranslationHandler {
+	return &TranslationHandler{service: service}
+}
+
+// GetByProcedure возвращает все переводы процедуры
+// @Summary Получить переводы процедуры
+// @Description Возвращает переводы заголовка и содержимого процедуры на все локали
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Success 200 {array} models.ProcedureTranslation
+// @Router /procedures/{id}/translations [get]
+func (h *TranslationHandler) GetByProcedure(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	translations, err := h.service.GetByProcedure(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(translations)
+}
+
+// Get возвращает перевод процедуры на локаль
+// @Summary Получить перевод процедуры
+// @Description Возвращает перевод процедуры на указанную локаль
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param locale path string true "Локаль, например en"
+// @Success 200 {object} models.ProcedureTranslation
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/translations/{locale} [get]
+func (h *TranslationHandler) Get(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	translation, err := h.service.Get(id, c.Params("locale"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(translation)
+}
+
+// Upsert создает или обновляет перевод процедуры
+// @Summary Сохранить перевод процедуры
+// @Description Создает или заменяет перевод процедуры на указанную локаль
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param locale path string true "Локаль, например en"
+// @Param translation body models.ProcedureTranslation true "Перевод"
+// @Success 200 {object} models.ProcedureTranslation
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 422 {object} errors.Error
+// @Router /procedures/{id}/translations/{locale} [put]
+func (h *TranslationHandler) Upsert(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	var translation models.ProcedureTranslation
+	if err := c.Bind().Body(&translation); err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
+	}
+	translation.ProcedureID = id
+	translation.Locale = c.Params("locale")
+	if err := h.service.Upsert(&translation); err != nil {
+		return err
+	}
+	return c.JSON(translation)
+}
+
+// Delete удаляет перевод процедуры
+// @Summary Удалить перевод процедуры
+// @Description Удаляет перевод процедуры на указанную локаль
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID процедуры"
+// @Param locale path string true "Локаль, например en"
+// @Success 204 "No Content"
+// @Failure 404 {object} errors.Error
+// @Router /procedures/{id}/translations/{locale} [delete]
+func (h *TranslationHandler) Delete(c fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return errors.NewSimpleError(fiber.StatusBadRequest, "invalid id parameter")
+	}
+	if err := h.service.Delete(id, c.Params("locale")); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Missing возвращает отчет о недостающих переводах
+// @Summary Недостающие переводы
+// @Description Возвращает для каждой поддерживаемой локали список неархивных процедур без перевода
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.MissingTranslations
+// @Router /translations/missing [get]
+func (h *TranslationHandler) Missing(c fiber.Ctx) error {
+	report, err := h.service.Missing()
+	if err != nil {
+		return err
+	}
+	return c.JSON(report)
+}
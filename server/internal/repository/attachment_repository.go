@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const attachmentColumns = `id, file_name, content_type, size, checksum, storage_key, created_at`
+
+type AttachmentRepos interface {
+	GetAll() ([]models.Attachment, error)
+	GetByID(id int) (*models.Attachment, error)
+	Create(attachment *models.Attachment) error
+	Delete(id int) (*models.Attachment, error)
+}
+
+type AttachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepository(db *sqlx.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// GetAll возвращает вложения, недавно загруженные первыми
+func (r *AttachmentRepository) GetAll() ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		ORDER BY created_at DESC, id DESC
+	`
+	if err := r.db.Select(&attachments, query); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) GetByID(id int) (*models.Attachment, error) {
+	var attachment models.Attachment
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1`
+	if err := r.db.Get(&attachment, query, id); err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) Create(attachment *models.Attachment) error {
+	query := `
+		INSERT INTO attachments (file_name, content_type, size, checksum, storage_key)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(
+		query,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.StorageKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+}
+
+// Delete удаляет запись о вложении и возвращает её, чтобы вызывающий код удалил файл.
+// Если на вложение ссылается содержимое процедуры (в том числе в корзине) или перевода,
+// возвращается errors.ErrConflict.
+func (r *AttachmentRepository) Delete(id int) (*models.Attachment, error) {
+	var attachment models.Attachment
+	reference := fmt.Sprintf(`[{"attachment_id": %d}]`, id)
+	query := `
+		DELETE FROM attachments
+		WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM procedures WHERE content @> $2::jsonb)
+			AND NOT EXISTS (SELECT 1 FROM procedure_translations WHERE content @> $2::jsonb)
+		RETURNING ` + attachmentColumns
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		// Отдельная блокировка дожидается транзакций, которые сейчас ссылаются на
+		// вложение (checkAttachments), и DELETE затем видит их ссылки в новом снимке
+		var exists int
+		err := tx.Get(&exists, `SELECT 1 FROM attachments WHERE id = $1 FOR UPDATE`, id)
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		err = tx.Get(&attachment, query, id, reference)
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrConflict
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// UnknownAttachmentsError — содержимое ссылается на вложения, которых нет
+type UnknownAttachmentsError struct {
+	IDs []int
+}
+
+func (e *UnknownAttachmentsError) Error() string {
+	return fmt.Sprintf("unknown attachments: %v", e.IDs)
+}
+
+// checkAttachments проверяет, что все вложения, на которые ссылается content, существуют.
+// Иначе возвращает *UnknownAttachmentsError. Найденные строки блокируются FOR KEY SHARE
+// до конца транзакции, чтобы параллельный Delete не удалил вложение до записи ссылки.
+func checkAttachments(tx *sqlx.Tx, content models.ProcedureContent) error {
+	var ids []int
+	for _, item := range content {
+		if item.AttachmentID != 0 {
+			ids = append(ids, item.AttachmentID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var known []int
+	if err := tx.Select(&known, `SELECT id FROM attachments WHERE id = ANY($1) FOR KEY SHARE`, pq.Array(ids)); err != nil {
+		return err
+	}
+	unknown := &UnknownAttachmentsError{}
+	for _, id := range ids {
+		if !slices.Contains(known, id) && !slices.Contains(unknown.IDs, id) {
+			unknown.IDs = append(unknown.IDs, id)
+		}
+	}
+	if len(unknown.IDs) > 0 {
+		return unknown
+	}
+	return nil
+}
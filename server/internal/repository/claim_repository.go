@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// claimColumns — набор колонок, из которых собирается models.Claim
+const claimColumns = `id, tracking_number, kind, status, claimant_name, claimant_email, claimant_phone, declared_value, currency, description, created_at, updated_at`
+
+// claimDocumentColumns — набор колонок, из которых собирается models.ClaimDocument
+const claimDocumentColumns = `id, claim_id, code, file_name, content_type, size, checksum, storage_key, thumbnail_key,
+	thumbnail_key <> '' AS has_thumbnail, created_at`
+
+type ClaimRepos interface {
+	List(filter models.ClaimFilter) (*models.ClaimPage, error)
+	GetByID(id int) (*models.Claim, error)
+	Create(claim *models.Claim) error
+	Transition(id int, from, to models.ClaimStatus, reason string) (*models.Claim, error)
+	ListDocuments(claimID int) ([]models.ClaimDocument, error)
+	GetDocument(claimID, id int) (*models.ClaimDocument, error)
+	CreateDocuments(claimID int, documents []models.ClaimDocument, maxPerCode, maxPerClaim int) ([]models.ClaimDocument, error)
+}
+
+type ClaimRepository struct {
+	db *sqlx.DB
+}
+
+func NewClaimRepository(db *sqlx.DB) *ClaimRepository {
+	return &ClaimRepository{db: db}
+}
+
+// List возвращает страницу заявлений, новые первыми. Следующая страница
+// начинается с заявлений, ID которых меньше filter.After.
+func (r *ClaimRepository) List(filter models.ClaimFilter) (*models.ClaimPage, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions := []string{"TRUE"}
+	if filter.Kind != "" {
+		conditions = append(conditions, "kind = "+arg(filter.Kind))
+	}
+	if filter.TrackingNumber != "" {
+		conditions = append(conditions, "tracking_number = "+arg(filter.TrackingNumber))
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM claims WHERE ` + strings.Join(conditions, " AND ")
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, err
+	}
+
+	if filter.After > 0 {
+		conditions = append(conditions, "id < "+arg(filter.After))
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM claims
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT %s
+	`, claimColumns, strings.Join(conditions, " AND "), arg(filter.Limit+1))
+	claims := make([]models.Claim, 0, filter.Limit+1)
+	if err := r.db.Select(&claims, query, args...); err != nil {
+		return nil, err
+	}
+
+	page := &models.ClaimPage{Items: claims, Total: total}
+	if len(claims) > filter.Limit {
+		page.Items = claims[:filter.Limit]
+		page.NextCursor = strconv.Itoa(page.Items[len(page.Items)-1].ID)
+	}
+	return page, nil
+}
+
+// GetByID возвращает заявление вместе с историей статусов
+func (r *ClaimRepository) GetByID(id int) (*models.Claim, error) {
+	var claim models.Claim
+	query := `SELECT ` + claimColumns + ` FROM claims WHERE id = $1`
+	if err := r.db.Get(&claim, query, id); err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := loadClaimHistory(r.db, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// Create регистрирует заявление в статусе submitted и открывает его историю
+func (r *ClaimRepository) Create(claim *models.Claim) error {
+	query := `
+		INSERT INTO claims (tracking_number, kind, claimant_name, claimant_email, claimant_phone,
+			declared_value, currency, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + claimColumns
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		err := tx.Get(
+			claim,
+			query,
+			claim.TrackingNumber,
+			claim.Kind,
+			claim.ClaimantName,
+			claim.ClaimantEmail,
+			claim.ClaimantPhone,
+			claim.DeclaredValue,
+			claim.Currency,
+			claim.Description,
+		)
+		if err != nil {
+			return err
+		}
+		if err := insertClaimStatusChange(tx, claim.ID, nil, claim.Status, ""); err != nil {
+			return err
+		}
+		return loadClaimHistory(tx, claim)
+	})
+}
+
+// Transition переводит заявление из статуса from в to и дописывает переход в историю.
+// Если заявления нет, возвращается errors.ErrNotFound, если его статус уже не from —
+// errors.ErrConflict.
+func (r *ClaimRepository) Transition(id int, from, to models.ClaimStatus, reason string) (*models.Claim, error) {
+	var claim models.Claim
+	query := `
+		UPDATE claims
+		SET status = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $2
+		RETURNING ` + claimColumns
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := tx.Get(&claim, query, id, from, to); err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				var exists bool
+				if err := tx.Get(&exists, `SELECT EXISTS(SELECT 1 FROM claims WHERE id = $1)`, id); err != nil {
+					return err
+				}
+				if exists {
+					return errors.ErrConflict
+				}
+				return errors.ErrNotFound
+			}
+			return err
+		}
+		if err := insertClaimStatusChange(tx, id, &from, to, reason); err != nil {
+			return err
+		}
+		return loadClaimHistory(tx, &claim)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ListDocuments возвращает документы заявления в порядке загрузки
+func (r *ClaimRepository) ListDocuments(claimID int) ([]models.ClaimDocument, error) {
+	documents := []models.ClaimDocument{}
+	query := `
+		SELECT ` + claimDocumentColumns + `
+		FROM claim_documents
+		WHERE claim_id = $1
+		ORDER BY id ASC
+	`
+	if err := r.db.Select(&documents, query, claimID); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (r *ClaimRepository) GetDocument(claimID, id int) (*models.ClaimDocument, error) {
+	var document models.ClaimDocument
+	query := `SELECT ` + claimDocumentColumns + ` FROM claim_documents WHERE claim_id = $1 AND id = $2`
+	if err := r.db.Get(&document, query, claimID, id); err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &document, nil
+}
+
+// ClaimStatusError — в текущем статусе к заявлению нельзя приложить документы
+type ClaimStatusError struct {
+	Status models.ClaimStatus
+}
+
+func (e *ClaimStatusError) Error() string {
+	return fmt.Sprintf("documents cannot be added to a claim in status %s", e.Status)
+}
+
+// ClaimDocumentLimitError — документы не помещаются в лимит. Code — код требования,
+// лимит которого превышен; пуст, если превышен лимит на всё заявление.
+type ClaimDocumentLimitError struct {
+	Code  string
+	Limit int
+}
+
+func (e *ClaimDocumentLimitError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("at most %d files can be attached to a claim", e.Limit)
+	}
+	return fmt.Sprintf("at most %d files can be attached for %s", e.Limit, e.Code)
+}
+
+// CreateDocuments регистрирует документы заявления одной транзакцией и возвращает их
+// в том же порядке. Строка заявления блокируется FOR UPDATE, поэтому проверки статуса
+// и лимитов не обгоняют параллельные загрузки и смену статуса. Если такой же файл уже
+// загружен по этому требованию, на месте документа возвращается прежний с Duplicate=true:
+// файлы нового вызывающий код должен удалить. Если заявления нет, возвращается
+// errors.ErrNotFound, если статус не принимает документы — *ClaimStatusError,
+// если превышен лимит — *ClaimDocumentLimitError, и ничего не сохраняется.
+func (r *ClaimRepository) CreateDocuments(claimID int, documents []models.ClaimDocument, maxPerCode, maxPerClaim int) ([]models.ClaimDocument, error) {
+	existingQuery := `
+		SELECT ` + claimDocumentColumns + `
+		FROM claim_documents
+		WHERE claim_id = $1 AND code = $2 AND checksum = $3
+	`
+	countQuery := `
+		SELECT COUNT(*) FILTER (WHERE code = $2) AS code, COUNT(*) AS claim
+		FROM claim_documents
+		WHERE claim_id = $1
+	`
+	insertQuery := `
+		INSERT INTO claim_documents (claim_id, code, file_name, content_type, size, checksum, storage_key, thumbnail_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + claimDocumentColumns
+	created := make([]models.ClaimDocument, len(documents))
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		var status models.ClaimStatus
+		err := tx.Get(&status, `SELECT status FROM claims WHERE id = $1 FOR UPDATE`, claimID)
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !status.AcceptsDocuments() {
+			return &ClaimStatusError{Status: status}
+		}
+
+		for i, document := range documents {
+			err := tx.Get(&created[i], existingQuery, claimID, document.Code, document.Checksum)
+			if err == nil {
+				created[i].Duplicate = true
+				continue
+			}
+			if !stderrors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			var counts struct {
+				Code  int `db:"code"`
+				Claim int `db:"claim"`
+			}
+			if err := tx.Get(&counts, countQuery, claimID, document.Code); err != nil {
+				return err
+			}
+			if counts.Code >= maxPerCode {
+				return &ClaimDocumentLimitError{Code: document.Code, Limit: maxPerCode}
+			}
+			if counts.Claim >= maxPerClaim {
+				return &ClaimDocumentLimitError{Limit: maxPerClaim}
+			}
+			err = tx.Get(
+				&created[i],
+				insertQuery,
+				claimID,
+				document.Code,
+				document.FileName,
+				document.ContentType,
+				document.Size,
+				document.Checksum,
+				document.StorageKey,
+				document.ThumbnailKey,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func insertClaimStatusChange(tx *sqlx.Tx, claimID int, from *models.ClaimStatus, to models.ClaimStatus, reason string) error {
+	query := `
+		INSERT INTO claim_status_history (claim_id, from_status, to_status, reason)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := tx.Exec(query, claimID, from, to, reason)
+	return err
+}
+
+// loadClaimHistory заполняет History заявления в порядке переходов
+func loadClaimHistory(db sqlx.Queryer, claim *models.Claim) error {
+	claim.History = []models.ClaimStatusChange{}
+	query := `
+		SELECT id, from_status, to_status, reason, created_at
+		FROM claim_status_history
+		WHERE claim_id = $1
+		ORDER BY id ASC
+	`
+	return sqlx.Select(db, &claim.History, query, claim.ID)
+}
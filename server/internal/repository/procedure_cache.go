@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"tech-quest/internal/domain/models"
+	"time"
+)
+
+// CachedProcedureRepository — ProcedureRepos с кешем чтения в памяти поверх другого
+// ProcedureRepos. Кешируются GetAll, List, GetByType, GetByID и LastChanged; записи
+// размером не больше maxEntries живут ttl и вытесняются по LRU. Любое изменение сбрасывает
+// кеш целиком, потому что списки зависят от всех процедур сразу. Каждый метод ProcedureRepos
+// реализован явно: новый метод интерфейса не скомпилируется, пока здесь не решено,
+// кеширует ли он чтение или сбрасывает кеш.
+type CachedProcedureRepository struct {
+	repo ProcedureRepos
+
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation увеличивается при сбросе, чтобы чтение, начатое до сброса,
+	// не положило в кеш устаревший результат
+	generation uint64
+
+	hits          uint64
+	misses        uint64
+	evictions     uint64
+	invalidations uint64
+}
+
+var _ ProcedureRepos = (*CachedProcedureRepository)(nil)
+
+type procedureCacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func NewCachedProcedureRepository(repo ProcedureRepos, ttl time.Duration, maxEntries int) *CachedProcedureRepository {
+	return &CachedProcedureRepository{
+		repo:       repo,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (r *CachedProcedureRepository) GetAll() ([]models.Procedure, error) {
+	value, err := r.load("all", func() (interface{}, error) {
+		return r.repo.GetAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneProcedures(value.([]models.Procedure)), nil
+}
+
+func (r *CachedProcedureRepository) List(filter models.ProcedureFilter) (*models.ProcedurePage, error) {
+	value, err := r.load(procedureListKey(filter), func() (interface{}, error) {
+		return r.repo.List(filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+	page := *value.(*models.ProcedurePage)
+	page.Items = cloneProcedures(page.Items)
+	page.Facets = slices.Clone(page.Facets)
+	return &page, nil
+}
+
+func (r *CachedProcedureRepository) GetByType(procedureType string, tags models.TagFilter) ([]models.Procedure, error) {
+	value, err := r.load("type:"+procedureType+"|"+tagFilterKey(tags), func() (interface{}, error) {
+		return r.repo.GetByType(procedureType, tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneProcedures(value.([]models.Procedure)), nil
+}
+
+func (r *CachedProcedureRepository) GetByID(id int) (*models.Procedure, error) {
+	value, err := r.load(fmt.Sprintf("id:%d", id), func() (interface{}, error) {
+		return r.repo.GetByID(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	procedure := cloneProcedure(*value.(*models.Procedure))
+	return &procedure, nil
+}
+
+func (r *CachedProcedureRepository) LastChanged() (time.Time, error) {
+	value, err := r.load("last_changed", func() (interface{}, error) {
+		return r.repo.LastChanged()
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return value.(time.Time), nil
+}
+
+func (r *CachedProcedureRepository) GetAllAnyStatus() ([]models.Procedure, error) {
+	return r.repo.GetAllAnyStatus()
+}
+
+func (r *CachedProcedureRepository) GetOrder(procedureType string) ([]models.ProcedureOrder, error) {
+	return r.repo.GetOrder(procedureType)
+}
+
+func (r *CachedProcedureRepository) Search(query, locale string, limit int) ([]models.SearchResult, error) {
+	return r.repo.Search(query, locale, limit)
+}
+
+func (r *CachedProcedureRepository) GetTrash() ([]models.Procedure, error) {
+	return r.repo.GetTrash()
+}
+
+func (r *CachedProcedureRepository) Create(procedure *models.Procedure) error {
+	defer r.Invalidate()
+	return r.repo.Create(procedure)
+}
+
+func (r *CachedProcedureRepository) Update(procedure *models.Procedure) error {
+	defer r.Invalidate()
+	return r.repo.Update(procedure)
+}
+
+func (r *CachedProcedureRepository) Patch(procedure *models.Procedure, columns []string) error {
+	defer r.Invalidate()
+	return r.repo.Patch(procedure, columns)
+}
+
+func (r *CachedProcedureRepository) UpdateStatus(id int, from, to models.ProcedureStatus) (*models.Procedure, error) {
+	defer r.Invalidate()
+	return r.repo.UpdateStatus(id, from, to)
+}
+
+func (r *CachedProcedureRepository) ApplySchedule(now time.Time) (int, error) {
+	changed, err := r.repo.ApplySchedule(now)
+	if changed > 0 {
+		r.Invalidate()
+	}
+	return changed, err
+}
+
+func (r *CachedProcedureRepository) Reorder(ids []int, procedureType string) ([]models.ProcedureOrder, error) {
+	defer r.Invalidate()
+	return r.repo.Reorder(ids, procedureType)
+}
+
+func (r *CachedProcedureRepository) Move(id int, parentID *int, version int) (*models.Procedure, error) {
+	defer r.Invalidate()
+	return r.repo.Move(id, parentID, version)
+}
+
+func (r *CachedProcedureRepository) Delete(id, version int, children models.ChildrenOnDelete) error {
+	defer r.Invalidate()
+	return r.repo.Delete(id, version, children)
+}
+
+func (r *CachedProcedureRepository) Restore(id int) (*models.Procedure, error) {
+	defer r.Invalidate()
+	return r.repo.Restore(id)
+}
+
+func (r *CachedProcedureRepository) Purge(id int) error {
+	defer r.Invalidate()
+	return r.repo.Purge(id)
+}
+
+func (r *CachedProcedureRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	purged, err := r.repo.PurgeDeletedBefore(cutoff)
+	if purged > 0 {
+		r.Invalidate()
+	}
+	return purged, err
+}
+
+func (r *CachedProcedureRepository) Import(procedures []models.Procedure, dryRun bool) ([]models.ImportRow, error) {
+	if !dryRun {
+		defer r.Invalidate()
+	}
+	return r.repo.Import(procedures, dryRun)
+}
+
+func (r *CachedProcedureRepository) Seed(fixtures []models.ProcedureFixture, dryRun bool) ([]models.SeedRow, error) {
+	if !dryRun {
+		defer r.Invalidate()
+	}
+	return r.repo.Seed(fixtures, dryRun)
+}
+
+func (r *CachedProcedureRepository) RestoreRevision(procedureID, revision int) (*models.Procedure, error) {
+	defer r.Invalidate()
+	return r.repo.RestoreRevision(procedureID, revision)
+}
+
+// Invalidate сбрасывает все записи кеша
+func (r *CachedProcedureRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	r.invalidations++
+	r.entries = make(map[string]*list.Element)
+	r.lru.Init()
+}
+
+func (r *CachedProcedureRepository) Stats() models.CacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := models.CacheStats{
+		Enabled:       true,
+		Entries:       r.lru.Len(),
+		Hits:          r.hits,
+		Misses:        r.misses,
+		Evictions:     r.evictions,
+		Invalidations: r.invalidations,
+	}
+	if total := r.hits + r.misses; total > 0 {
+		stats.HitRatio = float64(r.hits) / float64(total)
+	}
+	return stats
+}
+
+// load возвращает значение из кеша или получает его через fetch и запоминает.
+// Ошибки, в том числе errors.ErrNotFound, не кешируются.
+func (r *CachedProcedureRepository) load(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	r.mu.Lock()
+	if element, ok := r.entries[key]; ok {
+		entry := element.Value.(*procedureCacheEntry)
+		if time.Now().Before(entry.expires) {
+			r.hits++
+			r.lru.MoveToFront(element)
+			r.mu.Unlock()
+			return entry.value, nil
+		}
+		r.lru.Remove(element)
+		delete(r.entries, key)
+	}
+	r.misses++
+	generation := r.generation
+	r.mu.Unlock()
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if generation != r.generation {
+		return value, nil
+	}
+	if element, ok := r.entries[key]; ok {
+		r.lru.Remove(element)
+	}
+	r.entries[key] = r.lru.PushFront(&procedureCacheEntry{
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(r.ttl),
+	})
+	for r.lru.Len() > r.maxEntries {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.entries, oldest.Value.(*procedureCacheEntry).key)
+		r.evictions++
+	}
+	return value, nil
+}
+
+func procedureListKey(filter models.ProcedureFilter) string {
+	var updatedSince, isExpanded string
+	if filter.UpdatedSince != nil {
+		updatedSince = filter.UpdatedSince.UTC().Format(time.RFC3339Nano)
+	}
+	if filter.IsExpanded != nil {
+		isExpanded = fmt.Sprint(*filter.IsExpanded)
+	}
+	return fmt.Sprintf(
+		"list:%d|%s|%s|%s|%s|%s|%s",
+		filter.Limit, filter.After, filter.Sort, strings.Join(filter.Types, ","), updatedSince, isExpanded,
+		tagFilterKey(filter.Tags),
+	)
+}
+
+func tagFilterKey(filter models.TagFilter) string {
+	if len(filter.Slugs) == 0 {
+		return ""
+	}
+	return filter.Mode + ":" + strings.Join(uniqueStrings(filter.Slugs), ",")
+}
+
+// cloneProcedures копирует процедуры, чтобы вызывающий код мог менять результат
+// (например, при локализации), не портя закешированное значение
+func cloneProcedures(procedures []models.Procedure) []models.Procedure {
+	if procedures == nil {
+		return nil
+	}
+	cloned := make([]models.Procedure, len(procedures))
+	for i, procedure := range procedures {
+		cloned[i] = cloneProcedure(procedure)
+	}
+	return cloned
+}
+
+func cloneProcedure(procedure models.Procedure) models.Procedure {
+	if procedure.ParentID != nil {
+		id := *procedure.ParentID
+		procedure.ParentID = &id
+	}
+	if procedure.Content != nil {
+		procedure.Content = append(models.ProcedureContent{}, procedure.Content...)
+		for i, item := range procedure.Content {
+			if item.Requirement != nil {
+				requirement := *item.Requirement
+				requirement.ClaimKinds = append([]models.ClaimKind(nil), requirement.ClaimKinds...)
+				requirement.ContentTypes = append([]string(nil), requirement.ContentTypes...)
+				procedure.Content[i].Requirement = &requirement
+			}
+		}
+	}
+	if procedure.PublishAt != nil {
+		t := *procedure.PublishAt
+		procedure.PublishAt = &t
+	}
+	if procedure.UnpublishAt != nil {
+		t := *procedure.UnpublishAt
+		procedure.UnpublishAt = &t
+	}
+	if procedure.DeletedAt != nil {
+		t := *procedure.DeletedAt
+		procedure.DeletedAt = &t
+	}
+	if procedure.Tags != nil {
+		procedure.Tags = append([]string{}, procedure.Tags...)
+	}
+	return procedure
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestCachedProcedureRepository_ReadThrough(t *testing.T) {
+	calls := 0
+	inner := &mocks.ProcedureRepoMock{
+		GetAllFn: func() ([]models.Procedure, error) {
+			calls++
+			return []models.Procedure{{ID: 1, Title: "Потеря багажа"}}, nil
+		},
+		UpdateFn: func(*models.Procedure) error {
+			return nil
+		},
+	}
+	cache := repository.NewCachedProcedureRepository(inner, time.Minute, 10)
+
+	first, err := cache.GetAll()
+	require.NoError(t, err)
+	first[0].Title = "изменено вызывающим кодом"
+
+	second, err := cache.GetAll()
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, "Потеря багажа", second[0].Title)
+
+	require.NoError(t, cache.Update(&models.Procedure{ID: 1}))
+	_, err = cache.GetAll()
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	stats := cache.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(2), stats.Misses)
+	require.Equal(t, uint64(1), stats.Invalidations)
+}
+
+func TestCachedProcedureRepository_LastChanged(t *testing.T) {
+	changed := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	calls := 0
+	inner := &mocks.ProcedureRepoMock{
+		LastChangedFn: func() (time.Time, error) {
+			calls++
+			return changed, nil
+		},
+		DeleteFn: func(int, int, models.ChildrenOnDelete) error {
+			return nil
+		},
+	}
+	cache := repository.NewCachedProcedureRepository(inner, time.Minute, 10)
+
+	got, err := cache.LastChanged()
+	require.NoError(t, err)
+	require.Equal(t, changed, got)
+	_, err = cache.LastChanged()
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	// Удаление не меняет видимых строк, но время изменения списков должно обновиться
+	changed = changed.Add(time.Minute)
+	require.NoError(t, cache.Delete(1, 0, models.ChildrenReject))
+	got, err = cache.LastChanged()
+	require.NoError(t, err)
+	require.Equal(t, changed, got)
+	require.Equal(t, 2, calls)
+}
+
+func TestCachedProcedureRepository_Bounds(t *testing.T) {
+	calls := map[int]int{}
+	inner := &mocks.ProcedureRepoMock{
+		GetByIDFn: func(id int) (*models.Procedure, error) {
+			calls[id]++
+			if id == 404 {
+				return nil, appErrors.ErrNotFound
+			}
+			return &models.Procedure{ID: id}, nil
+		},
+	}
+	cache := repository.NewCachedProcedureRepository(inner, time.Minute, 2)
+
+	for _, id := range []int{1, 2, 1, 3, 1, 2} {
+		_, err := cache.GetByID(id)
+		require.NoError(t, err)
+	}
+	// 2 вытеснена при загрузке 3, так как 1 использовалась позже
+	require.Equal(t, map[int]int{1: 1, 2: 2, 3: 1}, calls)
+	require.Equal(t, 2, cache.Stats().Entries)
+	require.Equal(t, uint64(2), cache.Stats().Evictions)
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetByID(404)
+		require.ErrorIs(t, err, appErrors.ErrNotFound)
+	}
+	require.Equal(t, 2, calls[404])
+
+	expiring := repository.NewCachedProcedureRepository(inner, -time.Second, 10)
+	_, _ = expiring.GetByID(5)
+	_, _ = expiring.GetByID(5)
+	require.Equal(t, 2, calls[5])
+}
@@ -1,118 +1,1488 @@
 package repository
 
 import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"tech-quest/internal/domain/models"
 	"tech-quest/pkg/errors"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// procedureColumns — набор колонок, из которых собирается models.Procedure
+const procedureColumns = `id, slug, parent_id, title, type, content, sort_order, is_expanded, status, version, publish_at, unpublish_at, created_at, updated_at, deleted_at`
+
+// ErrInvalidCursor — курсор страницы повреждён или выдан для другой сортировки
+var ErrInvalidCursor = stderrors.New("invalid cursor")
+
+// ErrSlugTaken — slug уже занят другой процедурой, в том числе из корзины
+var ErrSlugTaken = stderrors.New("slug is already taken")
+
+// ErrParentNotFound — родительской процедуры нет или она в корзине
+var ErrParentNotFound = stderrors.New("parent procedure not found")
+
+// ErrParentCycle — процедуру нельзя перенести в её собственное поддерево
+var ErrParentCycle = stderrors.New("procedure cannot be moved under itself or its descendant")
+
+// ErrHasChildren — у удаляемой процедуры есть подпроцедуры
+var ErrHasChildren = stderrors.New("procedure has children")
+
+// ErrParentDeleted — родитель восстанавливаемой процедуры находится в корзине
+var ErrParentDeleted = stderrors.New("parent procedure is in trash")
+
+// hierarchyLockKey — ключ advisory-блокировки, под которой меняется дерево процедур
+const hierarchyLockKey = 20260016
+
+// errDryRun откатывает транзакцию пробного импорта
+var errDryRun = stderrors.New("dry run")
+
+// procedureSortColumns — допустимые колонки сортировки и тип, к которому
+// приводится значение из курсора. В SQL попадают только ключи этой карты.
+var procedureSortColumns = map[string]string{
+	"sort_order": "integer",
+	"title":      "text",
+	"updated_at": "timestamp",
+}
+
+// procedureCursor — позиция последней строки страницы в порядке сортировки
+type procedureCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// procedurePatchColumns — колонки, которые можно обновить точечно через Patch
+var procedurePatchColumns = map[string]func(procedure *models.Procedure) interface{}{
+	"slug":         func(p *models.Procedure) interface{} { return p.Slug },
+	"title":        func(p *models.Procedure) interface{} { return p.Title },
+	"type":         func(p *models.Procedure) interface{} { return p.Type },
+	"content":      func(p *models.Procedure) interface{} { return p.Content },
+	"sort_order":   func(p *models.Procedure) interface{} { return p.SortOrder },
+	"is_expanded":  func(p *models.Procedure) interface{} { return p.IsExpanded },
+	"publish_at":   func(p *models.Procedure) interface{} { return p.PublishAt },
+	"unpublish_at": func(p *models.Procedure) interface{} { return p.UnpublishAt },
+}
+
 type ProcedureRepos interface {
 	GetAll() ([]models.Procedure, error)
+	List(filter models.ProcedureFilter) (*models.ProcedurePage, error)
+	GetAllAnyStatus() ([]models.Procedure, error)
 	GetByID(id int) (*models.Procedure, error)
-	GetByType(procedureType string) ([]models.Procedure, error)
+	GetByType(procedureType string, tags models.TagFilter) ([]models.Procedure, error)
 	Create(procedure *models.Procedure) error
 	Update(procedure *models.Procedure) error
-	Delete(id int) error
+	Patch(procedure *models.Procedure, columns []string) error
+	UpdateStatus(id int, from, to models.ProcedureStatus) (*models.Procedure, error)
+	ApplySchedule(now time.Time) (int, error)
+	GetOrder(procedureType string) ([]models.ProcedureOrder, error)
+	Reorder(ids []int, procedureType string) ([]models.ProcedureOrder, error)
+	Search(query, locale string, limit int) ([]models.SearchResult, error)
+	Move(id int, parentID *int, version int) (*models.Procedure, error)
+	Delete(id, version int, children models.ChildrenOnDelete) error
+	GetTrash() ([]models.Procedure, error)
+	Restore(id int) (*models.Procedure, error)
+	Purge(id int) error
+	PurgeDeletedBefore(cutoff time.Time) (int, error)
+	Import(procedures []models.Procedure, dryRun bool) ([]models.ImportRow, error)
+	Seed(fixtures []models.ProcedureFixture, dryRun bool) ([]models.SeedRow, error)
+	RestoreRevision(procedureID, revision int) (*models.Procedure, error)
+	LastChanged() (time.Time, error)
 }
 
 type ProcedureRepository struct {
 	db *sqlx.DB
+	// maxDepth — максимальная глубина дерева процедур, верхний уровень — 1
+	maxDepth int
 }
 
-func NewProcedureRepository(db *sqlx.DB) *ProcedureRepository {
-	return &ProcedureRepository{db: db}
+func NewProcedureRepository(db *sqlx.DB, maxDepth int) *ProcedureRepository {
+	return &ProcedureRepository{db: db, maxDepth: maxDepth}
 }
 
+// GetAll возвращает только опубликованные процедуры
 func (r *ProcedureRepository) GetAll() ([]models.Procedure, error) {
 	var procedures []models.Procedure
 	query := `
-		SELECT id, title, type, content, sort_order, is_expanded, created_at, updated_at
+		SELECT ` + procedureColumns + `
 		FROM procedures
-		ORDER BY sort_order ASC
+		WHERE status = 'published' AND deleted_at IS NULL
+		ORDER BY sort_order ASC, id ASC
 	`
 	err := r.db.Select(&procedures, query)
 	if err != nil {
 		return nil, err
 	}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
 	return procedures, nil
 }
 
+// LastChanged возвращает время последнего изменения процедур, их переводов или тегов,
+// включая удаление и снятие с публикации
+func (r *ProcedureRepository) LastChanged() (time.Time, error) {
+	var changed time.Time
+	if err := r.db.Get(&changed, `SELECT changed_at FROM procedure_changes_clock`); err != nil {
+		return time.Time{}, err
+	}
+	return changed, nil
+}
+
+// List возвращает страницу опубликованных процедур. Пагинация по курсору:
+// следующая страница начинается строго после (значение поля сортировки, id)
+// последней строки предыдущей. Facets считаются по всем процедурам,
+// прошедшим фильтры, без учёта курсора и limit.
+func (r *ProcedureRepository) List(filter models.ProcedureFilter) (*models.ProcedurePage, error) {
+	field := strings.TrimPrefix(filter.Sort, "-")
+	cast, ok := procedureSortColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", field)
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions := []string{"status = 'published'", "deleted_at IS NULL"}
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, "type = ANY("+arg(pq.Array(filter.Types))+")")
+	}
+	if filter.UpdatedSince != nil {
+		conditions = append(conditions, "updated_at >= "+arg(filter.UpdatedSince.UTC()))
+	}
+	if filter.IsExpanded != nil {
+		conditions = append(conditions, "is_expanded = "+arg(*filter.IsExpanded))
+	}
+	if condition := tagCondition(filter.Tags, arg); condition != "" {
+		conditions = append(conditions, condition)
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM procedures WHERE ` + strings.Join(conditions, " AND ")
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, err
+	}
+	facets := []models.TagFacet{}
+	facetsQuery := `
+		SELECT pt.tag_slug AS tag, COUNT(*) AS count
+		FROM procedure_tags pt
+		JOIN procedures ON procedures.id = pt.procedure_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY pt.tag_slug
+		ORDER BY count DESC, tag ASC
+	`
+	if err := r.db.Select(&facets, facetsQuery, args...); err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if strings.HasPrefix(filter.Sort, "-") {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != "" {
+		cursor, err := decodeProcedureCursor(filter.After)
+		if err != nil || cursor.Sort != filter.Sort {
+			return nil, ErrInvalidCursor
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"(%s, id) %s (%s::%s, %s::integer)",
+			field, comparison, arg(cursor.Value), cast, arg(cursor.ID),
+		))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM procedures
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT %s
+	`, procedureColumns, strings.Join(conditions, " AND "), field, direction, direction, arg(filter.Limit+1))
+	procedures := make([]models.Procedure, 0, filter.Limit+1)
+	if err := r.db.Select(&procedures, query, args...); err != nil {
+		return nil, err
+	}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
+
+	page := &models.ProcedurePage{Items: procedures, Total: total, Facets: facets}
+	if len(procedures) > filter.Limit {
+		page.Items = procedures[:filter.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeProcedureCursor(procedureCursor{
+			Sort:  filter.Sort,
+			Value: procedureSortValue(field, last),
+			ID:    last.ID,
+		})
+	}
+	return page, nil
+}
+
+func procedureSortValue(field string, procedure models.Procedure) string {
+	switch field {
+	case "title":
+		return procedure.Title
+	case "updated_at":
+		return procedure.UpdatedAt.UTC().Format("2006-01-02T15:04:05.999999")
+	default:
+		return strconv.Itoa(procedure.SortOrder)
+	}
+}
+
+func encodeProcedureCursor(cursor procedureCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeProcedureCursor(raw string) (procedureCursor, error) {
+	var cursor procedureCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+// GetAllAnyStatus возвращает процедуры во всех статусах для предпросмотра
+func (r *ProcedureRepository) GetAllAnyStatus() ([]models.Procedure, error) {
+	var procedures []models.Procedure
+	query := `
+		SELECT ` + procedureColumns + `
+		FROM procedures
+		WHERE deleted_at IS NULL
+		ORDER BY sort_order ASC, id ASC
+	`
+	err := r.db.Select(&procedures, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
+	return procedures, nil
+}
+
+// GetByID возвращает процедуру в любом статусе, кроме удалённых в корзину
 func (r *ProcedureRepository) GetByID(id int) (*models.Procedure, error) {
 	var procedure models.Procedure
 	query := `
-		SELECT id, title, type, content, sort_order, is_expanded, created_at, updated_at
+		SELECT ` + procedureColumns + `
 		FROM procedures
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	err := r.db.Get(&procedure, query, id)
 	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
 		return nil, err
 	}
-	return &procedure, nil
+	procedures := []models.Procedure{procedure}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
+	return &procedures[0], nil
 }
 
-func (r *ProcedureRepository) GetByType(procedureType string) ([]models.Procedure, error) {
+// GetByType возвращает опубликованные процедуры указанного типа, отобранные по тегам
+func (r *ProcedureRepository) GetByType(procedureType string, tags models.TagFilter) ([]models.Procedure, error) {
 	var procedures []models.Procedure
+	args := []interface{}{procedureType}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions := []string{"type = $1", "status = 'published'", "deleted_at IS NULL"}
+	if condition := tagCondition(tags, arg); condition != "" {
+		conditions = append(conditions, condition)
+	}
 	query := `
-		SELECT id, title, type, content, sort_order, is_expanded, created_at, updated_at
+		SELECT ` + procedureColumns + `
 		FROM procedures
-		WHERE type = $1
-		ORDER BY sort_order ASC
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY sort_order ASC, id ASC
 	`
-	err := r.db.Select(&procedures, query, procedureType)
+	err := r.db.Select(&procedures, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
 	return procedures, nil
 }
 
+// tagCondition строит условие фильтра по тегам: в режиме any процедура должна
+// иметь хотя бы один из тегов, иначе все. Для пустого фильтра возвращает "".
+func tagCondition(filter models.TagFilter, arg func(value interface{}) string) string {
+	slugs := uniqueStrings(filter.Slugs)
+	if len(slugs) == 0 {
+		return ""
+	}
+	if filter.Mode == models.TagMatchAny {
+		return "EXISTS (SELECT 1 FROM procedure_tags pt WHERE pt.procedure_id = procedures.id AND pt.tag_slug = ANY(" +
+			arg(pq.Array(slugs)) + "))"
+	}
+	return "(SELECT COUNT(*) FROM procedure_tags pt WHERE pt.procedure_id = procedures.id AND pt.tag_slug = ANY(" +
+		arg(pq.Array(slugs)) + ")) = " + arg(len(slugs))
+}
+
+// Create добавляет процедуру; с ParentID она становится подпроцедурой,
+// если родитель существует и глубина дерева не превысит maxDepth.
 func (r *ProcedureRepository) Create(procedure *models.Procedure) error {
 	query := `
-		INSERT INTO procedures (title, type, content, sort_order, is_expanded)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at
+		INSERT INTO procedures (slug, parent_id, title, type, content, sort_order, is_expanded, status, publish_at, unpublish_at)
+		VALUES (NULLIF($1, ''), $10, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, slug, version, created_at, updated_at
 	`
-	return r.db.QueryRow(
-		query,
-		procedure.Title,
-		procedure.Type,
-		procedure.Content,
-		procedure.SortOrder,
-		procedure.IsExpanded,
-	).Scan(&procedure.ID, &procedure.CreatedAt, &procedure.UpdatedAt)
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := checkAttachments(tx, procedure.Content); err != nil {
+			return err
+		}
+		if procedure.ParentID != nil {
+			if err := lockHierarchy(tx); err != nil {
+				return err
+			}
+			if err := r.checkParent(tx, 0, *procedure.ParentID); err != nil {
+				return err
+			}
+		}
+		err := tx.QueryRow(
+			query,
+			procedure.Slug,
+			procedure.Title,
+			procedure.Type,
+			procedure.Content,
+			procedure.SortOrder,
+			procedure.IsExpanded,
+			procedure.Status,
+			procedure.PublishAt,
+			procedure.UnpublishAt,
+			procedure.ParentID,
+		).Scan(&procedure.ID, &procedure.Slug, &procedure.Version, &procedure.CreatedAt, &procedure.UpdatedAt)
+		if err != nil {
+			return slugError(err)
+		}
+		if err := setProcedureTags(tx, procedure, procedure.Tags); err != nil {
+			return err
+		}
+		return insertRevision(tx, models.RevisionCreate, procedure)
+	})
 }
 
+// Update сохраняет редактируемые поля процедуры. Статус меняется только через UpdateStatus,
+// родитель — через Move, пустой slug оставляет прежний, Tags == nil — прежние теги.
+// Если procedure.Version не нулевая, запись выполняется только при совпадении версии,
+// иначе возвращается errors.ErrPreconditionFailed.
 func (r *ProcedureRepository) Update(procedure *models.Procedure) error {
 	query := `
 		UPDATE procedures
-		SET title = $1, type = $2, content = $3, sort_order = $4, is_expanded = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
-		RETURNING updated_at
-	`
-	return r.db.QueryRow(
-		query,
-		procedure.Title,
-		procedure.Type,
-		procedure.Content,
-		procedure.SortOrder,
-		procedure.IsExpanded,
-		procedure.ID,
-	).Scan(&procedure.UpdatedAt)
+		SET title = $1, type = $2, content = $3, sort_order = $4, is_expanded = $5,
+			publish_at = $6, unpublish_at = $7, slug = COALESCE(NULLIF($10, ''), slug),
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8 AND deleted_at IS NULL AND ($9::integer = 0 OR version = $9::integer)
+		RETURNING slug, parent_id, status, version, created_at, updated_at
+	`
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := checkAttachments(tx, procedure.Content); err != nil {
+			return err
+		}
+		err := tx.QueryRow(
+			query,
+			procedure.Title,
+			procedure.Type,
+			procedure.Content,
+			procedure.SortOrder,
+			procedure.IsExpanded,
+			procedure.PublishAt,
+			procedure.UnpublishAt,
+			procedure.ID,
+			procedure.Version,
+			procedure.Slug,
+		).Scan(&procedure.Slug, &procedure.ParentID, &procedure.Status, &procedure.Version, &procedure.CreatedAt, &procedure.UpdatedAt)
+		if err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				return missingOrStale(tx, procedure.ID)
+			}
+			return slugError(err)
+		}
+		if procedure.Tags != nil {
+			if err := setProcedureTags(tx, procedure, procedure.Tags); err != nil {
+				return err
+			}
+		}
+		return insertRevision(tx, models.RevisionUpdate, procedure)
+	})
+}
+
+// Patch записывает только перечисленные колонки и возвращает в procedure
+// актуальное состояние строки. Колонка "tags" заменяет теги процедуры.
+// Версия проверяется так же, как в Update.
+func (r *ProcedureRepository) Patch(procedure *models.Procedure, columns []string) error {
+	assignments := make([]string, 0, len(columns)+1)
+	args := make([]interface{}, 0, len(columns)+1)
+	tags := procedure.Tags
+	patchTags := false
+	for _, column := range columns {
+		if column == "tags" {
+			patchTags = true
+			continue
+		}
+		value, ok := procedurePatchColumns[column]
+		if !ok {
+			return fmt.Errorf("column %q cannot be patched", column)
+		}
+		args = append(args, value(procedure))
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	assignments = append(assignments, "version = version + 1", "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, procedure.ID, procedure.Version)
+	query := fmt.Sprintf(`
+		UPDATE procedures
+		SET %s
+		WHERE id = $%d AND deleted_at IS NULL AND ($%d::integer = 0 OR version = $%d::integer)
+		RETURNING %s
+	`, strings.Join(assignments, ", "), len(args)-1, len(args), len(args), procedureColumns)
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		if slices.Contains(columns, "content") {
+			if err := checkAttachments(tx, procedure.Content); err != nil {
+				return err
+			}
+		}
+		procedure.Tags = nil
+		if err := tx.Get(procedure, query, args...); err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				return missingOrStale(tx, procedure.ID)
+			}
+			return slugError(err)
+		}
+		if patchTags {
+			if err := setProcedureTags(tx, procedure, tags); err != nil {
+				return err
+			}
+		}
+		return insertRevision(tx, models.RevisionUpdate, procedure)
+	})
+}
+
+// UpdateStatus переводит процедуру из статуса from в to.
+// Если статус успел измениться, возвращается errors.ErrConflict.
+func (r *ProcedureRepository) UpdateStatus(id int, from, to models.ProcedureStatus) (*models.Procedure, error) {
+	var procedure models.Procedure
+	query := `
+		UPDATE procedures
+		SET status = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3 AND deleted_at IS NULL
+		RETURNING ` + procedureColumns
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := tx.Get(&procedure, query, to, id, from); err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				return errors.ErrConflict
+			}
+			return err
+		}
+		return insertRevision(tx, models.RevisionUpdate, &procedure)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &procedure, nil
+}
+
+// ApplySchedule публикует процедуры на ревью с наступившим publish_at
+// и архивирует опубликованные с наступившим unpublish_at.
+// Возвращает количество изменённых процедур.
+func (r *ProcedureRepository) ApplySchedule(now time.Time) (int, error) {
+	publishQuery := `
+		UPDATE procedures
+		SET status = 'published', version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'in_review' AND deleted_at IS NULL AND publish_at IS NOT NULL AND publish_at <= $1
+			AND (unpublish_at IS NULL OR unpublish_at > $1)
+		RETURNING ` + procedureColumns
+	unpublishQuery := `
+		UPDATE procedures
+		SET status = 'archived', version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'published' AND deleted_at IS NULL AND unpublish_at IS NOT NULL AND unpublish_at <= $1
+		RETURNING ` + procedureColumns
+	changed := 0
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		for _, query := range []string{publishQuery, unpublishQuery} {
+			var procedures []models.Procedure
+			if err := tx.Select(&procedures, query, now); err != nil {
+				return err
+			}
+			for i := range procedures {
+				if err := insertRevision(tx, models.RevisionUpdate, &procedures[i]); err != nil {
+					return err
+				}
+			}
+			changed += len(procedures)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// GetOrder возвращает текущий порядок процедур во всех статусах.
+// Пустой procedureType означает все процедуры.
+func (r *ProcedureRepository) GetOrder(procedureType string) ([]models.ProcedureOrder, error) {
+	var order []models.ProcedureOrder
+	query := `
+		SELECT id, sort_order
+		FROM procedures
+		WHERE deleted_at IS NULL AND ($1 = '' OR type = $1)
+		ORDER BY sort_order ASC, id ASC
+	`
+	err := r.db.Select(&order, query, procedureType)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ReorderMismatchError — список ID не совпадает с процедурами в области сортировки
+type ReorderMismatchError struct {
+	Unknown   []int
+	Missing   []int
+	Duplicate []int
+}
+
+func (e *ReorderMismatchError) Error() string {
+	return fmt.Sprintf("reorder ids mismatch: unknown %v, missing %v, duplicate %v", e.Unknown, e.Missing, e.Duplicate)
 }
 
-func (r *ProcedureRepository) Delete(id int) error {
-	query := `DELETE FROM procedures WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+// Reorder атомарно переписывает sort_order процедур в порядке ids.
+// ids должен содержать ровно все процедуры области, иначе возвращается
+// *ReorderMismatchError. Для всей таблицы позиции нумеруются с 1,
+// внутри типа процедуры переставляются по уже занятым ими позициям, поэтому
+// позиции процедур разных типов могут совпадать: списки добирают порядок по id.
+func (r *ProcedureRepository) Reorder(ids []int, procedureType string) ([]models.ProcedureOrder, error) {
+	lockQuery := `
+		SELECT id, sort_order
+		FROM procedures
+		WHERE deleted_at IS NULL AND ($1 = '' OR type = $1)
+		ORDER BY sort_order ASC, id ASC
+		FOR UPDATE
+	`
+	updateQuery := `
+		UPDATE procedures p
+		SET sort_order = v.sort_order, version = p.version + 1, updated_at = CURRENT_TIMESTAMP
+		FROM unnest($1::integer[], $2::integer[]) AS v(id, sort_order)
+		WHERE p.id = v.id AND p.deleted_at IS NULL AND p.sort_order <> v.sort_order
+		RETURNING ` + prefixColumns("p", procedureColumns)
+	var result []models.ProcedureOrder
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		var current []models.ProcedureOrder
+		if err := tx.Select(&current, lockQuery, procedureType); err != nil {
+			return err
+		}
+		if mismatch := compareOrder(current, ids); mismatch != nil {
+			return mismatch
+		}
+
+		slots := make([]int, len(current))
+		for i, row := range current {
+			switch {
+			case procedureType == "":
+				slots[i] = i + 1
+			case i > 0 && row.SortOrder <= slots[i-1]:
+				slots[i] = slots[i-1] + 1
+			default:
+				slots[i] = row.SortOrder
+			}
+		}
+
+		var changed []models.Procedure
+		if err := tx.Select(&changed, updateQuery, pq.Array(ids), pq.Array(slots)); err != nil {
+			return err
+		}
+		for i := range changed {
+			if err := insertRevision(tx, models.RevisionUpdate, &changed[i]); err != nil {
+				return err
+			}
+		}
+
+		result = make([]models.ProcedureOrder, len(ids))
+		for i, id := range ids {
+			result[i] = models.ProcedureOrder{ID: id, SortOrder: slots[i]}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func compareOrder(current []models.ProcedureOrder, ids []int) *ReorderMismatchError {
+	known := make(map[int]bool, len(current))
+	for _, row := range current {
+		known[row.ID] = true
+	}
+	seen := make(map[int]bool, len(ids))
+	mismatch := &ReorderMismatchError{}
+	for _, id := range ids {
+		switch {
+		case !known[id]:
+			mismatch.Unknown = append(mismatch.Unknown, id)
+		case seen[id]:
+			mismatch.Duplicate = append(mismatch.Duplicate, id)
+		}
+		seen[id] = true
+	}
+	for _, row := range current {
+		if !seen[row.ID] {
+			mismatch.Missing = append(mismatch.Missing, row.ID)
+		}
+	}
+	if len(mismatch.Unknown) == 0 && len(mismatch.Missing) == 0 && len(mismatch.Duplicate) == 0 {
+		return nil
+	}
+	sort.Ints(mismatch.Missing)
+	return mismatch
+}
+
+// prefixColumns добавляет алиас таблицы к каждой колонке списка
+func prefixColumns(alias, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, column := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Search ищет опубликованные процедуры по исходному тексту (словарь russian)
+// и по переводам на locale со словарём этой локали. При совпадении в переводе
+// процедура возвращается на языке перевода.
+func (r *ProcedureRepository) Search(query, locale string, limit int) ([]models.SearchResult, error) {
+	var rows []struct {
+		models.Procedure
+		ResultLocale string  `db:"result_locale"`
+		Rank         float64 `db:"rank"`
+		Snippet      string  `db:"snippet"`
+	}
+	sqlQuery := `
+		SELECT p.id, p.slug, p.parent_id, p.type, p.sort_order, p.is_expanded, p.status, p.version, p.publish_at, p.unpublish_at,
+			p.created_at, p.updated_at, p.deleted_at,
+			CASE WHEN translated THEN t.title ELSE p.title END AS title,
+			CASE WHEN translated THEN t.content ELSE p.content END AS content,
+			CASE WHEN translated THEN t.locale ELSE '' END AS result_locale,
+			GREATEST(
+				ts_rank(p.search_vector, base_query),
+				COALESCE(ts_rank(t.search_vector, locale_query), 0)
+			) AS rank,
+			CASE WHEN translated
+				THEN ts_headline(locale_search_config(t.locale), procedure_search_document(t.title, t.content),
+					locale_query, $4)
+				ELSE ts_headline('pg_catalog.russian', procedure_search_document(p.title, p.content),
+					base_query, $4)
+			END AS snippet
+		FROM procedures p
+		LEFT JOIN procedure_translations t ON t.procedure_id = p.id AND t.locale = $2
+		CROSS JOIN websearch_to_tsquery('pg_catalog.russian', $1) AS base_query
+		CROSS JOIN websearch_to_tsquery(locale_search_config($2), $1) AS locale_query
+		CROSS JOIN LATERAL (SELECT COALESCE(t.search_vector @@ locale_query, false) AS translated) AS m
+		WHERE p.status = 'published' AND p.deleted_at IS NULL
+			AND (p.search_vector @@ base_query OR translated)
+		ORDER BY rank DESC, p.sort_order ASC, p.id ASC
+		LIMIT $3
+	`
+	headlineOptions := "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=\" … \""
+	if err := r.db.Select(&rows, sqlQuery, query, locale, limit, headlineOptions); err != nil {
+		return nil, err
+	}
+	procedures := make([]models.Procedure, len(rows))
+	for i, row := range rows {
+		procedures[i] = row.Procedure
+		procedures[i].Locale = row.ResultLocale
+	}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
+	results := make([]models.SearchResult, 0, len(rows))
+	for i, row := range rows {
+		results = append(results, models.SearchResult{
+			Procedure: procedures[i],
+			Rank:      row.Rank,
+			Snippet:   row.Snippet,
+		})
+	}
+	return results, nil
+}
+
+// Delete перемещает процедуру в корзину. Ненулевая version проверяется так же, как в Update.
+// children определяет судьбу подпроцедур: при ChildrenReject удаление с ними
+// отклоняется с ErrHasChildren, ChildrenCascade отправляет в корзину всё поддерево
+// с тем же deleted_at, ChildrenReparent переносит их к родителю удаляемой процедуры.
+func (r *ProcedureRepository) Delete(id, version int, children models.ChildrenOnDelete) error {
+	query := `
+		UPDATE procedures
+		SET deleted_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::integer = 0 OR version = $2::integer)
+		RETURNING ` + procedureColumns
+	cascadeQuery := `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM procedures WHERE parent_id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT p.id FROM procedures p JOIN subtree s ON p.parent_id = s.id WHERE p.deleted_at IS NULL
+		)
+		UPDATE procedures
+		SET deleted_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (SELECT id FROM subtree)
+		RETURNING ` + procedureColumns
+	reparentQuery := `
+		UPDATE procedures
+		SET parent_id = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE parent_id = $1 AND deleted_at IS NULL
+		RETURNING ` + procedureColumns
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockHierarchy(tx); err != nil {
+			return err
+		}
+		var procedure models.Procedure
+		if err := tx.Get(&procedure, query, id, version); err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				return missingOrStale(tx, id)
+			}
+			return err
+		}
+		if err := insertRevision(tx, models.RevisionDelete, &procedure); err != nil {
+			return err
+		}
+
+		var affected []models.Procedure
+		action := models.RevisionDelete
+		switch children {
+		case models.ChildrenCascade:
+			if err := tx.Select(&affected, cascadeQuery, id); err != nil {
+				return err
+			}
+		case models.ChildrenReparent:
+			action = models.RevisionUpdate
+			if err := tx.Select(&affected, reparentQuery, id, procedure.ParentID); err != nil {
+				return err
+			}
+		default:
+			var hasChildren bool
+			query := `SELECT EXISTS(SELECT 1 FROM procedures WHERE parent_id = $1 AND deleted_at IS NULL)`
+			if err := tx.Get(&hasChildren, query, id); err != nil {
+				return err
+			}
+			if hasChildren {
+				return ErrHasChildren
+			}
+		}
+		for i := range affected {
+			if err := insertRevision(tx, action, &affected[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Move переносит процедуру вместе с поддеревом под parentID (nil — на верхний уровень)
+// и ставит её после подпроцедур нового родителя. Родитель должен существовать и не лежать
+// в переносимом поддереве, иначе возвращается ErrParentNotFound или ErrParentCycle;
+// превышение глубины — *DepthLimitError. Версия проверяется так же, как в Update.
+func (r *ProcedureRepository) Move(id int, parentID *int, version int) (*models.Procedure, error) {
+	query := `
+		UPDATE procedures
+		SET parent_id = $2::integer,
+			sort_order = GREATEST(sort_order, (
+				SELECT COALESCE(MAX(s.sort_order) + 1, 0)
+				FROM procedures s
+				WHERE s.parent_id IS NOT DISTINCT FROM $2::integer AND s.id <> $1 AND s.deleted_at IS NULL
+			)),
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL AND ($3::integer = 0 OR version = $3::integer)
+		RETURNING ` + procedureColumns
+	var procedure models.Procedure
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockHierarchy(tx); err != nil {
+			return err
+		}
+		if parentID != nil {
+			if err := r.checkParent(tx, id, *parentID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Get(&procedure, query, id, parentID, version); err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				return missingOrStale(tx, id)
+			}
+			return err
+		}
+		return insertRevision(tx, models.RevisionUpdate, &procedure)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &procedure, nil
+}
+
+// DepthLimitError — после изменения дерево процедур стало бы глубже MaxDepth
+type DepthLimitError struct {
+	Depth    int
+	MaxDepth int
+}
+
+func (e *DepthLimitError) Error() string {
+	return fmt.Sprintf("procedure tree depth %d exceeds the limit of %d", e.Depth, e.MaxDepth)
+}
+
+// checkParent проверяет, что процедуру id вместе с поддеревом можно поместить под
+// parentID; id == 0 означает новую процедуру без поддерева. Глубина поддерева
+// считается с учётом подпроцедур в корзине, чтобы их восстановление не нарушило лимит.
+// Вызывается под lockHierarchy, иначе параллельные переносы могут замкнуть цикл.
+func (r *ProcedureRepository) checkParent(tx *sqlx.Tx, id, parentID int) error {
+	// Обход ограничен maxDepth+1 уровнями: более длинная цепочка и так нарушает лимит
+	ancestorsQuery := `
+		WITH RECURSIVE ancestors(id, parent_id, level) AS (
+			SELECT id, parent_id, 1 FROM procedures WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT p.id, p.parent_id, a.level + 1
+			FROM procedures p JOIN ancestors a ON p.id = a.parent_id
+			WHERE a.level <= $2
+		)
+		SELECT id FROM ancestors
+	`
+	heightQuery := `
+		WITH RECURSIVE subtree(id, level) AS (
+			SELECT id, 1 FROM procedures WHERE id = $1
+			UNION ALL
+			SELECT p.id, s.level + 1
+			FROM procedures p JOIN subtree s ON p.parent_id = s.id
+			WHERE s.level <= $2
+		)
+		SELECT COALESCE(MAX(level), 1) FROM subtree
+	`
+	var ancestors []int
+	if err := tx.Select(&ancestors, ancestorsQuery, parentID, r.maxDepth); err != nil {
+		return err
+	}
+	if len(ancestors) == 0 {
+		return ErrParentNotFound
+	}
+	if id != 0 && slices.Contains(ancestors, id) {
+		return ErrParentCycle
+	}
+	height := 1
+	if id != 0 {
+		if err := tx.Get(&height, heightQuery, id, r.maxDepth); err != nil {
+			return err
+		}
+	}
+	if depth := len(ancestors) + height; depth > r.maxDepth {
+		return &DepthLimitError{Depth: depth, MaxDepth: r.maxDepth}
+	}
+	return nil
+}
+
+// lockHierarchy упорядочивает транзакции, меняющие дерево процедур
+func lockHierarchy(tx *sqlx.Tx) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, hierarchyLockKey)
+	return err
+}
+
+// GetTrash возвращает процедуры в корзине, недавно удалённые первыми
+func (r *ProcedureRepository) GetTrash() ([]models.Procedure, error) {
+	var procedures []models.Procedure
+	query := `
+		SELECT ` + procedureColumns + `
+		FROM procedures
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id ASC
+	`
+	err := r.db.Select(&procedures, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTags(r.db, procedures); err != nil {
+		return nil, err
+	}
+	return procedures, nil
+}
+
+// Restore возвращает процедуру из корзины с прежним статусом вместе с подпроцедурами,
+// удалёнными каскадом одновременно с ней. Если процедуры нет в корзине, возвращается
+// errors.ErrNotFound, если в корзине её родитель — ErrParentDeleted.
+func (r *ProcedureRepository) Restore(id int) (*models.Procedure, error) {
+	var procedure models.Procedure
+	parentQuery := `
+		SELECT parent.deleted_at IS NOT NULL
+		FROM procedures p
+		JOIN procedures parent ON parent.id = p.parent_id
+		WHERE p.id = $1 AND p.deleted_at IS NOT NULL
+	`
+	query := `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM procedures WHERE id = $1 AND deleted_at IS NOT NULL
+			UNION
+			SELECT p.id
+			FROM procedures p JOIN subtree s ON p.parent_id = s.id
+			WHERE p.deleted_at = (SELECT deleted_at FROM procedures WHERE id = $1)
+		)
+		UPDATE procedures
+		SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (SELECT id FROM subtree)
+		RETURNING ` + procedureColumns
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockHierarchy(tx); err != nil {
+			return err
+		}
+		var parentDeleted bool
+		if err := tx.Get(&parentDeleted, parentQuery, id); err != nil && !stderrors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if parentDeleted {
+			return ErrParentDeleted
+		}
+		var restored []models.Procedure
+		if err := tx.Select(&restored, query, id); err != nil {
+			return err
+		}
+		if len(restored) == 0 {
+			return errors.ErrNotFound
+		}
+		for i := range restored {
+			if err := insertRevision(tx, models.RevisionRestore, &restored[i]); err != nil {
+				return err
+			}
+			if restored[i].ID == id {
+				procedure = restored[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &procedure, nil
+}
+
+// Purge окончательно удаляет процедуру из корзины вместе с переводами и ревизиями.
+// Если процедуры нет в корзине, возвращается errors.ErrNotFound.
+func (r *ProcedureRepository) Purge(id int) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		var ids []int
+		err := tx.Select(&ids, `DELETE FROM procedures WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id`, id)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return errors.ErrNotFound
+		}
+		return deleteRevisions(tx, ids)
+	})
+}
+
+// PurgeDeletedBefore окончательно удаляет процедуры, попавшие в корзину раньше cutoff.
+// Возвращает количество удалённых процедур.
+func (r *ProcedureRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	var ids []int
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		err := tx.Select(&ids, `DELETE FROM procedures WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING id`, cutoff)
+		if err != nil {
+			return err
+		}
+		return deleteRevisions(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// ImportRowError — ошибка конкретной строки импорта
+type ImportRowError struct {
+	Index int
+	Err   error
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e *ImportRowError) Unwrap() error {
+	return e.Err
+}
+
+// Import создаёт или обновляет процедуры по slug в одной транзакции: при ошибке
+// не сохраняется ни одна строка. Процедура из корзины при импорте восстанавливается,
+// совпадающая с файлом остаётся нетронутой. Родитель задаётся ParentSlug и ищется
+// сначала среди строк файла, затем среди процедур вне корзины; пустой ParentSlug
+// делает процедуру процедурой верхнего уровня. Ошибки дерева возвращаются как
+// *ImportRowError. При dryRun транзакция откатывается.
+func (r *ProcedureRepository) Import(procedures []models.Procedure, dryRun bool) ([]models.ImportRow, error) {
+	upsertQuery := `
+		INSERT INTO procedures (slug, title, type, content, sort_order, is_expanded, status, publish_at, unpublish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (slug) DO UPDATE
+		SET title = EXCLUDED.title, type = EXCLUDED.type, content = EXCLUDED.content,
+			sort_order = EXCLUDED.sort_order, is_expanded = EXCLUDED.is_expanded, status = EXCLUDED.status,
+			publish_at = EXCLUDED.publish_at, unpublish_at = EXCLUDED.unpublish_at, deleted_at = NULL,
+			version = procedures.version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE procedures.deleted_at IS NOT NULL
+			OR (procedures.title, procedures.type, procedures.content, procedures.sort_order,
+				procedures.is_expanded, procedures.status, procedures.publish_at, procedures.unpublish_at)
+			IS DISTINCT FROM
+				(EXCLUDED.title, EXCLUDED.type, EXCLUDED.content, EXCLUDED.sort_order,
+				EXCLUDED.is_expanded, EXCLUDED.status, EXCLUDED.publish_at, EXCLUDED.unpublish_at)
+		RETURNING ` + procedureColumns + `, xmax = 0 AS inserted`
+	parentQuery := `
+		UPDATE procedures
+		SET parent_id = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND parent_id IS DISTINCT FROM $2
+		RETURNING ` + procedureColumns
+	rows := make([]models.ImportRow, 0, len(procedures))
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		// Ревизии пишутся после расстановки родителей, чтобы строка получила одну ревизию
+		changed := make([]*models.Procedure, len(procedures))
+		ids := make(map[string]int, len(procedures))
+		for i, procedure := range procedures {
+			var saved struct {
+				models.Procedure
+				Inserted bool `db:"inserted"`
+			}
+			row := models.ImportRow{Index: i, Slug: procedure.Slug}
+			if err := checkAttachments(tx, procedure.Content); err != nil {
+				return fmt.Errorf("row %d (%s): %w", i, procedure.Slug, err)
+			}
+			err := tx.Get(
+				&saved,
+				upsertQuery,
+				procedure.Slug,
+				procedure.Title,
+				procedure.Type,
+				procedure.Content,
+				procedure.SortOrder,
+				procedure.IsExpanded,
+				procedure.Status,
+				procedure.PublishAt,
+				procedure.UnpublishAt,
+			)
+			switch {
+			case stderrors.Is(err, sql.ErrNoRows):
+				if err := tx.Get(&row.ID, `SELECT id FROM procedures WHERE slug = $1`, procedure.Slug); err != nil {
+					return err
+				}
+				row.Action = models.ImportUnchanged
+			case err != nil:
+				return fmt.Errorf("row %d (%s): %w", i, procedure.Slug, err)
+			default:
+				row.ID = saved.ID
+				row.Action = models.ImportUpdated
+				if saved.Inserted {
+					row.Action = models.ImportCreated
+				}
+				changed[i] = &saved.Procedure
+			}
+			ids[procedure.Slug] = row.ID
+			rows = append(rows, row)
+		}
+
+		if err := lockHierarchy(tx); err != nil {
+			return err
+		}
+		for i, procedure := range procedures {
+			var parentID *int
+			if procedure.ParentSlug != "" {
+				id, ok := ids[procedure.ParentSlug]
+				if !ok {
+					err := tx.Get(&id, `SELECT id FROM procedures WHERE slug = $1 AND deleted_at IS NULL`, procedure.ParentSlug)
+					if stderrors.Is(err, sql.ErrNoRows) {
+						return &ImportRowError{Index: i, Err: ErrParentNotFound}
+					}
+					if err != nil {
+						return err
+					}
+				}
+				if err := r.checkParent(tx, rows[i].ID, id); err != nil {
+					return &ImportRowError{Index: i, Err: err}
+				}
+				parentID = &id
+			}
+			var moved models.Procedure
+			err := tx.Get(&moved, parentQuery, rows[i].ID, parentID)
+			if stderrors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			changed[i] = &moved
+			if rows[i].Action == models.ImportUnchanged {
+				rows[i].Action = models.ImportUpdated
+			}
+		}
+
+		for i, procedure := range changed {
+			if procedure == nil {
+				continue
+			}
+			action := models.RevisionUpdate
+			if rows[i].Action == models.ImportCreated {
+				action = models.RevisionCreate
+			}
+			if err := insertRevision(tx, action, procedure); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Seed применяет фикстуры в одной транзакции. Процедура создаётся, если её ещё
+// не загружали, и обновляется, только если фикстура изменилась, а версия процедуры
+// осталась той, что записана в procedure_seeds при прошлой загрузке. Процедуры,
+// которые редакторы изменили, удалили или создали сами, пропускаются.
+func (r *ProcedureRepository) Seed(fixtures []models.ProcedureFixture, dryRun bool) ([]models.SeedRow, error) {
+	insertQuery := `
+		INSERT INTO procedures (slug, title, type, content, sort_order, is_expanded, status, publish_at, unpublish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + procedureColumns
+	updateQuery := `
+		UPDATE procedures
+		SET title = $2, type = $3, content = $4, sort_order = $5, is_expanded = $6, status = $7,
+			publish_at = $8, unpublish_at = $9, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE slug = $1
+		RETURNING ` + procedureColumns
+	recordQuery := `
+		INSERT INTO procedure_seeds (slug, checksum, version)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (slug) DO UPDATE
+		SET checksum = EXCLUDED.checksum, version = EXCLUDED.version, seeded_at = CURRENT_TIMESTAMP
+	`
+	rows := make([]models.SeedRow, 0, len(fixtures))
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		// Несколько экземпляров могут запуститься одновременно: seed выполняется по очереди
+		if _, err := tx.Exec(`LOCK TABLE procedure_seeds IN EXCLUSIVE MODE`); err != nil {
+			return err
+		}
+		for _, fixture := range fixtures {
+			procedure := fixture.Procedure
+			row := models.SeedRow{File: fixture.File, Slug: procedure.Slug}
+
+			var seeded struct {
+				Checksum string `db:"checksum"`
+				Version  int    `db:"version"`
+			}
+			err := tx.Get(&seeded, `SELECT checksum, version FROM procedure_seeds WHERE slug = $1`, procedure.Slug)
+			wasSeeded := err == nil
+			if err != nil && !stderrors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			var current struct {
+				ID        int        `db:"id"`
+				Version   int        `db:"version"`
+				DeletedAt *time.Time `db:"deleted_at"`
+			}
+			err = tx.Get(&current, `SELECT id, version, deleted_at FROM procedures WHERE slug = $1 FOR UPDATE`, procedure.Slug)
+			exists := err == nil
+			if err != nil && !stderrors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			row.ID = current.ID
+
+			var query string
+			var action models.RevisionAction
+			switch {
+			case !wasSeeded && !exists:
+				query, action, row.Action = insertQuery, models.RevisionCreate, models.ImportCreated
+			case !wasSeeded || !exists || current.DeletedAt != nil || current.Version != seeded.Version:
+				row.Action = models.ImportSkipped
+			case seeded.Checksum == fixture.Checksum:
+				row.Action = models.ImportUnchanged
+			default:
+				query, action, row.Action = updateQuery, models.RevisionUpdate, models.ImportUpdated
+			}
+			if query != "" {
+				var saved models.Procedure
+				err := tx.Get(
+					&saved,
+					query,
+					procedure.Slug,
+					procedure.Title,
+					procedure.Type,
+					procedure.Content,
+					procedure.SortOrder,
+					procedure.IsExpanded,
+					procedure.Status,
+					procedure.PublishAt,
+					procedure.UnpublishAt,
+				)
+				if err != nil {
+					return fmt.Errorf("fixture %s (%s): %w", fixture.File, procedure.Slug, err)
+				}
+				if err := insertRevision(tx, action, &saved); err != nil {
+					return err
+				}
+				if _, err := tx.Exec(recordQuery, saved.Slug, fixture.Checksum, saved.Version); err != nil {
+					return err
+				}
+				row.ID = saved.ID
+			}
+			rows = append(rows, row)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RestoreRevision возвращает процедуру к состоянию указанной ревизии.
+// Статус и родитель существующей процедуры не меняются; процедура из корзины или
+// окончательно удалённая возвращается с прежним ID в статусе draft, а если её
+// родителя больше нет среди живых процедур — на верхний уровень.
+// Из тегов ревизии восстанавливаются те, что ещё есть в справочнике. Если содержимое
+// ревизии ссылается на удалённые с тех пор вложения, возвращается *UnknownAttachmentsError.
+func (r *ProcedureRepository) RestoreRevision(procedureID, revision int) (*models.Procedure, error) {
+	var restored models.Procedure
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		var snapshot models.ProcedureSnapshot
+		err := tx.Get(
+			&snapshot,
+			`SELECT snapshot FROM procedure_revisions WHERE procedure_id = $1 AND revision = $2`,
+			procedureID,
+			revision,
+		)
+		if err != nil {
+			if stderrors.Is(err, sql.ErrNoRows) {
+				return errors.ErrNotFound
+			}
+			return err
+		}
+		if err := checkAttachments(tx, snapshot.Content); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO procedures (id, slug, title, type, content, sort_order, is_expanded, status, publish_at, unpublish_at)
+			VALUES (
+				$1,
+				(SELECT NULLIF($9, '') WHERE NOT EXISTS (SELECT 1 FROM procedures WHERE slug = $9)),
+				$2, $3, $4, $5, $6, 'draft', $7, $8
+			)
+			ON CONFLICT (id) DO UPDATE
+			SET title = EXCLUDED.title, type = EXCLUDED.type, content = EXCLUDED.content,
+				sort_order = EXCLUDED.sort_order, is_expanded = EXCLUDED.is_expanded,
+				publish_at = EXCLUDED.publish_at, unpublish_at = EXCLUDED.unpublish_at,
+				status = CASE WHEN procedures.deleted_at IS NULL THEN procedures.status ELSE 'draft' END,
+				parent_id = CASE WHEN procedures.deleted_at IS NULL THEN procedures.parent_id ELSE (
+					SELECT parent.id FROM procedures parent
+					WHERE parent.id = procedures.parent_id AND parent.deleted_at IS NULL
+				) END,
+				deleted_at = NULL, version = procedures.version + 1, updated_at = CURRENT_TIMESTAMP
+			RETURNING ` + procedureColumns
+		err = tx.Get(
+			&restored,
+			query,
+			procedureID,
+			snapshot.Title,
+			snapshot.Type,
+			snapshot.Content,
+			snapshot.SortOrder,
+			snapshot.IsExpanded,
+			snapshot.PublishAt,
+			snapshot.UnpublishAt,
+			snapshot.Slug,
+		)
+		if err != nil {
+			return err
+		}
+		if snapshot.Tags != nil {
+			known, _, err := splitKnownTags(tx, snapshot.Tags)
+			if err != nil {
+				return err
+			}
+			if err := setProcedureTags(tx, &restored, known); err != nil {
+				return err
+			}
+		}
+		return insertRevision(tx, models.RevisionRestore, &restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// missingOrStale объясняет, почему условное изменение не затронуло строку:
+// процедуры нет (или она в корзине) или её версия уже другая
+func missingOrStale(tx *sqlx.Tx, id int) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM procedures WHERE id = $1 AND deleted_at IS NULL)`
+	if err := tx.Get(&exists, query, id); err != nil {
+		return err
+	}
+	if exists {
+		return errors.ErrPreconditionFailed
+	}
+	return errors.ErrNotFound
+}
+
+// slugError заменяет нарушение уникальности slug на ErrSlugTaken
+func slugError(err error) error {
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "procedures_slug_key" {
+		return ErrSlugTaken
+	}
+	return err
+}
+
+func deleteRevisions(tx *sqlx.Tx, procedureIDs []int) error {
+	if len(procedureIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`DELETE FROM procedure_revisions WHERE procedure_id = ANY($1)`, pq.Array(procedureIDs))
+	return err
+}
+
+// UnknownTagsError — процедуре назначены теги, которых нет в справочнике
+type UnknownTagsError struct {
+	Tags []string
+}
+
+func (e *UnknownTagsError) Error() string {
+	return fmt.Sprintf("unknown tags: %s", strings.Join(e.Tags, ", "))
+}
+
+// loadTags заполняет Tags процедур одним запросом; процедуры без тегов
+// получают пустой список
+func loadTags(db sqlx.Queryer, procedures []models.Procedure) error {
+	if len(procedures) == 0 {
+		return nil
+	}
+	ids := make([]int, len(procedures))
+	positions := make(map[int][]int, len(procedures))
+	for i := range procedures {
+		ids[i] = procedures[i].ID
+		positions[procedures[i].ID] = append(positions[procedures[i].ID], i)
+		procedures[i].Tags = []string{}
+	}
+	var rows []struct {
+		ProcedureID int    `db:"procedure_id"`
+		TagSlug     string `db:"tag_slug"`
+	}
+	query := `
+		SELECT procedure_id, tag_slug
+		FROM procedure_tags
+		WHERE procedure_id = ANY($1)
+		ORDER BY tag_slug COLLATE "C" ASC
+	`
+	if err := sqlx.Select(db, &rows, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for _, i := range positions[row.ProcedureID] {
+			procedures[i].Tags = append(procedures[i].Tags, row.TagSlug)
+		}
+	}
+	return nil
+}
+
+// splitKnownTags делит теги на существующие в справочнике и неизвестные
+func splitKnownTags(tx *sqlx.Tx, tags []string) (known, unknown []string, err error) {
+	tags = uniqueStrings(tags)
+	known = []string{}
+	if len(tags) == 0 {
+		return known, nil, nil
+	}
+	if err := tx.Select(&known, `SELECT slug FROM tags WHERE slug = ANY($1) ORDER BY slug COLLATE "C"`, pq.Array(tags)); err != nil {
+		return nil, nil, err
+	}
+	for _, tag := range tags {
+		if !slices.Contains(known, tag) {
+			unknown = append(unknown, tag)
+		}
+	}
+	return known, unknown, nil
+}
+
+// setProcedureTags заменяет теги процедуры на tags и записывает их в procedure.
+// Если какого-то тега нет в справочнике, возвращается *UnknownTagsError.
+func setProcedureTags(tx *sqlx.Tx, procedure *models.Procedure, tags []string) error {
+	known, unknown, err := splitKnownTags(tx, tags)
 	if err != nil {
 		return err
 	}
-	rowsAffected, err := result.RowsAffected()
+	if len(unknown) > 0 {
+		return &UnknownTagsError{Tags: unknown}
+	}
+	_, err = tx.Exec(
+		`DELETE FROM procedure_tags WHERE procedure_id = $1 AND NOT (tag_slug = ANY($2))`,
+		procedure.ID,
+		pq.Array(known),
+	)
 	if err != nil {
 		return err
 	}
-	if rowsAffected == 0 {
-		return errors.ErrNotFound
+	_, err = tx.Exec(`
+		INSERT INTO procedure_tags (procedure_id, tag_slug)
+		SELECT $1, unnest($2::varchar[])
+		ON CONFLICT DO NOTHING
+	`, procedure.ID, pq.Array(known))
+	if err != nil {
+		return err
 	}
+	procedure.Tags = known
 	return nil
 }
+
+// uniqueStrings возвращает отсортированные значения без повторов
+func uniqueStrings(values []string) []string {
+	unique := slices.Clone(values)
+	slices.Sort(unique)
+	return slices.Compact(unique)
+}
+
+// insertRevision сохраняет снимок процедуры. Теги, если они ещё не загружены,
+// читаются в той же транзакции, чтобы их содержали и снимок, и ответ.
+func insertRevision(tx *sqlx.Tx, action models.RevisionAction, procedure *models.Procedure) error {
+	if procedure.Tags == nil {
+		procedures := []models.Procedure{*procedure}
+		if err := loadTags(tx, procedures); err != nil {
+			return err
+		}
+		procedure.Tags = procedures[0].Tags
+	}
+	// Блокировка строки процедуры упорядочивает параллельные изменения: иначе обе
+	// транзакции прочитают один MAX(revision) и вторая упадёт на UNIQUE (procedure_id, revision)
+	if _, err := tx.Exec(`SELECT 1 FROM procedures WHERE id = $1 FOR UPDATE`, procedure.ID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO procedure_revisions (procedure_id, revision, action, snapshot)
+		SELECT $1::integer, COALESCE(MAX(revision), 0) + 1, $2::varchar, $3::jsonb
+		FROM procedure_revisions
+		WHERE procedure_id = $1::integer
+	`
+	_, err := tx.Exec(query, procedure.ID, action, models.ProcedureSnapshot{Procedure: *procedure})
+	return err
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ProcedureTypeRepos interface {
+	GetAll() ([]models.ProcedureType, error)
+	GetBySlug(slug string) (*models.ProcedureType, error)
+	Create(procedureType *models.ProcedureType) error
+	Update(procedureType *models.ProcedureType) error
+	Delete(slug string) error
+}
+
+type ProcedureTypeRepository struct {
+	db *sqlx.DB
+}
+
+func NewProcedureTypeRepository(db *sqlx.DB) *ProcedureTypeRepository {
+	return &ProcedureTypeRepository{db: db}
+}
+
+func (r *ProcedureTypeRepository) GetAll() ([]models.ProcedureType, error) {
+	var types []models.ProcedureType
+	query := `
+		SELECT slug, display_name, description, icon, sort_order, created_at, updated_at
+		FROM procedure_types
+		ORDER BY sort_order ASC, slug ASC
+	`
+	err := r.db.Select(&types, query)
+	if err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+func (r *ProcedureTypeRepository) GetBySlug(slug string) (*models.ProcedureType, error) {
+	var procedureType models.ProcedureType
+	query := `
+		SELECT slug, display_name, description, icon, sort_order, created_at, updated_at
+		FROM procedure_types
+		WHERE slug = $1
+	`
+	err := r.db.Get(&procedureType, query, slug)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &procedureType, nil
+}
+
+// Create добавляет тип. Если slug уже занят, возвращается errors.ErrConflict.
+func (r *ProcedureTypeRepository) Create(procedureType *models.ProcedureType) error {
+	query := `
+		INSERT INTO procedure_types (slug, display_name, description, icon, sort_order)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(
+		query,
+		procedureType.Slug,
+		procedureType.DisplayName,
+		procedureType.Description,
+		procedureType.Icon,
+		procedureType.SortOrder,
+	).Scan(&procedureType.CreatedAt, &procedureType.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if stderrors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *ProcedureTypeRepository) Update(procedureType *models.ProcedureType) error {
+	query := `
+		UPDATE procedure_types
+		SET display_name = $1, description = $2, icon = $3, sort_order = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE slug = $5
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(
+		query,
+		procedureType.DisplayName,
+		procedureType.Description,
+		procedureType.Icon,
+		procedureType.SortOrder,
+		procedureType.Slug,
+	).Scan(&procedureType.CreatedAt, &procedureType.UpdatedAt)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет тип. Если на тип ссылаются процедуры, возвращается errors.ErrConflict.
+func (r *ProcedureTypeRepository) Delete(slug string) error {
+	query := `DELETE FROM procedure_types WHERE slug = $1`
+	result, err := r.db.Exec(query, slug)
+	if err != nil {
+		var pqErr *pq.Error
+		if stderrors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
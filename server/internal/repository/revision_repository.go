@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RevisionRepos interface {
+	GetByProcedure(procedureID int) ([]models.ProcedureRevision, error)
+	Get(procedureID, revision int) (*models.ProcedureRevision, error)
+	GetAllAsOf(asOf time.Time) ([]models.Procedure, error)
+}
+
+type RevisionRepository struct {
+	db *sqlx.DB
+}
+
+func NewRevisionRepository(db *sqlx.DB) *RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+func (r *RevisionRepository) GetByProcedure(procedureID int) ([]models.ProcedureRevision, error) {
+	var revisions []models.ProcedureRevision
+	query := `
+		SELECT id, procedure_id, revision, action, snapshot, created_at
+		FROM procedure_revisions
+		WHERE procedure_id = $1
+		ORDER BY revision DESC
+	`
+	err := r.db.Select(&revisions, query, procedureID)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *RevisionRepository) Get(procedureID, revision int) (*models.ProcedureRevision, error) {
+	var rev models.ProcedureRevision
+	query := `
+		SELECT id, procedure_id, revision, action, snapshot, created_at
+		FROM procedure_revisions
+		WHERE procedure_id = $1 AND revision = $2
+	`
+	err := r.db.Get(&rev, query, procedureID, revision)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// GetAllAsOf восстанавливает список опубликованных процедур по последним ревизиям,
+// созданным не позже asOf. Удалённые к этому моменту процедуры не возвращаются.
+// Ревизии, записанные до появления статусов, считаются опубликованными.
+func (r *RevisionRepository) GetAllAsOf(asOf time.Time) ([]models.Procedure, error) {
+	var snapshots []models.ProcedureSnapshot
+	query := `
+		SELECT snapshot
+		FROM (
+			SELECT DISTINCT ON (procedure_id) procedure_id, action, snapshot
+			FROM procedure_revisions
+			WHERE created_at <= $1
+			ORDER BY procedure_id, revision DESC
+		) latest
+		WHERE action <> 'delete' AND COALESCE(snapshot ->> 'status', 'published') = 'published'
+		ORDER BY (snapshot ->> 'sort_order')::integer ASC, procedure_id ASC
+	`
+	err := r.db.Select(&snapshots, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	procedures := make([]models.Procedure, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		procedures = append(procedures, snapshot.Procedure)
+	}
+	return procedures, nil
+}
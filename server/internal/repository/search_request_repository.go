@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// searchRequestColumns — набор колонок, из которых собирается models.SearchRequest
+const searchRequestColumns = `id, tracking_number, kind, status, contact_email, contact_phone, resolution, due_at,
+	(status = 'open' AND due_at < CURRENT_TIMESTAMP) AS overdue,
+	overdue_at, escalation_level, escalated_at, resolved_at, created_at, updated_at`
+
+type SearchRequestRepos interface {
+	List(filter models.SearchRequestFilter) (*models.SearchRequestPage, error)
+	GetByID(id int) (*models.SearchRequest, error)
+	Create(request *models.SearchRequest, sla time.Duration) error
+	Resolve(id int, status models.SearchRequestStatus, resolution string) (*models.SearchRequest, error)
+	Escalate(now time.Time, interval time.Duration) ([]models.SearchRequest, error)
+}
+
+type SearchRequestRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchRequestRepository(db *sqlx.DB) *SearchRequestRepository {
+	return &SearchRequestRepository{db: db}
+}
+
+// List возвращает страницу запросов на розыск, новые первыми. Следующая страница
+// начинается с запросов, ID которых меньше filter.After.
+func (r *SearchRequestRepository) List(filter models.SearchRequestFilter) (*models.SearchRequestPage, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions := []string{"TRUE"}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+arg(filter.Status))
+	}
+	if filter.TrackingNumber != "" {
+		conditions = append(conditions, "tracking_number = "+arg(filter.TrackingNumber))
+	}
+	if filter.Overdue {
+		conditions = append(conditions, "status = 'open' AND due_at < CURRENT_TIMESTAMP")
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM search_requests WHERE ` + strings.Join(conditions, " AND ")
+	if err := r.db.Get(&total, countQuery, args...); err != nil {
+		return nil, err
+	}
+
+	if filter.After > 0 {
+		conditions = append(conditions, "id < "+arg(filter.After))
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM search_requests
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT %s
+	`, searchRequestColumns, strings.Join(conditions, " AND "), arg(filter.Limit+1))
+	requests := make([]models.SearchRequest, 0, filter.Limit+1)
+	if err := r.db.Select(&requests, query, args...); err != nil {
+		return nil, err
+	}
+
+	page := &models.SearchRequestPage{Items: requests, Total: total}
+	if len(requests) > filter.Limit {
+		page.Items = requests[:filter.Limit]
+		page.NextCursor = strconv.Itoa(page.Items[len(page.Items)-1].ID)
+	}
+	return page, nil
+}
+
+func (r *SearchRequestRepository) GetByID(id int) (*models.SearchRequest, error) {
+	var request models.SearchRequest
+	query := `SELECT ` + searchRequestColumns + ` FROM search_requests WHERE id = $1`
+	if err := r.db.Get(&request, query, id); err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// Create регистрирует открытый запрос на розыск со сроком ответа через sla от момента подачи
+func (r *SearchRequestRepository) Create(request *models.SearchRequest, sla time.Duration) error {
+	query := `
+		INSERT INTO search_requests (tracking_number, kind, contact_email, contact_phone, due_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + make_interval(secs => $5))
+		RETURNING ` + searchRequestColumns
+	return r.db.Get(
+		request,
+		query,
+		request.TrackingNumber,
+		request.Kind,
+		request.ContactEmail,
+		request.ContactPhone,
+		sla.Seconds(),
+	)
+}
+
+// Resolve завершает открытый запрос на розыск. Если запроса нет, возвращается
+// errors.ErrNotFound, если он уже завершён — errors.ErrConflict.
+func (r *SearchRequestRepository) Resolve(id int, status models.SearchRequestStatus, resolution string) (*models.SearchRequest, error) {
+	var request models.SearchRequest
+	query := `
+		UPDATE search_requests
+		SET status = $2, resolution = $3, resolved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'open'
+		RETURNING ` + searchRequestColumns
+	if err := r.db.Get(&request, query, id, status, resolution); err != nil {
+		if !stderrors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if _, err := r.GetByID(id); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrConflict
+	}
+	return &request, nil
+}
+
+// Escalate отмечает просроченными открытые запросы, срок ответа которых прошёл к now,
+// и повышает им уровень эскалации. Запрос эскалируется повторно, если с прошлой эскалации
+// прошло не меньше interval; нулевой interval означает однократную эскалацию.
+// Колонки времени хранятся с часовым поясом, поэтому now из приложения и due_at,
+// посчитанный часами базы, сравниваются как моменты времени. Возвращает эскалированные запросы.
+func (r *SearchRequestRepository) Escalate(now time.Time, interval time.Duration) ([]models.SearchRequest, error) {
+	query := `
+		UPDATE search_requests
+		SET escalation_level = escalation_level + 1,
+			escalated_at = $1,
+			overdue_at = COALESCE(overdue_at, $1),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'open' AND due_at <= $1
+			AND (escalated_at IS NULL
+				OR ($2::double precision > 0 AND escalated_at <= $1 - make_interval(secs => $2::double precision)))
+		RETURNING ` + searchRequestColumns
+	var requests []models.SearchRequest
+	if err := r.db.Select(&requests, query, now, interval.Seconds()); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type TagRepos interface {
+	GetAll() ([]models.Tag, error)
+	GetBySlug(slug string) (*models.Tag, error)
+	Create(tag *models.Tag) error
+	Update(tag *models.Tag) error
+	Delete(slug string) error
+}
+
+type TagRepository struct {
+	db *sqlx.DB
+}
+
+func NewTagRepository(db *sqlx.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) GetAll() ([]models.Tag, error) {
+	var tags []models.Tag
+	query := `
+		SELECT slug, name, created_at, updated_at
+		FROM tags
+		ORDER BY slug ASC
+	`
+	err := r.db.Select(&tags, query)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) GetBySlug(slug string) (*models.Tag, error) {
+	var tag models.Tag
+	query := `
+		SELECT slug, name, created_at, updated_at
+		FROM tags
+		WHERE slug = $1
+	`
+	err := r.db.Get(&tag, query, slug)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Create добавляет тег. Если slug уже занят, возвращается errors.ErrConflict.
+func (r *TagRepository) Create(tag *models.Tag) error {
+	query := `
+		INSERT INTO tags (slug, name)
+		VALUES ($1, $2)
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(query, tag.Slug, tag.Name).Scan(&tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if stderrors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errors.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TagRepository) Update(tag *models.Tag) error {
+	query := `
+		UPDATE tags
+		SET name = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE slug = $2
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRow(query, tag.Name, tag.Slug).Scan(&tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет тег и снимает его со всех процедур. У затронутых процедур, как
+// при любом другом изменении, растёт версия и записывается ревизия, иначе ETag
+// и If-Match не заметили бы пропавший тег
+func (r *TagRepository) Delete(slug string) error {
+	return withTx(r.db, func(tx *sqlx.Tx) error {
+		// Блокировка тега не даёт параллельно привязать его к процедуре,
+		// которая иначе потеряла бы тег без новой ревизии
+		var exists int
+		err := tx.Get(&exists, `SELECT 1 FROM tags WHERE slug = $1 FOR UPDATE`, slug)
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return errors.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var ids []int
+		if err := tx.Select(&ids, `DELETE FROM procedure_tags WHERE tag_slug = $1 RETURNING procedure_id`, slug); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM tags WHERE slug = $1`, slug); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		var affected []models.Procedure
+		query := `
+			UPDATE procedures
+			SET version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ANY($1)
+			RETURNING ` + procedureColumns
+		if err := tx.Select(&affected, query, pq.Array(ids)); err != nil {
+			return err
+		}
+		for i := range affected {
+			if err := insertRevision(tx, models.RevisionUpdate, &affected[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
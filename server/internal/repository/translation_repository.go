@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type TranslationRepos interface {
+	GetByProcedure(procedureID int) ([]models.ProcedureTranslation, error)
+	Get(procedureID int, locale string) (*models.ProcedureTranslation, error)
+	GetForProcedures(procedureIDs []int, locales []string) ([]models.ProcedureTranslation, error)
+	Upsert(translation *models.ProcedureTranslation) error
+	Delete(procedureID int, locale string) error
+	GetMissing(locales []string) (map[string][]models.ProcedureRef, error)
+}
+
+type TranslationRepository struct {
+	db *sqlx.DB
+}
+
+func NewTranslationRepository(db *sqlx.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+func (r *TranslationRepository) GetByProcedure(procedureID int) ([]models.ProcedureTranslation, error) {
+	var translations []models.ProcedureTranslation
+	query := `
+		SELECT procedure_id, locale, title, content, created_at, updated_at
+		FROM procedure_translations
+		WHERE procedure_id = $1
+		ORDER BY locale ASC
+	`
+	err := r.db.Select(&translations, query, procedureID)
+	if err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+func (r *TranslationRepository) Get(procedureID int, locale string) (*models.ProcedureTranslation, error) {
+	var translation models.ProcedureTranslation
+	query := `
+		SELECT procedure_id, locale, title, content, created_at, updated_at
+		FROM procedure_translations
+		WHERE procedure_id = $1 AND locale = $2
+	`
+	err := r.db.Get(&translation, query, procedureID, locale)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &translation, nil
+}
+
+func (r *TranslationRepository) GetForProcedures(procedureIDs []int, locales []string) ([]models.ProcedureTranslation, error) {
+	var translations []models.ProcedureTranslation
+	query := `
+		SELECT procedure_id, locale, title, content, created_at, updated_at
+		FROM procedure_translations
+		WHERE procedure_id = ANY($1) AND locale = ANY($2)
+	`
+	err := r.db.Select(&translations, query, pq.Array(procedureIDs), pq.Array(locales))
+	if err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+func (r *TranslationRepository) Upsert(translation *models.ProcedureTranslation) error {
+	query := `
+		INSERT INTO procedure_translations (procedure_id, locale, title, content)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (procedure_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, content = EXCLUDED.content, updated_at = CURRENT_TIMESTAMP
+		RETURNING created_at, updated_at
+	`
+	err := withTx(r.db, func(tx *sqlx.Tx) error {
+		if err := checkAttachments(tx, translation.Content); err != nil {
+			return err
+		}
+		return tx.QueryRow(
+			query,
+			translation.ProcedureID,
+			translation.Locale,
+			translation.Title,
+			translation.Content,
+		).Scan(&translation.CreatedAt, &translation.UpdatedAt)
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if stderrors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return errors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TranslationRepository) Delete(procedureID int, locale string) error {
+	query := `DELETE FROM procedure_translations WHERE procedure_id = $1 AND locale = $2`
+	result, err := r.db.Exec(query, procedureID, locale)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+// GetMissing возвращает для каждой локали неархивные процедуры вне корзины без перевода
+func (r *TranslationRepository) GetMissing(locales []string) (map[string][]models.ProcedureRef, error) {
+	var rows []struct {
+		Locale string `db:"locale"`
+		models.ProcedureRef
+	}
+	query := `
+		SELECT l.locale, p.id, p.title, p.type
+		FROM procedures p
+		CROSS JOIN unnest($1::text[]) AS l(locale)
+		WHERE p.status <> 'archived' AND p.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM procedure_translations t
+				WHERE t.procedure_id = p.id AND t.locale = l.locale
+			)
+		ORDER BY l.locale ASC, p.sort_order ASC, p.id ASC
+	`
+	if err := r.db.Select(&rows, query, pq.Array(locales)); err != nil {
+		return nil, err
+	}
+	missing := make(map[string][]models.ProcedureRef)
+	for _, row := range rows {
+		missing[row.Locale] = append(missing[row.Locale], row.ProcedureRef)
+	}
+	return missing, nil
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// withTx выполняет fn в транзакции, откатывая её при ошибке
+func withTx(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -5,13 +5,97 @@ import (
 	"tech-quest/internal/handlers"
 )
 
-func RegisterRoutes(router fiber.Router, procedureHandler *handlers.ProcedureHandler) {
+func RegisterRoutes(
+	router fiber.Router,
+	procedureHandler *handlers.ProcedureHandler,
+	revisionHandler *handlers.RevisionHandler,
+	translationHandler *handlers.TranslationHandler,
+	procedureTypeHandler *handlers.ProcedureTypeHandler,
+	tagHandler *handlers.TagHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	claimHandler *handlers.ClaimHandler,
+	claimDocumentHandler *handlers.ClaimDocumentHandler,
+	searchRequestHandler *handlers.SearchRequestHandler,
+	cacheHandler *handlers.CacheHandler,
+	streamHandler *handlers.ProcedureStreamHandler,
+	adminAuth fiber.Handler,
+) {
 	procedures := router.Group("/procedures")
 
 	procedures.Get("/", procedureHandler.GetAll)
+	procedures.Get("/preview", adminAuth, procedureHandler.PreviewAll)
+	procedures.Get("/search", procedureHandler.Search)
+	procedures.Get("/tree", procedureHandler.Tree)
+	procedures.Get("/stream", streamHandler.Stream)
+	procedures.Get("/export", adminAuth, procedureHandler.Export)
+	procedures.Get("/trash", adminAuth, procedureHandler.Trash)
+	procedures.Delete("/trash/:id", adminAuth, procedureHandler.Purge)
 	procedures.Get("/:id", procedureHandler.GetByID)
 	procedures.Get("/type/:type", procedureHandler.GetByType)
 	procedures.Post("/", procedureHandler.Create)
+	procedures.Post("/reorder", procedureHandler.Reorder)
+	procedures.Post("/import", procedureHandler.Import)
 	procedures.Put("/:id", procedureHandler.Update)
+	procedures.Patch("/:id", procedureHandler.Patch)
 	procedures.Delete("/:id", procedureHandler.Delete)
+	procedures.Get("/:id/preview", adminAuth, procedureHandler.Preview)
+	procedures.Post("/:id/status", procedureHandler.Transition)
+	procedures.Post("/:id/restore", adminAuth, procedureHandler.Restore)
+	procedures.Post("/:id/move", procedureHandler.Move)
+
+	procedures.Get("/:id/revisions", revisionHandler.GetByProcedure)
+	procedures.Get("/:id/revisions/diff", revisionHandler.Diff)
+	procedures.Get("/:id/revisions/:rev", revisionHandler.Get)
+	procedures.Post("/:id/revisions/:rev/restore", revisionHandler.Restore)
+
+	procedures.Get("/:id/translations", translationHandler.GetByProcedure)
+	procedures.Get("/:id/translations/:locale", translationHandler.Get)
+	procedures.Put("/:id/translations/:locale", translationHandler.Upsert)
+	procedures.Delete("/:id/translations/:locale", translationHandler.Delete)
+
+	translations := router.Group("/translations")
+	translations.Get("/missing", translationHandler.Missing)
+
+	procedureTypes := router.Group("/procedure-types")
+	procedureTypes.Get("/", procedureTypeHandler.GetAll)
+	procedureTypes.Get("/:slug", procedureTypeHandler.GetBySlug)
+	procedureTypes.Post("/", procedureTypeHandler.Create)
+	procedureTypes.Put("/:slug", procedureTypeHandler.Update)
+	procedureTypes.Delete("/:slug", procedureTypeHandler.Delete)
+
+	tags := router.Group("/tags")
+	tags.Get("/", tagHandler.GetAll)
+	tags.Get("/:slug", tagHandler.GetBySlug)
+	tags.Post("/", tagHandler.Create)
+	tags.Put("/:slug", tagHandler.Update)
+	tags.Delete("/:slug", tagHandler.Delete)
+
+	attachments := router.Group("/attachments")
+	attachments.Get("/", attachmentHandler.GetAll)
+	attachments.Get("/:id", attachmentHandler.GetByID)
+	attachments.Get("/:id/download", attachmentHandler.Download)
+	attachments.Post("/", adminAuth, attachmentHandler.Upload)
+	attachments.Delete("/:id", adminAuth, attachmentHandler.Delete)
+
+	// Заявления содержат персональные данные, поэтому читать их могут только администраторы
+	claims := router.Group("/claims")
+	claims.Get("/", adminAuth, claimHandler.GetAll)
+	claims.Get("/:id", adminAuth, claimHandler.GetByID)
+	claims.Get("/:id/checklist", adminAuth, claimHandler.Checklist)
+	claims.Post("/", claimHandler.Create)
+	claims.Post("/:id/transitions", adminAuth, claimHandler.Transition)
+	// Документы загружает сам заявитель, подтверждая трек-номером, что заявление его
+	claims.Get("/:id/documents", adminAuth, claimDocumentHandler.GetAll)
+	claims.Post("/:id/documents", claimDocumentHandler.Upload)
+	claims.Get("/:id/documents/:document/download", adminAuth, claimDocumentHandler.Download)
+	claims.Get("/:id/documents/:document/thumbnail", adminAuth, claimDocumentHandler.Thumbnail)
+
+	searchRequests := router.Group("/search-requests")
+	searchRequests.Get("/", adminAuth, searchRequestHandler.GetAll)
+	searchRequests.Get("/:id", adminAuth, searchRequestHandler.GetByID)
+	searchRequests.Post("/", searchRequestHandler.Create)
+	searchRequests.Post("/:id/resolve", adminAuth, searchRequestHandler.Resolve)
+
+	cache := router.Group("/cache")
+	cache.Get("/stats", adminAuth, cacheHandler.Stats)
 }
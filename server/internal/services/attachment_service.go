@@ -0,0 +1,306 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"tech-quest/pkg/storage"
+	"time"
+	"unicode/utf8"
+)
+
+// oleSignature — начало файлов Word и Excel старого формата (.doc, .xls)
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// officeContentTypes уточняет тип по расширению для форматов, которые
+// http.DetectContentType распознаёт только как zip или произвольные байты
+var officeContentTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+}
+
+type AttachmentService struct {
+	repo    repository.AttachmentRepos
+	storage storage.Storage
+	// maxSize — максимальный размер файла в байтах
+	maxSize int64
+	// allowedTypes — допустимые MIME-типы без параметров
+	allowedTypes []string
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepos,
+	storage storage.Storage,
+	maxSize int64,
+	allowedTypes []string,
+) *AttachmentService {
+	return &AttachmentService{repo: repo, storage: storage, maxSize: maxSize, allowedTypes: allowedTypes}
+}
+
+func (s *AttachmentService) GetAll() ([]models.Attachment, error) {
+	attachments, err := s.repo.GetAll()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get attachments: " + err.Error(),
+			},
+		)
+	}
+	return attachments, nil
+}
+
+func (s *AttachmentService) GetByID(id int) (*models.Attachment, error) {
+	attachment, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, attachmentNotFoundError()
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get attachment: " + err.Error(),
+			},
+		)
+	}
+	return attachment, nil
+}
+
+// Upload сохраняет файл в хранилище и регистрирует вложение. Тип определяется
+// по содержимому, а не по заявленному клиентом Content-Type.
+func (s *AttachmentService) Upload(fileName string, body io.Reader) (*models.Attachment, error) {
+	fileName, err := sanitizeFileName(fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readUpload(body, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	contentType := sniffContentType(fileName, data)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !slices.Contains(s.allowedTypes, mediaType) {
+		return nil, unsupportedTypeError(mediaType, s.allowedTypes)
+	}
+
+	checksum := sha256.Sum256(data)
+	attachment := &models.Attachment{
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		StorageKey:  newStorageKey(),
+	}
+	if err := s.storage.Put(attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to store file: " + err.Error(),
+			},
+		)
+	}
+	if err := s.repo.Create(attachment); err != nil {
+		if err := s.storage.Delete(attachment.StorageKey); err != nil {
+			log.Printf("attachments: failed to delete orphaned file %s: %v", attachment.StorageKey, err)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to create attachment: " + err.Error(),
+			},
+		)
+	}
+	return attachment, nil
+}
+
+// Open открывает содержимое вложения начиная с offset; length < 0 — до конца файла
+func (s *AttachmentService) Open(attachment *models.Attachment, offset, length int64) (io.ReadCloser, error) {
+	body, err := s.storage.Get(attachment.StorageKey, offset, length)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "attachment file is missing in storage",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to read attachment: " + err.Error(),
+			},
+		)
+	}
+	return body, nil
+}
+
+// Delete удаляет вложение, если на него не ссылается ни одна процедура или перевод
+func (s *AttachmentService) Delete(id int) error {
+	attachment, err := s.repo.Delete(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return attachmentNotFoundError()
+		}
+		if err == errors.ErrConflict {
+			return errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "attachment is referenced by procedure content",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to delete attachment: " + err.Error(),
+			},
+		)
+	}
+	// Запись уже удалена: файл без записи недоступен, поэтому ошибка только логируется
+	if err := s.storage.Delete(attachment.StorageKey); err != nil {
+		log.Printf("attachments: failed to delete file %s: %v", attachment.StorageKey, err)
+	}
+	return nil
+}
+
+// sanitizeFileName оставляет от имени загруженного файла только базовое имя:
+// браузеры и клиенты Windows присылают полный путь
+func sanitizeFileName(fileName string) (string, error) {
+	fileName = strings.TrimSpace(filepath.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if fileName == "" || fileName == "." || fileName == "/" || !utf8.ValidString(fileName) {
+		return "", errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "file name is required",
+				Attr:   "file",
+			},
+		)
+	}
+	if utf8.RuneCountInString(fileName) > 255 {
+		return "", errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "file name must be at most 255 characters",
+				Attr:   "file",
+			},
+		)
+	}
+	return fileName, nil
+}
+
+// readUpload читает непустой файл размером не больше maxSize байт
+func readUpload(body io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "failed to read file: " + err.Error(),
+				Attr:   "file",
+			},
+		)
+	}
+	if len(data) == 0 {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "file is empty",
+				Attr:   "file",
+			},
+		)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errors.NewError(
+			413,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("file must be at most %d bytes", maxSize),
+				Attr:   "file",
+			},
+		)
+	}
+	return data, nil
+}
+
+func unsupportedTypeError(mediaType string, allowedTypes []string) error {
+	return errors.NewError(
+		415,
+		errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("file type %s is not allowed, allowed types: %s", mediaType, strings.Join(allowedTypes, ", ")),
+			Attr:   "file",
+		},
+	)
+}
+
+// sniffContentType определяет MIME-тип по первым байтам файла. Офисные форматы
+// уточняются по расширению, только если содержимое им соответствует.
+func sniffContentType(fileName string, data []byte) string {
+	detected := http.DetectContentType(data)
+	officeType, ok := officeContentTypes[strings.ToLower(filepath.Ext(fileName))]
+	if !ok {
+		return detected
+	}
+	switch {
+	case detected == "application/zip" && strings.Contains(officeType, "openxmlformats"):
+		return officeType
+	case bytes.HasPrefix(data, oleSignature) && !strings.Contains(officeType, "openxmlformats"):
+		return officeType
+	}
+	return detected
+}
+
+// newStorageKey возвращает случайный ключ файла с префиксом года и месяца загрузки
+func newStorageKey() string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return time.Now().UTC().Format("2006/01/") + hex.EncodeToString(random)
+}
+
+func attachmentNotFoundError() error {
+	return errors.NewError(
+		404,
+		errors.ErrorDetail{
+			Code:   errors.NotFoundCode,
+			Detail: "attachment not found",
+		},
+	)
+}
+
+func unknownAttachmentsError(unknown *repository.UnknownAttachmentsError) error {
+	return errors.NewError(
+		422,
+		errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("unknown attachments: %s", joinInts(unknown.IDs)),
+			Attr:   "content",
+		},
+	)
+}
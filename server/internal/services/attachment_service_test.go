@@ -0,0 +1,185 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+	"tech-quest/pkg/storage"
+)
+
+var testAllowedTypes = []string{
+	"application/pdf",
+	"image/png",
+	"text/plain",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	pdf := "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n%%EOF\n"
+	tests := []struct {
+		name            string
+		fileName        string
+		body            string
+		wantStatus      int
+		wantFileName    string
+		wantContentType string
+	}{
+		{
+			name:            "pdf",
+			fileName:        "Заявление.pdf",
+			body:            pdf,
+			wantFileName:    "Заявление.pdf",
+			wantContentType: "application/pdf",
+		},
+		{
+			name:            "path in file name is dropped",
+			fileName:        `C:\Users\ivan\form.pdf`,
+			body:            pdf,
+			wantFileName:    "form.pdf",
+			wantContentType: "application/pdf",
+		},
+		{
+			name:       "declared extension does not matter",
+			fileName:   "form.pdf",
+			body:       "<html><script>alert(1)</script></html>",
+			wantStatus: 415,
+		},
+		{
+			name:       "too large",
+			fileName:   "notes.txt",
+			body:       strings.Repeat("a", 65),
+			wantStatus: 413,
+		},
+		{
+			name:       "empty",
+			fileName:   "notes.txt",
+			wantStatus: 400,
+		},
+		{
+			name:       "missing file name",
+			fileName:   "",
+			body:       pdf,
+			wantStatus: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := storage.NewLocal(t.TempDir())
+			require.NoError(t, err)
+			var created *models.Attachment
+			repo := &mocks.AttachmentRepoMock{
+				CreateFn: func(a *models.Attachment) error {
+					a.ID = 1
+					created = a
+					return nil
+				},
+			}
+			service := NewAttachmentService(repo, files, 64, testAllowedTypes)
+			attachment, err := service.Upload(tt.fileName, strings.NewReader(tt.body))
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				require.Nil(t, created)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFileName, attachment.FileName)
+			require.Equal(t, tt.wantContentType, attachment.ContentType)
+			require.Equal(t, int64(len(tt.body)), attachment.Size)
+			require.Len(t, attachment.Checksum, 64)
+
+			body, err := service.Open(attachment, 5, 3)
+			require.NoError(t, err)
+			defer body.Close()
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			require.Equal(t, tt.body[5:8], string(data))
+		})
+	}
+}
+
+func TestAttachmentService_UploadRemovesFileWhenCreateFails(t *testing.T) {
+	dir := t.TempDir()
+	files, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	var key string
+	repo := &mocks.AttachmentRepoMock{
+		CreateFn: func(a *models.Attachment) error {
+			key = a.StorageKey
+			return stderrors.New("db down")
+		},
+	}
+	service := NewAttachmentService(repo, files, 1024, testAllowedTypes)
+	_, err = service.Upload("notes.txt", strings.NewReader("hello"))
+	require.Error(t, err)
+
+	_, err = files.Get(key, 0, -1)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestAttachmentService_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantStatus int
+	}{
+		{name: "success"},
+		{name: "referenced", repoErr: appErrors.ErrConflict, wantStatus: 409},
+		{name: "not found", repoErr: appErrors.ErrNotFound, wantStatus: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := storage.NewLocal(t.TempDir())
+			require.NoError(t, err)
+			require.NoError(t, files.Put("2026/01/abc", strings.NewReader("data"), 4, "text/plain"))
+			repo := &mocks.AttachmentRepoMock{
+				DeleteFn: func(int) (*models.Attachment, error) {
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.Attachment{ID: 1, StorageKey: "2026/01/abc"}, nil
+				},
+			}
+			service := NewAttachmentService(repo, files, 1024, testAllowedTypes)
+			err = service.Delete(1)
+			_, getErr := files.Get("2026/01/abc", 0, -1)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				require.NoError(t, getErr)
+				return
+			}
+			require.NoError(t, err)
+			require.ErrorIs(t, getErr, storage.ErrNotFound)
+		})
+	}
+}
+
+func TestSniffContentType(t *testing.T) {
+	var docx bytes.Buffer
+	archive := zip.NewWriter(&docx)
+	entry, err := archive.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = entry.Write([]byte("<w:document/>"))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	require.Equal(t,
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		sniffContentType("form.docx", docx.Bytes()),
+	)
+	require.Equal(t, "application/zip", sniffContentType("form.zip", docx.Bytes()))
+	require.Equal(t, "application/msword", sniffContentType("form.doc", append(oleSignature, 0, 0, 0, 0)))
+	require.Equal(t, "text/plain; charset=utf-8", sniffContentType("form.docx", []byte("not a document")))
+}
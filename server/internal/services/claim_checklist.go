@@ -0,0 +1,109 @@
+package services
+
+import (
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+)
+
+// claimChecklistTypes — типы процедур, из документов которых собирается чек-лист заявления
+var claimChecklistTypes = []string{"loss_or_damage_docs", "damage_additional_docs"}
+
+// Checklist сверяет документы заявления с требованиями опубликованных процедур
+// claimChecklistTypes и возвращает, какие требования выполнены, а каких документов не хватает
+func (s *ClaimService) Checklist(id int) (*models.ClaimChecklist, error) {
+	claim, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.checklist(claim)
+}
+
+func (s *ClaimService) checklist(claim *models.Claim) (*models.ClaimChecklist, error) {
+	procedures, err := s.requirementProcedures()
+	if err != nil {
+		return nil, err
+	}
+	documents, err := s.repo.ListDocuments(claim.ID)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get claim documents: " + err.Error(),
+			},
+		)
+	}
+	return BuildClaimChecklist(claim, procedures, documents), nil
+}
+
+// requirementProcedures возвращает опубликованные процедуры с требованиями к документам
+func (s *ClaimService) requirementProcedures() ([]models.Procedure, error) {
+	var procedures []models.Procedure
+	for _, procedureType := range claimChecklistTypes {
+		found, err := s.procedures.GetByType(procedureType, models.TagFilter{})
+		if err != nil {
+			return nil, errors.NewError(
+				500,
+				errors.ErrorDetail{
+					Code:   errors.ServerErrorCode,
+					Detail: "failed to get document requirements: " + err.Error(),
+				},
+			)
+		}
+		procedures = append(procedures, found...)
+	}
+	return procedures, nil
+}
+
+// BuildClaimChecklist собирает чек-лист заявления из требований элементов содержимого
+// процедур в их порядке. Требование с одним кодом в нескольких процедурах попадает
+// в чек-лист один раз и обязательно, если обязательно хотя бы в одной из них.
+// Без единого требования чек-лист не полон: пропавшие или снятые с публикации
+// процедуры не должны открывать рассмотрение заявления без документов.
+func BuildClaimChecklist(claim *models.Claim, procedures []models.Procedure, documents []models.ClaimDocument) *models.ClaimChecklist {
+	byCode := map[string][]int{}
+	for _, document := range documents {
+		byCode[document.Code] = append(byCode[document.Code], document.ID)
+	}
+
+	checklist := &models.ClaimChecklist{
+		ClaimID: claim.ID,
+		Kind:    claim.Kind,
+		Missing: []string{},
+		Items:   []models.ClaimChecklistItem{},
+	}
+	index := map[string]int{}
+	for _, procedure := range procedures {
+		for _, item := range procedure.Content {
+			requirement := item.Requirement
+			if requirement == nil || !requirement.AppliesTo(claim.Kind) {
+				continue
+			}
+			if i, ok := index[requirement.Code]; ok {
+				checklist.Items[i].Required = checklist.Items[i].Required || requirement.Required
+				continue
+			}
+			index[requirement.Code] = len(checklist.Items)
+			ids := byCode[requirement.Code]
+			if ids == nil {
+				ids = []int{}
+			}
+			checklist.Items = append(checklist.Items, models.ClaimChecklistItem{
+				Code:         requirement.Code,
+				Title:        item.Value,
+				Required:     requirement.Required,
+				ProcedureID:  procedure.ID,
+				ContentTypes: requirement.ContentTypes,
+				Satisfied:    len(ids) > 0,
+				DocumentIDs:  ids,
+			})
+		}
+	}
+	for _, item := range checklist.Items {
+		if item.Required && !item.Satisfied {
+			checklist.Missing = append(checklist.Missing, item.Code)
+		}
+	}
+	checklist.Complete = len(checklist.Items) > 0 && len(checklist.Missing) == 0
+	return checklist
+}
@@ -0,0 +1,422 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"slices"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"tech-quest/pkg/imaging"
+	"tech-quest/pkg/storage"
+)
+
+// MaxClaimUploadFiles — сколько файлов можно загрузить одним запросом
+const MaxClaimUploadFiles = 5
+
+// strippableImageTypes — изображения, из которых удаляются метаданные и строится превью.
+// Остальные изображения не принимаются: EXIF из них не удалить.
+var strippableImageTypes = []string{"image/jpeg", "image/png"}
+
+// ClaimDocumentLimits — ограничения на документы заявления
+type ClaimDocumentLimits struct {
+	// MaxSize — максимальный размер файла в байтах
+	MaxSize int64
+	// MaxPerCode — сколько документов можно приложить по одному требованию
+	MaxPerCode int
+	// MaxPerClaim — сколько документов можно приложить к заявлению всего
+	MaxPerClaim int
+	// AllowedTypes — MIME-типы для требований без собственного списка content_types
+	AllowedTypes []string
+	// ThumbnailSize — большая сторона превью изображений в пикселях
+	ThumbnailSize int
+}
+
+// ClaimUpload — файл из multipart-формы загрузки документов
+type ClaimUpload struct {
+	FileName string
+	Body     io.Reader
+}
+
+type ClaimDocumentService struct {
+	claims  *ClaimService
+	repo    repository.ClaimRepos
+	storage storage.Storage
+	limits  ClaimDocumentLimits
+}
+
+func NewClaimDocumentService(
+	claims *ClaimService,
+	repo repository.ClaimRepos,
+	storage storage.Storage,
+	limits ClaimDocumentLimits,
+) *ClaimDocumentService {
+	return &ClaimDocumentService{claims: claims, repo: repo, storage: storage, limits: limits}
+}
+
+// preparedDocument — проверенный файл, готовый к сохранению
+type preparedDocument struct {
+	document  models.ClaimDocument
+	data      []byte
+	thumbnail []byte
+}
+
+// List возвращает документы заявления в порядке загрузки
+func (s *ClaimDocumentService) List(claimID int) ([]models.ClaimDocument, error) {
+	if _, err := s.claims.GetByID(claimID); err != nil {
+		return nil, err
+	}
+	documents, err := s.repo.ListDocuments(claimID)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get claim documents: " + err.Error(),
+			},
+		)
+	}
+	return documents, nil
+}
+
+// Upload прикладывает файлы к заявлению по требованию code из его чек-листа.
+// Загрузить документы может сам заявитель, поэтому трек-номер должен совпадать
+// с трек-номером заявления. Из фотографий удаляются метаданные (EXIF с координатами),
+// для них строится превью. Файл, уже загруженный по этому требованию, повторно
+// не сохраняется: возвращается прежний документ с duplicate=true.
+// Ошибка в любом файле отклоняет всю загрузку: документы регистрируются одной
+// транзакцией, а уже сохранённые файлы удаляются.
+func (s *ClaimDocumentService) Upload(claimID int, trackingNumber, code string, uploads []ClaimUpload) ([]models.ClaimDocument, error) {
+	if len(uploads) == 0 || len(uploads) > MaxClaimUploadFiles {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("upload 1 to %d files at once", MaxClaimUploadFiles),
+				Attr:   "file",
+			},
+		)
+	}
+	claim, err := s.claims.GetByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+	// Несовпадение трек-номера не отличается от отсутствия заявления, чтобы не подтверждать его ID
+	if normalizeTrackingNumber(trackingNumber) != claim.TrackingNumber {
+		return nil, claimNotFoundError()
+	}
+	// Статус проверяется ещё раз при регистрации документов, здесь — чтобы не разбирать файлы зря
+	if !claim.Status.AcceptsDocuments() {
+		return nil, claimStatusError(claim.Status)
+	}
+
+	procedures, err := s.claims.requirementProcedures()
+	if err != nil {
+		return nil, err
+	}
+	documents, err := s.repo.ListDocuments(claimID)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get claim documents: " + err.Error(),
+			},
+		)
+	}
+	checklist := BuildClaimChecklist(claim, procedures, documents)
+	index := slices.IndexFunc(checklist.Items, func(item models.ClaimChecklistItem) bool {
+		return item.Code == code
+	})
+	if index < 0 {
+		codes := make([]string, len(checklist.Items))
+		for i, item := range checklist.Items {
+			codes[i] = item.Code
+		}
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("unknown document code %q for %s claims, expected one of: %s", code, claim.Kind, strings.Join(codes, ", ")),
+				Attr:   "code",
+			},
+		)
+	}
+	allowedTypes := checklist.Items[index].ContentTypes
+	if len(allowedTypes) == 0 {
+		allowedTypes = s.limits.AllowedTypes
+	}
+
+	prepared := make([]preparedDocument, len(uploads))
+	for i, upload := range uploads {
+		if prepared[i], err = s.prepare(claimID, code, upload, allowedTypes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Повторы ищутся среди документов требования и среди файлов этой же загрузки
+	existing := map[string]models.ClaimDocument{}
+	for _, document := range documents {
+		if document.Code == code {
+			existing[document.Checksum] = document
+		}
+	}
+	first := map[string]int{}
+	for i := range prepared {
+		checksum := prepared[i].document.Checksum
+		if _, ok := existing[checksum]; ok {
+			continue
+		}
+		if _, ok := first[checksum]; !ok {
+			first[checksum] = i
+		}
+	}
+
+	var keys []string
+	var pending []models.ClaimDocument
+	for i := range prepared {
+		if j, ok := first[prepared[i].document.Checksum]; !ok || j != i {
+			continue
+		}
+		stored, err := s.storeFiles(&prepared[i])
+		keys = append(keys, stored...)
+		if err != nil {
+			s.deleteFiles(keys)
+			return nil, err
+		}
+		pending = append(pending, prepared[i].document)
+	}
+	var created []models.ClaimDocument
+	if len(pending) > 0 {
+		created, err = s.repo.CreateDocuments(claimID, pending, s.limits.MaxPerCode, s.limits.MaxPerClaim)
+		if err != nil {
+			s.deleteFiles(keys)
+			return nil, createDocumentsError(err)
+		}
+	}
+	registered := map[string]models.ClaimDocument{}
+	for i, document := range created {
+		// Такой же файл успели загрузить параллельно: новые файлы не нужны
+		if document.Duplicate {
+			s.deleteFiles([]string{pending[i].StorageKey, pending[i].ThumbnailKey})
+		}
+		registered[document.Checksum] = document
+	}
+
+	results := make([]models.ClaimDocument, len(prepared))
+	for i := range prepared {
+		checksum := prepared[i].document.Checksum
+		if document, ok := existing[checksum]; ok {
+			results[i] = document
+			results[i].Duplicate = true
+			continue
+		}
+		results[i] = registered[checksum]
+		if first[checksum] != i {
+			results[i].Duplicate = true
+		}
+	}
+	return results, nil
+}
+
+// prepare читает и проверяет файл, удаляет метаданные изображений и строит превью
+func (s *ClaimDocumentService) prepare(claimID int, code string, upload ClaimUpload, allowedTypes []string) (preparedDocument, error) {
+	fileName, err := sanitizeFileName(upload.FileName)
+	if err != nil {
+		return preparedDocument{}, err
+	}
+	data, err := readUpload(upload.Body, s.limits.MaxSize)
+	if err != nil {
+		return preparedDocument{}, err
+	}
+	contentType := sniffContentType(fileName, data)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !slices.Contains(allowedTypes, mediaType) {
+		return preparedDocument{}, unsupportedTypeError(mediaType, allowedTypes)
+	}
+
+	var thumbnail []byte
+	switch {
+	case slices.Contains(strippableImageTypes, mediaType):
+		if data, err = imaging.StripMetadata(data); err != nil {
+			return preparedDocument{}, damagedImageError(fileName, err)
+		}
+		if thumbnail, err = imaging.Thumbnail(data, s.limits.ThumbnailSize); err != nil {
+			return preparedDocument{}, damagedImageError(fileName, err)
+		}
+	case strings.HasPrefix(mediaType, "image/"):
+		return preparedDocument{}, errors.NewError(
+			415,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("images of type %s are not accepted, use JPEG or PNG", mediaType),
+				Attr:   "file",
+			},
+		)
+	}
+
+	checksum := sha256.Sum256(data)
+	return preparedDocument{
+		document: models.ClaimDocument{
+			ClaimID:     claimID,
+			Code:        code,
+			FileName:    fileName,
+			ContentType: contentType,
+			Size:        int64(len(data)),
+			Checksum:    hex.EncodeToString(checksum[:]),
+		},
+		data:      data,
+		thumbnail: thumbnail,
+	}, nil
+}
+
+// storeFiles сохраняет файл и превью в хранилище, заполняет их ключи в документе
+// и возвращает ключи сохранённых файлов, в том числе при ошибке
+func (s *ClaimDocumentService) storeFiles(prepared *preparedDocument) ([]string, error) {
+	document := &prepared.document
+	document.StorageKey = fmt.Sprintf("claims/%d/%s", document.ClaimID, newStorageKey())
+	if err := s.storage.Put(document.StorageKey, bytes.NewReader(prepared.data), document.Size, document.ContentType); err != nil {
+		return nil, storeFileError(err)
+	}
+	keys := []string{document.StorageKey}
+	if prepared.thumbnail != nil {
+		document.ThumbnailKey = document.StorageKey + ".thumb.jpg"
+		err := s.storage.Put(document.ThumbnailKey, bytes.NewReader(prepared.thumbnail), int64(len(prepared.thumbnail)), "image/jpeg")
+		if err != nil {
+			return keys, storeFileError(err)
+		}
+		keys = append(keys, document.ThumbnailKey)
+	}
+	return keys, nil
+}
+
+// deleteFiles удаляет файлы, которые не попали в документы заявления; пустые ключи пропускаются
+func (s *ClaimDocumentService) deleteFiles(keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(key); err != nil {
+			log.Printf("claim documents: failed to delete orphaned file %s: %v", key, err)
+		}
+	}
+}
+
+// Open открывает документ заявления или его превью
+func (s *ClaimDocumentService) Open(claimID, documentID int, thumbnail bool) (*models.ClaimDocument, io.ReadCloser, error) {
+	document, err := s.repo.GetDocument(claimID, documentID)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, nil, claimDocumentNotFoundError("claim document not found")
+		}
+		return nil, nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get claim document: " + err.Error(),
+			},
+		)
+	}
+	key := document.StorageKey
+	if thumbnail {
+		if document.ThumbnailKey == "" {
+			return nil, nil, claimDocumentNotFoundError("claim document has no thumbnail")
+		}
+		key = document.ThumbnailKey
+	}
+	body, err := s.storage.Get(key, 0, -1)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, nil, claimDocumentNotFoundError("claim document file is missing in storage")
+		}
+		return nil, nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to read claim document: " + err.Error(),
+			},
+		)
+	}
+	return document, body, nil
+}
+
+func damagedImageError(fileName string, err error) error {
+	detail := fmt.Sprintf("%s is not a valid image", fileName)
+	if !stderrors.Is(err, imaging.ErrUnsupported) {
+		detail = fmt.Sprintf("%s: %v", fileName, err)
+	}
+	return errors.NewError(
+		400,
+		errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: detail,
+			Attr:   "file",
+		},
+	)
+}
+
+func claimStatusError(status models.ClaimStatus) error {
+	return errors.NewError(
+		409,
+		errors.ErrorDetail{
+			Code:   errors.ConflictCode,
+			Detail: fmt.Sprintf("documents cannot be added to a claim in status %s", status),
+			Attr:   "status",
+		},
+	)
+}
+
+// createDocumentsError переводит ошибку регистрации документов в ответ API
+func createDocumentsError(err error) error {
+	var statusErr *repository.ClaimStatusError
+	var limitErr *repository.ClaimDocumentLimitError
+	switch {
+	case err == errors.ErrNotFound:
+		return claimNotFoundError()
+	case stderrors.As(err, &statusErr):
+		return claimStatusError(statusErr.Status)
+	case stderrors.As(err, &limitErr):
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: limitErr.Error(),
+				Attr:   "file",
+			},
+		)
+	}
+	return errors.NewError(
+		500,
+		errors.ErrorDetail{
+			Code:   errors.ServerErrorCode,
+			Detail: "failed to create claim documents: " + err.Error(),
+		},
+	)
+}
+
+func storeFileError(err error) error {
+	return errors.NewError(
+		500,
+		errors.ErrorDetail{
+			Code:   errors.ServerErrorCode,
+			Detail: "failed to store file: " + err.Error(),
+		},
+	)
+}
+
+func claimDocumentNotFoundError(detail string) error {
+	return errors.NewError(
+		404,
+		errors.ErrorDetail{
+			Code:   errors.NotFoundCode,
+			Detail: detail,
+		},
+	)
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"bytes"
+	stderrors "errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+	"tech-quest/pkg/storage"
+)
+
+var testClaimDocumentLimits = ClaimDocumentLimits{
+	MaxSize:       1 << 20,
+	MaxPerCode:    2,
+	MaxPerClaim:   3,
+	AllowedTypes:  []string{"application/pdf", "image/jpeg", "image/png"},
+	ThumbnailSize: 16,
+}
+
+// photoWithGPS возвращает JPEG с EXIF, в котором записаны координаты съёмки
+func photoWithGPS(t *testing.T, shade uint8) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: shade, G: uint8(x * 4), B: uint8(y * 8), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	payload := "Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00GPS 55.7558N 37.6173E"
+	exif := append([]byte{0xFF, 0xE1, 0x00, byte(len(payload) + 2)}, payload...)
+	encoded := buf.Bytes()
+	return append(append(append([]byte{}, encoded[:2]...), exif...), encoded[2:]...)
+}
+
+// storedFiles возвращает пути файлов в хранилище относительно его каталога
+func storedFiles(t *testing.T, dir string) []string {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func claimUpload(name string, data []byte) ClaimUpload {
+	return ClaimUpload{FileName: name, Body: bytes.NewReader(data)}
+}
+
+func TestClaimDocumentService_UploadPhoto(t *testing.T) {
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	var created []models.ClaimDocument
+	repo := &mocks.ClaimRepoMock{
+		GetByIDFn: func(id int) (*models.Claim, error) {
+			return &models.Claim{ID: id, Kind: models.ClaimDamage, Status: models.ClaimAwaitingDocuments, TrackingNumber: "RA123456789RU"}, nil
+		},
+		ListDocumentsFn: func(int) ([]models.ClaimDocument, error) {
+			return []models.ClaimDocument{}, nil
+		},
+		CreateDocumentsFn: func(claimID int, documents []models.ClaimDocument, maxPerCode, maxPerClaim int) ([]models.ClaimDocument, error) {
+			require.Equal(t, 7, claimID)
+			require.Equal(t, testClaimDocumentLimits.MaxPerCode, maxPerCode)
+			require.Equal(t, testClaimDocumentLimits.MaxPerClaim, maxPerClaim)
+			for _, document := range documents {
+				document.ID = 100 + len(created)
+				document.HasThumbnail = document.ThumbnailKey != ""
+				created = append(created, document)
+			}
+			return created, nil
+		},
+	}
+	procedures := &mocks.ProcedureRepoMock{
+		GetByTypeFn: func(procedureType string, _ models.TagFilter) ([]models.Procedure, error) {
+			return checklistProcedures()[procedureType], nil
+		},
+	}
+	service := NewClaimDocumentService(NewClaimService(repo, procedures), repo, local, testClaimDocumentLimits)
+
+	documents, err := service.Upload(7, "ra 1234 5678 9ru", "damage_photos", []ClaimUpload{
+		claimUpload("IMG_0001.JPG", photoWithGPS(t, 10)),
+	})
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	document := documents[0]
+	require.Equal(t, 100, document.ID)
+	require.Equal(t, "image/jpeg", document.ContentType)
+	require.True(t, document.HasThumbnail)
+	require.False(t, document.Duplicate)
+	require.True(t, strings.HasPrefix(document.StorageKey, "claims/7/"))
+	require.Equal(t, document.StorageKey+".thumb.jpg", document.ThumbnailKey)
+
+	repo.GetDocumentFn = func(claimID, id int) (*models.ClaimDocument, error) {
+		return &document, nil
+	}
+	_, body, err := service.Open(7, document.ID, false)
+	require.NoError(t, err)
+	stored, err := io.ReadAll(body)
+	require.NoError(t, body.Close())
+	require.NoError(t, err)
+	require.NotContains(t, string(stored), "GPS")
+	require.Equal(t, document.Size, int64(len(stored)))
+
+	_, body, err = service.Open(7, document.ID, true)
+	require.NoError(t, err)
+	thumbnail, err := jpeg.Decode(body)
+	require.NoError(t, body.Close())
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 16, 8), thumbnail.Bounds())
+}
+
+func TestClaimDocumentService_UploadDeduplicates(t *testing.T) {
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+	attached := []models.ClaimDocument{}
+	var created []models.ClaimDocument
+	repo := &mocks.ClaimRepoMock{
+		GetByIDFn: func(id int) (*models.Claim, error) {
+			return &models.Claim{ID: id, Kind: models.ClaimDamage, Status: models.ClaimSubmitted, TrackingNumber: "RA123456789RU"}, nil
+		},
+		ListDocumentsFn: func(int) ([]models.ClaimDocument, error) {
+			return attached, nil
+		},
+		CreateDocumentsFn: func(_ int, documents []models.ClaimDocument, _, _ int) ([]models.ClaimDocument, error) {
+			result := make([]models.ClaimDocument, len(documents))
+			for i, document := range documents {
+				document.ID = 100 + len(created)
+				created = append(created, document)
+				result[i] = document
+			}
+			return result, nil
+		},
+	}
+	procedures := &mocks.ProcedureRepoMock{
+		GetByTypeFn: func(procedureType string, _ models.TagFilter) ([]models.Procedure, error) {
+			return checklistProcedures()[procedureType], nil
+		},
+	}
+	service := NewClaimDocumentService(NewClaimService(repo, procedures), repo, local, testClaimDocumentLimits)
+	photo := photoWithGPS(t, 10)
+
+	documents, err := service.Upload(7, "RA123456789RU", "damage_photos", []ClaimUpload{
+		claimUpload("a.jpg", photo),
+		claimUpload("copy of a.jpg", photo),
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	require.False(t, documents[0].Duplicate)
+	require.True(t, documents[1].Duplicate)
+	require.Equal(t, documents[0].ID, documents[1].ID)
+	require.Len(t, storedFiles(t, dir), 2)
+
+	// Повторная загрузка уже приложенного файла ничего не сохраняет
+	attached = created
+	documents, err = service.Upload(7, "RA123456789RU", "damage_photos", []ClaimUpload{claimUpload("again.jpg", photo)})
+	require.NoError(t, err)
+	require.True(t, documents[0].Duplicate)
+	require.Equal(t, created[0].ID, documents[0].ID)
+	require.Len(t, created, 1)
+	require.Len(t, storedFiles(t, dir), 2)
+
+	// Тот же файл успели загрузить параллельно: возвращается прежний документ, новые файлы удаляются
+	attached = []models.ClaimDocument{}
+	repo.CreateDocumentsFn = func(_ int, documents []models.ClaimDocument, _, _ int) ([]models.ClaimDocument, error) {
+		existing := created[0]
+		existing.Duplicate = true
+		return []models.ClaimDocument{existing}, nil
+	}
+	documents, err = service.Upload(7, "RA123456789RU", "damage_photos", []ClaimUpload{claimUpload("race.jpg", photo)})
+	require.NoError(t, err)
+	require.True(t, documents[0].Duplicate)
+	require.Equal(t, created[0].ID, documents[0].ID)
+	require.Len(t, storedFiles(t, dir), 2)
+}
+
+func TestClaimDocumentService_UploadRejected(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n%%EOF\n")
+	gif := []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")
+	tests := []struct {
+		name           string
+		status         models.ClaimStatus
+		trackingNumber string
+		code           string
+		uploads        func(t *testing.T) []ClaimUpload
+		contentTypes   []string
+		createErr      error
+		wantStatus     int
+		wantAttr       string
+	}{
+		{
+			name:           "tracking number does not match",
+			trackingNumber: "RA000000000RU",
+			code:           "damage_photos",
+			uploads:        func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.jpg", photoWithGPS(t, 1))} },
+			wantStatus:     404,
+		},
+		{
+			name:       "claim is closed",
+			status:     models.ClaimClosed,
+			code:       "damage_photos",
+			uploads:    func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.jpg", photoWithGPS(t, 1))} },
+			wantStatus: 409,
+			wantAttr:   "status",
+		},
+		{
+			name:       "claim closed during upload",
+			code:       "damage_photos",
+			uploads:    func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.jpg", photoWithGPS(t, 1))} },
+			createErr:  &repository.ClaimStatusError{Status: models.ClaimClosed},
+			wantStatus: 409,
+			wantAttr:   "status",
+		},
+		{
+			name:       "code is not in the checklist",
+			code:       "selfie",
+			uploads:    func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.jpg", photoWithGPS(t, 1))} },
+			wantStatus: 400,
+			wantAttr:   "code",
+		},
+		{
+			name:       "pdf instead of photo",
+			code:       "damage_photos",
+			uploads:    func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("photo.jpg", pdf)} },
+			wantStatus: 415,
+			wantAttr:   "file",
+		},
+		{
+			name:         "image without metadata stripping",
+			code:         "damage_photos",
+			contentTypes: []string{"image/gif"},
+			uploads:      func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.gif", gif)} },
+			wantStatus:   415,
+			wantAttr:     "file",
+		},
+		{
+			name: "damaged photo",
+			code: "damage_photos",
+			uploads: func(t *testing.T) []ClaimUpload {
+				return []ClaimUpload{claimUpload("a.jpg", photoWithGPS(t, 1)[:200])}
+			},
+			wantStatus: 400,
+			wantAttr:   "file",
+		},
+		{
+			name: "too large",
+			code: "recipient_passport",
+			uploads: func(t *testing.T) []ClaimUpload {
+				return []ClaimUpload{claimUpload("a.pdf", append(pdf, make([]byte, 1<<20)...))}
+			},
+			wantStatus: 413,
+			wantAttr:   "file",
+		},
+		{
+			name: "too many files for requirement",
+			code: "damage_photos",
+			uploads: func(t *testing.T) []ClaimUpload {
+				return []ClaimUpload{claimUpload("b.jpg", photoWithGPS(t, 2)), claimUpload("c.jpg", photoWithGPS(t, 3))}
+			},
+			createErr:  &repository.ClaimDocumentLimitError{Code: "damage_photos", Limit: 2},
+			wantStatus: 400,
+			wantAttr:   "file",
+		},
+		{
+			name:       "too many files for claim",
+			code:       "recipient_passport",
+			uploads:    func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.pdf", pdf)} },
+			createErr:  &repository.ClaimDocumentLimitError{Limit: 3},
+			wantStatus: 400,
+			wantAttr:   "file",
+		},
+		{
+			name:       "database failure",
+			code:       "damage_photos",
+			uploads:    func(t *testing.T) []ClaimUpload { return []ClaimUpload{claimUpload("a.jpg", photoWithGPS(t, 1))} },
+			createErr:  stderrors.New("connection reset"),
+			wantStatus: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			local, err := storage.NewLocal(dir)
+			require.NoError(t, err)
+			status := tt.status
+			if status == "" {
+				status = models.ClaimAwaitingDocuments
+			}
+			repo := &mocks.ClaimRepoMock{
+				GetByIDFn: func(id int) (*models.Claim, error) {
+					return &models.Claim{ID: id, Kind: models.ClaimDamage, Status: status, TrackingNumber: "RA123456789RU"}, nil
+				},
+				ListDocumentsFn: func(int) ([]models.ClaimDocument, error) {
+					return []models.ClaimDocument{}, nil
+				},
+				CreateDocumentsFn: func(int, []models.ClaimDocument, int, int) ([]models.ClaimDocument, error) {
+					require.NotNil(t, tt.createErr, "rejected upload must not reach the repository")
+					return nil, tt.createErr
+				},
+			}
+			procedures := &mocks.ProcedureRepoMock{
+				GetByTypeFn: func(procedureType string, _ models.TagFilter) ([]models.Procedure, error) {
+					found := checklistProcedures()[procedureType]
+					for _, procedure := range found {
+						for _, item := range procedure.Content {
+							if item.Requirement != nil && tt.contentTypes != nil {
+								item.Requirement.ContentTypes = tt.contentTypes
+							}
+						}
+					}
+					return found, nil
+				},
+			}
+			service := NewClaimDocumentService(NewClaimService(repo, procedures), repo, local, testClaimDocumentLimits)
+			trackingNumber := tt.trackingNumber
+			if trackingNumber == "" {
+				trackingNumber = "RA123456789RU"
+			}
+
+			_, err = service.Upload(7, trackingNumber, tt.code, tt.uploads(t))
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr), "got %v", err)
+			require.Equal(t, tt.wantStatus, appErr.StatusCode)
+			require.Equal(t, tt.wantAttr, appErr.ErrorDetail[0].Attr)
+			require.Empty(t, storedFiles(t, dir))
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"unicode/utf8"
+)
+
+var (
+	// trackingNumberPattern — трек-номер после удаления пробелов и перевода в верхний регистр:
+	// международный S10 (RA123456789RU) или внутренний из цифр
+	trackingNumberPattern = regexp.MustCompile(`^[A-Z0-9]{8,30}$`)
+	phonePattern          = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+	currencyPattern       = regexp.MustCompile(`^[A-Z]{3}$`)
+	// phoneSeparators — символы, которые клиенты вставляют в телефон для читаемости
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+)
+
+const (
+	defaultClaimCurrency     = "RUB"
+	maxClaimantNameLength    = 255
+	maxClaimDescriptionChars = 5000
+	maxClaimReasonChars      = 1000
+)
+
+// claimTransitions — разрешённые переходы между статусами заявления. Из
+// awaiting_documents заявление возвращается на рассмотрение, когда документы получены;
+// closed — конечный статус.
+var claimTransitions = map[models.ClaimStatus][]models.ClaimStatus{
+	models.ClaimSubmitted:         {models.ClaimUnderReview, models.ClaimRejected},
+	models.ClaimUnderReview:       {models.ClaimAwaitingDocuments, models.ClaimApproved, models.ClaimRejected},
+	models.ClaimAwaitingDocuments: {models.ClaimUnderReview, models.ClaimApproved, models.ClaimRejected},
+	models.ClaimApproved:          {models.ClaimCompensated},
+	models.ClaimRejected:          {models.ClaimClosed},
+	models.ClaimCompensated:       {models.ClaimClosed},
+}
+
+// claimReasonRequired — статусы, переход в которые нужно объяснить клиенту
+var claimReasonRequired = map[models.ClaimStatus]bool{
+	models.ClaimAwaitingDocuments: true,
+	models.ClaimRejected:          true,
+}
+
+type ClaimService struct {
+	repo repository.ClaimRepos
+	// procedures — источник требований к документам заявления
+	procedures repository.ProcedureRepos
+}
+
+func NewClaimService(repo repository.ClaimRepos, procedures repository.ProcedureRepos) *ClaimService {
+	return &ClaimService{repo: repo, procedures: procedures}
+}
+
+// List возвращает страницу заявлений с фильтрами по виду и трек-номеру
+func (s *ClaimService) List(filter models.ClaimFilter) (*models.ClaimPage, error) {
+	if filter.Limit < 0 || filter.Limit > maxPageLimit {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("limit must be between 1 and %d", maxPageLimit),
+				Attr:   "limit",
+			},
+		)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Kind != "" && !filter.Kind.IsValid() {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "kind must be loss or damage",
+				Attr:   "kind",
+			},
+		)
+	}
+	filter.TrackingNumber = normalizeTrackingNumber(filter.TrackingNumber)
+	page, err := s.repo.List(filter)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get claims: " + err.Error(),
+			},
+		)
+	}
+	return page, nil
+}
+
+func (s *ClaimService) GetByID(id int) (*models.Claim, error) {
+	claim, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, claimNotFoundError()
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get claim: " + err.Error(),
+			},
+		)
+	}
+	return claim, nil
+}
+
+// Create проверяет и регистрирует новое заявление. Трек-номер, телефон и валюта
+// приводятся к единому виду, ошибки по всем полям возвращаются вместе.
+func (s *ClaimService) Create(claim *models.Claim) error {
+	normalizeClaim(claim)
+	if details := validateClaim(claim); len(details) > 0 {
+		return errors.NewError(400, details...)
+	}
+	if err := s.repo.Create(claim); err != nil {
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to create claim: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// Transition переводит заявление в новый статус, если переход разрешён, и
+// возвращает заявление с обновлённой историей. Для awaiting_documents и rejected
+// нужна причина: какие документы ожидаются или почему отказано. На рассмотрение
+// заявление уходит, только когда приложены все обязательные документы чек-листа.
+func (s *ClaimService) Transition(id int, transition models.ClaimTransition) (*models.Claim, error) {
+	to := transition.Status
+	reason := strings.TrimSpace(transition.Reason)
+	if !to.IsValid() {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("unknown status %q", to),
+				Attr:   "status",
+			},
+		)
+	}
+	if reason == "" && claimReasonRequired[to] {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("reason is required for status %s", to),
+				Attr:   "reason",
+			},
+		)
+	}
+	if utf8.RuneCountInString(reason) > maxClaimReasonChars {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("reason must be at most %d characters", maxClaimReasonChars),
+				Attr:   "reason",
+			},
+		)
+	}
+	current, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(claimTransitions[current.Status], to) {
+		return nil, errors.NewError(
+			409,
+			errors.ErrorDetail{
+				Code:   errors.InvalidTransitionCode,
+				Detail: invalidClaimTransitionDetail(current.Status, to),
+				Attr:   "status",
+			},
+		)
+	}
+	if to == models.ClaimUnderReview {
+		checklist, err := s.checklist(current)
+		if err != nil {
+			return nil, err
+		}
+		if len(checklist.Items) == 0 {
+			return nil, errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.MissingDocumentsCode,
+					Detail: fmt.Sprintf("no document requirements are published for %s claims", current.Kind),
+					Attr:   "documents",
+				},
+			)
+		}
+		if !checklist.Complete {
+			return nil, errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.MissingDocumentsCode,
+					Detail: "required documents are missing: " + strings.Join(checklist.Missing, ", "),
+					Attr:   "documents",
+				},
+			)
+		}
+	}
+	claim, err := s.repo.Transition(id, current.Status, to, reason)
+	if err != nil {
+		if err == errors.ErrConflict {
+			return nil, errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "claim status was changed concurrently",
+					Attr:   "status",
+				},
+			)
+		}
+		if err == errors.ErrNotFound {
+			return nil, claimNotFoundError()
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to change claim status: " + err.Error(),
+			},
+		)
+	}
+	return claim, nil
+}
+
+func invalidClaimTransitionDetail(from, to models.ClaimStatus) string {
+	allowed := claimTransitions[from]
+	if len(allowed) == 0 {
+		return fmt.Sprintf("transition from %s to %s is not allowed: %s is final", from, to, from)
+	}
+	names := make([]string, len(allowed))
+	for i, status := range allowed {
+		names[i] = string(status)
+	}
+	return fmt.Sprintf("transition from %s to %s is not allowed, allowed: %s", from, to, strings.Join(names, ", "))
+}
+
+func normalizeTrackingNumber(trackingNumber string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(trackingNumber), ""))
+}
+
+func normalizeClaim(claim *models.Claim) {
+	claim.TrackingNumber = normalizeTrackingNumber(claim.TrackingNumber)
+	claim.ClaimantName = strings.TrimSpace(claim.ClaimantName)
+	claim.ClaimantEmail = strings.TrimSpace(claim.ClaimantEmail)
+	claim.ClaimantPhone = phoneSeparators.Replace(strings.TrimSpace(claim.ClaimantPhone))
+	claim.Currency = strings.ToUpper(strings.TrimSpace(claim.Currency))
+	if claim.Currency == "" {
+		claim.Currency = defaultClaimCurrency
+	}
+	claim.Description = strings.TrimSpace(claim.Description)
+}
+
+// validateClaim возвращает ошибки по каждому неверному полю заявления
+func validateClaim(claim *models.Claim) []errors.ErrorDetail {
+	var details []errors.ErrorDetail
+	invalid := func(attr, detail string) {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: detail,
+			Attr:   attr,
+		})
+	}
+	if !trackingNumberPattern.MatchString(claim.TrackingNumber) {
+		invalid("tracking_number", "tracking_number must contain 8 to 30 latin letters and digits")
+	}
+	if !claim.Kind.IsValid() {
+		invalid("kind", "kind must be loss or damage")
+	}
+	switch {
+	case claim.ClaimantName == "":
+		invalid("claimant_name", "claimant_name is required")
+	case utf8.RuneCountInString(claim.ClaimantName) > maxClaimantNameLength:
+		invalid("claimant_name", fmt.Sprintf("claimant_name must be at most %d characters", maxClaimantNameLength))
+	}
+	if claim.ClaimantEmail == "" && claim.ClaimantPhone == "" {
+		invalid("claimant_email", "claimant_email or claimant_phone is required")
+	}
+	if claim.ClaimantEmail != "" && !validEmail(claim.ClaimantEmail) {
+		invalid("claimant_email", "claimant_email must be a valid email address")
+	}
+	if claim.ClaimantPhone != "" && !phonePattern.MatchString(claim.ClaimantPhone) {
+		invalid("claimant_phone", "claimant_phone must contain 10 to 15 digits")
+	}
+	if claim.DeclaredValue < 0 {
+		invalid("declared_value", "declared_value must not be negative")
+	}
+	if !currencyPattern.MatchString(claim.Currency) {
+		invalid("currency", "currency must be an ISO 4217 code, e.g. RUB")
+	}
+	switch {
+	case claim.Kind == models.ClaimDamage && claim.Description == "":
+		invalid("description", "description of the damage is required")
+	case utf8.RuneCountInString(claim.Description) > maxClaimDescriptionChars:
+		invalid("description", fmt.Sprintf("description must be at most %d characters", maxClaimDescriptionChars))
+	}
+	return details
+}
+
+// validEmail проверяет, что email — один адрес без отображаемого имени и помещается в колонку
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && len(email) <= 255
+}
+
+func claimNotFoundError() error {
+	return errors.NewError(
+		404,
+		errors.ErrorDetail{
+			Code:   errors.NotFoundCode,
+			Detail: "claim not found",
+		},
+	)
+}
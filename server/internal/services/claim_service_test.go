@@ -0,0 +1,426 @@
+package services
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestClaimService_Create(t *testing.T) {
+	valid := func() models.Claim {
+		return models.Claim{
+			TrackingNumber: "ra 1234 5678 9ru",
+			Kind:           models.ClaimLoss,
+			ClaimantName:   "Иванов Иван",
+			ClaimantPhone:  "+7 (912) 345-67-89",
+			DeclaredValue:  150000,
+		}
+	}
+	tests := []struct {
+		name      string
+		modify    func(*models.Claim)
+		wantAttrs []string
+	}{
+		{
+			name:   "loss without description",
+			modify: func(*models.Claim) {},
+		},
+		{
+			name: "damage with email only",
+			modify: func(c *models.Claim) {
+				c.Kind = models.ClaimDamage
+				c.ClaimantPhone = ""
+				c.ClaimantEmail = "ivanov@example.com"
+				c.Description = "Разбита крышка коробки"
+			},
+		},
+		{
+			name: "damage requires description",
+			modify: func(c *models.Claim) {
+				c.Kind = models.ClaimDamage
+			},
+			wantAttrs: []string{"description"},
+		},
+		{
+			name: "no contacts",
+			modify: func(c *models.Claim) {
+				c.ClaimantPhone = ""
+			},
+			wantAttrs: []string{"claimant_email"},
+		},
+		{
+			name: "every invalid field is reported",
+			modify: func(c *models.Claim) {
+				c.TrackingNumber = "RA-1"
+				c.Kind = "theft"
+				c.ClaimantName = " "
+				c.ClaimantEmail = "Иван <ivanov@example.com>"
+				c.ClaimantPhone = "12345"
+				c.DeclaredValue = -1
+				c.Currency = "rubles"
+			},
+			wantAttrs: []string{
+				"tracking_number", "kind", "claimant_name", "claimant_email",
+				"claimant_phone", "declared_value", "currency",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			repo := &mocks.ClaimRepoMock{
+				CreateFn: func(c *models.Claim) error {
+					created = true
+					c.ID = 1
+					c.Status = models.ClaimSubmitted
+					return nil
+				},
+			}
+			service := NewClaimService(repo, &mocks.ProcedureRepoMock{})
+			claim := valid()
+			tt.modify(&claim)
+
+			err := service.Create(&claim)
+
+			if len(tt.wantAttrs) > 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, 400, appErr.StatusCode)
+				attrs := make([]string, len(appErr.ErrorDetail))
+				for i, detail := range appErr.ErrorDetail {
+					attrs[i] = detail.Attr
+				}
+				require.Equal(t, tt.wantAttrs, attrs)
+				require.False(t, created)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, created)
+			require.Equal(t, "RA123456789RU", claim.TrackingNumber)
+			require.Equal(t, "RUB", claim.Currency)
+		})
+	}
+}
+
+func TestClaimService_CreateNormalizesPhone(t *testing.T) {
+	repo := &mocks.ClaimRepoMock{
+		CreateFn: func(*models.Claim) error { return nil },
+	}
+	claim := models.Claim{
+		TrackingNumber: "80085412345678",
+		Kind:           models.ClaimLoss,
+		ClaimantName:   "Иванов Иван",
+		ClaimantPhone:  "+7 (912) 345-67-89",
+		Currency:       "kzt",
+	}
+
+	require.NoError(t, NewClaimService(repo, &mocks.ProcedureRepoMock{}).Create(&claim))
+	require.Equal(t, "+79123456789", claim.ClaimantPhone)
+	require.Equal(t, "KZT", claim.Currency)
+}
+
+func TestClaimService_List(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     models.ClaimFilter
+		wantFilter models.ClaimFilter
+		wantStatus int
+	}{
+		{
+			name:       "defaults",
+			wantFilter: models.ClaimFilter{Limit: defaultPageLimit},
+		},
+		{
+			name:       "tracking number is normalized",
+			filter:     models.ClaimFilter{Limit: 10, Kind: models.ClaimDamage, TrackingNumber: "ra123456789ru"},
+			wantFilter: models.ClaimFilter{Limit: 10, Kind: models.ClaimDamage, TrackingNumber: "RA123456789RU"},
+		},
+		{
+			name:       "unknown kind",
+			filter:     models.ClaimFilter{Kind: "theft"},
+			wantStatus: 400,
+		},
+		{
+			name:       "limit too large",
+			filter:     models.ClaimFilter{Limit: maxPageLimit + 1},
+			wantStatus: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.ClaimFilter
+			repo := &mocks.ClaimRepoMock{
+				ListFn: func(filter models.ClaimFilter) (*models.ClaimPage, error) {
+					got = filter
+					return &models.ClaimPage{Items: []models.Claim{}}, nil
+				},
+			}
+			_, err := NewClaimService(repo, &mocks.ProcedureRepoMock{}).List(tt.filter)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFilter, got)
+		})
+	}
+}
+
+func TestClaimService_GetByID_NotFound(t *testing.T) {
+	repo := &mocks.ClaimRepoMock{
+		GetByIDFn: func(int) (*models.Claim, error) {
+			return nil, appErrors.ErrNotFound
+		},
+	}
+	_, err := NewClaimService(repo, &mocks.ProcedureRepoMock{}).GetByID(7)
+
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 404, appErr.StatusCode)
+}
+
+func TestClaimService_Transition(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       models.ClaimStatus
+		transition    models.ClaimTransition
+		repoErr       error
+		getErr        error
+		wantStatus    int
+		wantCode      string
+		wantAttr      string
+		wantRepoCalls int
+	}{
+		{
+			name:          "allowed transition",
+			current:       models.ClaimSubmitted,
+			transition:    models.ClaimTransition{Status: models.ClaimUnderReview},
+			wantRepoCalls: 1,
+		},
+		{
+			name:          "rejection with reason",
+			current:       models.ClaimUnderReview,
+			transition:    models.ClaimTransition{Status: models.ClaimRejected, Reason: " посылка вручена "},
+			wantRepoCalls: 1,
+		},
+		{
+			name:       "illegal transition",
+			current:    models.ClaimSubmitted,
+			transition: models.ClaimTransition{Status: models.ClaimCompensated},
+			wantStatus: 409,
+			wantCode:   appErrors.InvalidTransitionCode,
+			wantAttr:   "status",
+		},
+		{
+			name:       "closed is final",
+			current:    models.ClaimClosed,
+			transition: models.ClaimTransition{Status: models.ClaimUnderReview},
+			wantStatus: 409,
+			wantCode:   appErrors.InvalidTransitionCode,
+			wantAttr:   "status",
+		},
+		{
+			name:       "unknown status",
+			current:    models.ClaimSubmitted,
+			transition: models.ClaimTransition{Status: "lost"},
+			wantStatus: 400,
+			wantCode:   appErrors.ValidationErrorCode,
+			wantAttr:   "status",
+		},
+		{
+			name:       "rejection without reason",
+			current:    models.ClaimUnderReview,
+			transition: models.ClaimTransition{Status: models.ClaimRejected, Reason: "  "},
+			wantStatus: 400,
+			wantCode:   appErrors.ValidationErrorCode,
+			wantAttr:   "reason",
+		},
+		{
+			name:          "changed concurrently",
+			current:       models.ClaimSubmitted,
+			transition:    models.ClaimTransition{Status: models.ClaimUnderReview},
+			repoErr:       appErrors.ErrConflict,
+			wantStatus:    409,
+			wantCode:      appErrors.ConflictCode,
+			wantAttr:      "status",
+			wantRepoCalls: 1,
+		},
+		{
+			name:       "not found",
+			transition: models.ClaimTransition{Status: models.ClaimUnderReview},
+			getErr:     appErrors.ErrNotFound,
+			wantStatus: 404,
+			wantCode:   appErrors.NotFoundCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			repo := &mocks.ClaimRepoMock{
+				GetByIDFn: func(id int) (*models.Claim, error) {
+					if tt.getErr != nil {
+						return nil, tt.getErr
+					}
+					return &models.Claim{ID: id, Status: tt.current}, nil
+				},
+				ListDocumentsFn: func(int) ([]models.ClaimDocument, error) {
+					return []models.ClaimDocument{{ID: 10, Code: "recipient_passport"}}, nil
+				},
+				TransitionFn: func(id int, from, to models.ClaimStatus, reason string) (*models.Claim, error) {
+					calls++
+					require.Equal(t, tt.current, from)
+					require.Equal(t, tt.transition.Status, to)
+					require.Equal(t, reason, strings.TrimSpace(reason))
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.Claim{ID: id, Status: to}, nil
+				},
+			}
+			procedures := &mocks.ProcedureRepoMock{
+				GetByTypeFn: func(procedureType string, _ models.TagFilter) ([]models.Procedure, error) {
+					return checklistProcedures()[procedureType], nil
+				},
+			}
+			service := NewClaimService(repo, procedures)
+
+			claim, err := service.Transition(1, tt.transition)
+			require.Equal(t, tt.wantRepoCalls, calls)
+			if tt.wantStatus == 0 {
+				require.NoError(t, err)
+				require.Equal(t, tt.transition.Status, claim.Status)
+				return
+			}
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, tt.wantStatus, appErr.StatusCode)
+			require.Equal(t, tt.wantCode, appErr.ErrorDetail[0].Code)
+			require.Equal(t, tt.wantAttr, appErr.ErrorDetail[0].Attr)
+		})
+	}
+}
+
+func checklistProcedures() map[string][]models.Procedure {
+	return map[string][]models.Procedure{
+		"loss_or_damage_docs": {{
+			ID: 2,
+			Content: models.ProcedureContent{
+				{Type: models.ContentItemText, Value: "Подаются в отделении"},
+				{Type: models.ContentItemDocument, Value: "копия паспорта получателя", Requirement: &models.DocumentRequirement{
+					Code: "recipient_passport", Required: true,
+				}},
+				{Type: models.ContentItemDocument, Value: "подтверждение стоимости", Requirement: &models.DocumentRequirement{
+					Code: "value_confirmation",
+				}},
+			},
+		}},
+		"damage_additional_docs": {{
+			ID: 3,
+			Content: models.ProcedureContent{
+				{Type: models.ContentItemDocument, Value: "фотографии повреждений", Requirement: &models.DocumentRequirement{
+					Code: "damage_photos", Required: true, ClaimKinds: []models.ClaimKind{models.ClaimDamage},
+					ContentTypes: []string{"image/jpeg", "image/png"},
+				}},
+				{Type: models.ContentItemDocument, Value: "подтверждение стоимости", Requirement: &models.DocumentRequirement{
+					Code: "value_confirmation", Required: true, ClaimKinds: []models.ClaimKind{models.ClaimDamage},
+				}},
+			},
+		}},
+	}
+}
+
+func TestBuildClaimChecklist(t *testing.T) {
+	var procedures []models.Procedure
+	for _, procedureType := range claimChecklistTypes {
+		procedures = append(procedures, checklistProcedures()[procedureType]...)
+	}
+	documents := []models.ClaimDocument{
+		{ID: 10, Code: "recipient_passport"},
+		{ID: 11, Code: "damage_photos"},
+		{ID: 12, Code: "damage_photos"},
+	}
+
+	loss := BuildClaimChecklist(&models.Claim{ID: 1, Kind: models.ClaimLoss}, procedures, documents)
+	require.True(t, loss.Complete)
+	require.Empty(t, loss.Missing)
+	require.Equal(t, []models.ClaimChecklistItem{
+		{Code: "recipient_passport", Title: "копия паспорта получателя", Required: true, ProcedureID: 2, Satisfied: true, DocumentIDs: []int{10}},
+		{Code: "value_confirmation", Title: "подтверждение стоимости", ProcedureID: 2, DocumentIDs: []int{}},
+	}, loss.Items)
+
+	damage := BuildClaimChecklist(&models.Claim{ID: 1, Kind: models.ClaimDamage}, procedures, documents)
+	require.False(t, damage.Complete)
+	require.Equal(t, []string{"value_confirmation"}, damage.Missing)
+	require.Len(t, damage.Items, 3)
+	require.Equal(t, []int{11, 12}, damage.Items[2].DocumentIDs)
+	require.True(t, damage.Items[1].Required)
+
+	empty := BuildClaimChecklist(&models.Claim{ID: 1, Kind: models.ClaimLoss}, nil, documents)
+	require.False(t, empty.Complete)
+	require.Empty(t, empty.Items)
+}
+
+func TestClaimService_TransitionRequiresDocuments(t *testing.T) {
+	documents := []models.ClaimDocument{{ID: 10, Code: "recipient_passport"}}
+	transitioned := false
+	repo := &mocks.ClaimRepoMock{
+		GetByIDFn: func(id int) (*models.Claim, error) {
+			return &models.Claim{ID: id, Kind: models.ClaimDamage, Status: models.ClaimAwaitingDocuments}, nil
+		},
+		ListDocumentsFn: func(int) ([]models.ClaimDocument, error) {
+			return documents, nil
+		},
+		TransitionFn: func(id int, from, to models.ClaimStatus, reason string) (*models.Claim, error) {
+			transitioned = true
+			return &models.Claim{ID: id, Status: to}, nil
+		},
+	}
+	procedures := &mocks.ProcedureRepoMock{
+		GetByTypeFn: func(procedureType string, _ models.TagFilter) ([]models.Procedure, error) {
+			return checklistProcedures()[procedureType], nil
+		},
+	}
+	service := NewClaimService(repo, procedures)
+
+	_, err := service.Transition(1, models.ClaimTransition{Status: models.ClaimUnderReview})
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 409, appErr.StatusCode)
+	require.Equal(t, appErrors.MissingDocumentsCode, appErr.ErrorDetail[0].Code)
+	require.Contains(t, appErr.ErrorDetail[0].Detail, "value_confirmation, damage_photos")
+	require.False(t, transitioned)
+
+	// Отклонить заявление можно и без документов
+	_, err = service.Transition(1, models.ClaimTransition{Status: models.ClaimRejected, Reason: "нет документов"})
+	require.NoError(t, err)
+	require.True(t, transitioned)
+
+	transitioned = false
+	documents = append(documents,
+		models.ClaimDocument{ID: 11, Code: "damage_photos"},
+		models.ClaimDocument{ID: 12, Code: "value_confirmation"},
+	)
+	_, err = service.Transition(1, models.ClaimTransition{Status: models.ClaimUnderReview})
+	require.NoError(t, err)
+	require.True(t, transitioned)
+
+	// Без опубликованных требований рассмотрение не начинается
+	transitioned = false
+	procedures.GetByTypeFn = func(string, models.TagFilter) ([]models.Procedure, error) {
+		return nil, nil
+	}
+	_, err = service.Transition(1, models.ClaimTransition{Status: models.ClaimUnderReview})
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 409, appErr.StatusCode)
+	require.Equal(t, appErrors.MissingDocumentsCode, appErr.ErrorDetail[0].Code)
+	require.False(t, transitioned)
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"tech-quest/internal/domain/models"
+)
+
+type AttachmentRepoMock struct {
+	GetAllFn  func() ([]models.Attachment, error)
+	GetByIDFn func(int) (*models.Attachment, error)
+	CreateFn  func(*models.Attachment) error
+	DeleteFn  func(int) (*models.Attachment, error)
+}
+
+func (m *AttachmentRepoMock) GetAll() ([]models.Attachment, error) {
+	return m.GetAllFn()
+}
+
+func (m *AttachmentRepoMock) GetByID(id int) (*models.Attachment, error) {
+	return m.GetByIDFn(id)
+}
+
+func (m *AttachmentRepoMock) Create(a *models.Attachment) error {
+	return m.CreateFn(a)
+}
+
+func (m *AttachmentRepoMock) Delete(id int) (*models.Attachment, error) {
+	return m.DeleteFn(id)
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"tech-quest/internal/domain/models"
+)
+
+type ClaimRepoMock struct {
+	ListFn    func(models.ClaimFilter) (*models.ClaimPage, error)
+	GetByIDFn func(int) (*models.Claim, error)
+	CreateFn  func(*models.Claim) error
+
+	TransitionFn      func(int, models.ClaimStatus, models.ClaimStatus, string) (*models.Claim, error)
+	ListDocumentsFn   func(int) ([]models.ClaimDocument, error)
+	GetDocumentFn     func(int, int) (*models.ClaimDocument, error)
+	CreateDocumentsFn func(int, []models.ClaimDocument, int, int) ([]models.ClaimDocument, error)
+}
+
+func (m *ClaimRepoMock) List(filter models.ClaimFilter) (*models.ClaimPage, error) {
+	return m.ListFn(filter)
+}
+
+func (m *ClaimRepoMock) GetByID(id int) (*models.Claim, error) {
+	return m.GetByIDFn(id)
+}
+
+func (m *ClaimRepoMock) Create(c *models.Claim) error {
+	return m.CreateFn(c)
+}
+
+func (m *ClaimRepoMock) Transition(id int, from, to models.ClaimStatus, reason string) (*models.Claim, error) {
+	return m.TransitionFn(id, from, to, reason)
+}
+
+func (m *ClaimRepoMock) ListDocuments(claimID int) ([]models.ClaimDocument, error) {
+	return m.ListDocumentsFn(claimID)
+}
+
+func (m *ClaimRepoMock) GetDocument(claimID, id int) (*models.ClaimDocument, error) {
+	return m.GetDocumentFn(claimID, id)
+}
+
+func (m *ClaimRepoMock) CreateDocuments(claimID int, documents []models.ClaimDocument, maxPerCode, maxPerClaim int) ([]models.ClaimDocument, error) {
+	return m.CreateDocumentsFn(claimID, documents, maxPerCode, maxPerClaim)
+}
@@ -2,27 +2,55 @@ package mocks
 
 import (
 	"tech-quest/internal/domain/models"
+	"time"
 )
 
 type ProcedureRepoMock struct {
 	GetAllFn    func() ([]models.Procedure, error)
 	GetByIDFn   func(int) (*models.Procedure, error)
-	GetByTypeFn func(string) ([]models.Procedure, error)
+	GetByTypeFn func(string, models.TagFilter) ([]models.Procedure, error)
 	CreateFn    func(*models.Procedure) error
 	UpdateFn    func(*models.Procedure) error
-	DeleteFn    func(int) error
+	DeleteFn    func(int, int, models.ChildrenOnDelete) error
+	MoveFn      func(int, *int, int) (*models.Procedure, error)
+
+	GetAllAnyStatusFn func() ([]models.Procedure, error)
+	UpdateStatusFn    func(int, models.ProcedureStatus, models.ProcedureStatus) (*models.Procedure, error)
+	ApplyScheduleFn   func(time.Time) (int, error)
+	GetOrderFn        func(string) ([]models.ProcedureOrder, error)
+	ReorderFn         func([]int, string) ([]models.ProcedureOrder, error)
+	RestoreRevisionFn func(int, int) (*models.Procedure, error)
+	SearchFn          func(string, string, int) ([]models.SearchResult, error)
+	ListFn            func(models.ProcedureFilter) (*models.ProcedurePage, error)
+	PatchFn           func(*models.Procedure, []string) error
+
+	GetTrashFn           func() ([]models.Procedure, error)
+	RestoreFn            func(int) (*models.Procedure, error)
+	PurgeFn              func(int) error
+	PurgeDeletedBeforeFn func(time.Time) (int, error)
+	ImportFn             func([]models.Procedure, bool) ([]models.ImportRow, error)
+	SeedFn               func([]models.ProcedureFixture, bool) ([]models.SeedRow, error)
+	LastChangedFn        func() (time.Time, error)
 }
 
 func (m *ProcedureRepoMock) GetAll() ([]models.Procedure, error) {
 	return m.GetAllFn()
 }
 
+func (m *ProcedureRepoMock) List(filter models.ProcedureFilter) (*models.ProcedurePage, error) {
+	return m.ListFn(filter)
+}
+
+func (m *ProcedureRepoMock) Patch(procedure *models.Procedure, columns []string) error {
+	return m.PatchFn(procedure, columns)
+}
+
 func (m *ProcedureRepoMock) GetByID(id int) (*models.Procedure, error) {
 	return m.GetByIDFn(id)
 }
 
-func (m *ProcedureRepoMock) GetByType(t string) ([]models.Procedure, error) {
-	return m.GetByTypeFn(t)
+func (m *ProcedureRepoMock) GetByType(t string, tags models.TagFilter) ([]models.Procedure, error) {
+	return m.GetByTypeFn(t, tags)
 }
 
 func (m *ProcedureRepoMock) Create(p *models.Procedure) error {
@@ -33,6 +61,66 @@ func (m *ProcedureRepoMock) Update(p *models.Procedure) error {
 	return m.UpdateFn(p)
 }
 
-func (m *ProcedureRepoMock) Delete(id int) error {
-	return m.DeleteFn(id)
+func (m *ProcedureRepoMock) Delete(id, version int, children models.ChildrenOnDelete) error {
+	return m.DeleteFn(id, version, children)
+}
+
+func (m *ProcedureRepoMock) Move(id int, parentID *int, version int) (*models.Procedure, error) {
+	return m.MoveFn(id, parentID, version)
+}
+
+func (m *ProcedureRepoMock) GetTrash() ([]models.Procedure, error) {
+	return m.GetTrashFn()
+}
+
+func (m *ProcedureRepoMock) Restore(id int) (*models.Procedure, error) {
+	return m.RestoreFn(id)
+}
+
+func (m *ProcedureRepoMock) Purge(id int) error {
+	return m.PurgeFn(id)
+}
+
+func (m *ProcedureRepoMock) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	return m.PurgeDeletedBeforeFn(cutoff)
+}
+
+func (m *ProcedureRepoMock) Import(procedures []models.Procedure, dryRun bool) ([]models.ImportRow, error) {
+	return m.ImportFn(procedures, dryRun)
+}
+
+func (m *ProcedureRepoMock) Seed(fixtures []models.ProcedureFixture, dryRun bool) ([]models.SeedRow, error) {
+	return m.SeedFn(fixtures, dryRun)
+}
+
+func (m *ProcedureRepoMock) RestoreRevision(procedureID, revision int) (*models.Procedure, error) {
+	return m.RestoreRevisionFn(procedureID, revision)
+}
+
+func (m *ProcedureRepoMock) GetAllAnyStatus() ([]models.Procedure, error) {
+	return m.GetAllAnyStatusFn()
+}
+
+func (m *ProcedureRepoMock) UpdateStatus(id int, from, to models.ProcedureStatus) (*models.Procedure, error) {
+	return m.UpdateStatusFn(id, from, to)
+}
+
+func (m *ProcedureRepoMock) ApplySchedule(now time.Time) (int, error) {
+	return m.ApplyScheduleFn(now)
+}
+
+func (m *ProcedureRepoMock) GetOrder(procedureType string) ([]models.ProcedureOrder, error) {
+	return m.GetOrderFn(procedureType)
+}
+
+func (m *ProcedureRepoMock) Reorder(ids []int, procedureType string) ([]models.ProcedureOrder, error) {
+	return m.ReorderFn(ids, procedureType)
+}
+
+func (m *ProcedureRepoMock) Search(query, locale string, limit int) ([]models.SearchResult, error) {
+	return m.SearchFn(query, locale, limit)
+}
+
+func (m *ProcedureRepoMock) LastChanged() (time.Time, error) {
+	return m.LastChangedFn()
 }
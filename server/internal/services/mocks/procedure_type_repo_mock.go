@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"tech-quest/internal/domain/models"
+)
+
+type ProcedureTypeRepoMock struct {
+	GetAllFn    func() ([]models.ProcedureType, error)
+	GetBySlugFn func(string) (*models.ProcedureType, error)
+	CreateFn    func(*models.ProcedureType) error
+	UpdateFn    func(*models.ProcedureType) error
+	DeleteFn    func(string) error
+}
+
+func (m *ProcedureTypeRepoMock) GetAll() ([]models.ProcedureType, error) {
+	return m.GetAllFn()
+}
+
+func (m *ProcedureTypeRepoMock) GetBySlug(slug string) (*models.ProcedureType, error) {
+	return m.GetBySlugFn(slug)
+}
+
+func (m *ProcedureTypeRepoMock) Create(t *models.ProcedureType) error {
+	return m.CreateFn(t)
+}
+
+func (m *ProcedureTypeRepoMock) Update(t *models.ProcedureType) error {
+	return m.UpdateFn(t)
+}
+
+func (m *ProcedureTypeRepoMock) Delete(slug string) error {
+	return m.DeleteFn(slug)
+}
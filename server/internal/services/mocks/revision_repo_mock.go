@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"tech-quest/internal/domain/models"
+	"time"
+)
+
+type RevisionRepoMock struct {
+	GetByProcedureFn func(int) ([]models.ProcedureRevision, error)
+	GetFn            func(int, int) (*models.ProcedureRevision, error)
+	GetAllAsOfFn     func(time.Time) ([]models.Procedure, error)
+}
+
+func (m *RevisionRepoMock) GetByProcedure(procedureID int) ([]models.ProcedureRevision, error) {
+	return m.GetByProcedureFn(procedureID)
+}
+
+func (m *RevisionRepoMock) Get(procedureID, revision int) (*models.ProcedureRevision, error) {
+	return m.GetFn(procedureID, revision)
+}
+
+func (m *RevisionRepoMock) GetAllAsOf(asOf time.Time) ([]models.Procedure, error) {
+	return m.GetAllAsOfFn(asOf)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"time"
+
+	"tech-quest/internal/domain/models"
+)
+
+type SearchRequestRepoMock struct {
+	ListFn     func(models.SearchRequestFilter) (*models.SearchRequestPage, error)
+	GetByIDFn  func(int) (*models.SearchRequest, error)
+	CreateFn   func(*models.SearchRequest, time.Duration) error
+	ResolveFn  func(int, models.SearchRequestStatus, string) (*models.SearchRequest, error)
+	EscalateFn func(time.Time, time.Duration) ([]models.SearchRequest, error)
+}
+
+func (m *SearchRequestRepoMock) List(filter models.SearchRequestFilter) (*models.SearchRequestPage, error) {
+	return m.ListFn(filter)
+}
+
+func (m *SearchRequestRepoMock) GetByID(id int) (*models.SearchRequest, error) {
+	return m.GetByIDFn(id)
+}
+
+func (m *SearchRequestRepoMock) Create(r *models.SearchRequest, sla time.Duration) error {
+	return m.CreateFn(r, sla)
+}
+
+func (m *SearchRequestRepoMock) Resolve(id int, status models.SearchRequestStatus, resolution string) (*models.SearchRequest, error) {
+	return m.ResolveFn(id, status, resolution)
+}
+
+func (m *SearchRequestRepoMock) Escalate(now time.Time, interval time.Duration) ([]models.SearchRequest, error) {
+	return m.EscalateFn(now, interval)
+}
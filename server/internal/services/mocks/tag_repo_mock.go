@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"tech-quest/internal/domain/models"
+)
+
+type TagRepoMock struct {
+	GetAllFn    func() ([]models.Tag, error)
+	GetBySlugFn func(string) (*models.Tag, error)
+	CreateFn    func(*models.Tag) error
+	UpdateFn    func(*models.Tag) error
+	DeleteFn    func(string) error
+}
+
+func (m *TagRepoMock) GetAll() ([]models.Tag, error) {
+	return m.GetAllFn()
+}
+
+func (m *TagRepoMock) GetBySlug(slug string) (*models.Tag, error) {
+	return m.GetBySlugFn(slug)
+}
+
+func (m *TagRepoMock) Create(t *models.Tag) error {
+	return m.CreateFn(t)
+}
+
+func (m *TagRepoMock) Update(t *models.Tag) error {
+	return m.UpdateFn(t)
+}
+
+func (m *TagRepoMock) Delete(slug string) error {
+	return m.DeleteFn(slug)
+}
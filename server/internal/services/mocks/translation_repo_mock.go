@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"tech-quest/internal/domain/models"
+)
+
+type TranslationRepoMock struct {
+	GetByProcedureFn   func(int) ([]models.ProcedureTranslation, error)
+	GetFn              func(int, string) (*models.ProcedureTranslation, error)
+	GetForProceduresFn func([]int, []string) ([]models.ProcedureTranslation, error)
+	UpsertFn           func(*models.ProcedureTranslation) error
+	DeleteFn           func(int, string) error
+	GetMissingFn       func([]string) (map[string][]models.ProcedureRef, error)
+}
+
+func (m *TranslationRepoMock) GetByProcedure(procedureID int) ([]models.ProcedureTranslation, error) {
+	return m.GetByProcedureFn(procedureID)
+}
+
+func (m *TranslationRepoMock) Get(procedureID int, locale string) (*models.ProcedureTranslation, error) {
+	return m.GetFn(procedureID, locale)
+}
+
+func (m *TranslationRepoMock) GetForProcedures(ids []int, locales []string) ([]models.ProcedureTranslation, error) {
+	return m.GetForProceduresFn(ids, locales)
+}
+
+func (m *TranslationRepoMock) Upsert(t *models.ProcedureTranslation) error {
+	return m.UpsertFn(t)
+}
+
+func (m *TranslationRepoMock) Delete(procedureID int, locale string) error {
+	return m.DeleteFn(procedureID, locale)
+}
+
+func (m *TranslationRepoMock) GetMissing(locales []string) (map[string][]models.ProcedureRef, error) {
+	return m.GetMissingFn(locales)
+}
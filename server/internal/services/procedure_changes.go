@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/database"
+)
+
+// ProcedureChangeChannel — канал Postgres, в который триггеры пишут изменения процедур
+const ProcedureChangeChannel = "procedure_changes"
+
+// ProcedureChanges получает уведомления об изменениях процедур из Postgres и
+// рассылает их подписчикам этого экземпляра: кешам и производным индексам.
+// Так изменения, сделанные на любой реплике, видны всем остальным.
+type ProcedureChanges struct {
+	listener *database.Listener
+
+	mu          sync.RWMutex
+	subscribers []func(models.ProcedureChange)
+}
+
+func NewProcedureChanges(listener *database.Listener) *ProcedureChanges {
+	return &ProcedureChanges{listener: listener}
+}
+
+// Subscribe добавляет обработчик изменений. Обработчики вызываются последовательно
+// в горутине слушателя и не должны блокироваться.
+func (s *ProcedureChanges) Subscribe(fn func(change models.ProcedureChange)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Start начинает слушать канал в отдельной горутине до отмены ctx
+func (s *ProcedureChanges) Start(ctx context.Context) {
+	go s.listener.Run(ctx, s.handle, func() {
+		s.Publish(models.ProcedureChange{Op: models.ProcedureChangeResync})
+	})
+}
+
+// Publish рассылает изменение всем подписчикам
+func (s *ProcedureChanges) Publish(change models.ProcedureChange) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, fn := range s.subscribers {
+		fn(change)
+	}
+}
+
+func (s *ProcedureChanges) handle(payload string) {
+	var change models.ProcedureChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		log.Printf("procedure changes: invalid payload %q: %v", payload, err)
+		return
+	}
+	s.Publish(change)
+}
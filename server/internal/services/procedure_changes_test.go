@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+)
+
+func TestProcedureChanges_Handle(t *testing.T) {
+	changes := NewProcedureChanges(nil)
+	var first, second []models.ProcedureChange
+	changes.Subscribe(func(change models.ProcedureChange) { first = append(first, change) })
+	changes.Subscribe(func(change models.ProcedureChange) { second = append(second, change) })
+
+	changes.handle(`{"table":"procedures","op":"update","id":7}`)
+	changes.handle(`not json`)
+	changes.Publish(models.ProcedureChange{Op: models.ProcedureChangeResync})
+
+	want := []models.ProcedureChange{
+		{Table: "procedures", Op: models.ProcedureChangeUpdate, ID: 7},
+		{Op: models.ProcedureChangeResync},
+	}
+	require.Equal(t, want, first)
+	require.Equal(t, want, second)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"log"
+	"tech-quest/internal/repository"
+	"time"
+)
+
+// ProcedureScheduler периодически применяет publish_at/unpublish_at процедур
+// и окончательно удаляет процедуры, пролежавшие в корзине дольше trashRetention.
+// Нулевой trashRetention отключает очистку корзины.
+type ProcedureScheduler struct {
+	repo           repository.ProcedureRepos
+	interval       time.Duration
+	trashRetention time.Duration
+}
+
+func NewProcedureScheduler(repo repository.ProcedureRepos, interval, trashRetention time.Duration) *ProcedureScheduler {
+	return &ProcedureScheduler{repo: repo, interval: interval, trashRetention: trashRetention}
+}
+
+// Start запускает планировщик в отдельной горутине до отмены ctx
+func (s *ProcedureScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.RunOnce(time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.RunOnce(now)
+			}
+		}
+	}()
+}
+
+func (s *ProcedureScheduler) RunOnce(now time.Time) {
+	changed, err := s.repo.ApplySchedule(now.UTC())
+	if err != nil {
+		log.Printf("procedure scheduler: %v", err)
+	} else if changed > 0 {
+		log.Printf("procedure scheduler: %d procedures changed status", changed)
+	}
+
+	if s.trashRetention <= 0 {
+		return
+	}
+	purged, err := s.repo.PurgeDeletedBefore(now.UTC().Add(-s.trashRetention))
+	if err != nil {
+		log.Printf("procedure scheduler: failed to purge trash: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("procedure scheduler: %d procedures purged from trash", purged)
+	}
+}
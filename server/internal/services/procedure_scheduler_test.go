@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/services/mocks"
+)
+
+func TestProcedureScheduler_PurgesTrash(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	var cutoffs []time.Time
+	repo := &mocks.ProcedureRepoMock{
+		ApplyScheduleFn: func(time.Time) (int, error) {
+			return 0, nil
+		},
+		PurgeDeletedBeforeFn: func(cutoff time.Time) (int, error) {
+			cutoffs = append(cutoffs, cutoff)
+			return 1, nil
+		},
+	}
+
+	NewProcedureScheduler(repo, time.Minute, 48*time.Hour).RunOnce(now)
+	require.Equal(t, []time.Time{now.Add(-48 * time.Hour)}, cutoffs)
+
+	NewProcedureScheduler(repo, time.Minute, 0).RunOnce(now)
+	require.Len(t, cutoffs, 1)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/pkg/errors"
+)
+
+// Seed загружает фикстуры процедур из fsys: все файлы .yaml, .yml и .json
+// в порядке имён, в формате экспорта. Повторный запуск безопасен: неизменённые
+// фикстуры не трогают базу, а процедуры, которые после загрузки меняли редакторы,
+// не перезаписываются. Ошибки всех файлов возвращаются одним ответом с attr
+// вида <файл>[<индекс>].<поле>.
+func (s *ProcedureService) Seed(fsys fs.FS, dryRun bool) (*models.SeedReport, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		switch strings.ToLower(path.Ext(name)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to read fixtures: " + err.Error(),
+			},
+		)
+	}
+
+	var (
+		fixtures []models.ProcedureFixture
+		details  []errors.ErrorDetail
+	)
+	status := 422
+	seen := map[string]string{}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.NewError(
+				500,
+				errors.ErrorDetail{
+					Code:   errors.ServerErrorCode,
+					Detail: "failed to read fixture " + file + ": " + err.Error(),
+				},
+			)
+		}
+		var records []models.ProcedureRecord
+		if path.Ext(file) == ".json" {
+			records, err = decodeJSONRecords(data)
+		} else {
+			records, err = decodeYAMLRecords(data)
+		}
+		if err != nil {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ParseErrorCode,
+				Detail: fmt.Sprintf("invalid fixture: %v", err),
+				Attr:   file,
+			})
+			status = 400
+			continue
+		}
+
+		procedures, fileDetails, fileStatus := s.validateRecords(records, func(i int) string {
+			return fmt.Sprintf("%s[%d]", file, i)
+		}, seen)
+		// Seed не расставляет родителей, поэтому parent отклоняется, а не теряется молча
+		for i, procedure := range procedures {
+			if procedure.ParentSlug != "" {
+				fileDetails = append(fileDetails, errors.ErrorDetail{
+					Code:   errors.ValidationErrorCode,
+					Detail: "parent is not supported in fixtures",
+					Attr:   fmt.Sprintf("%s[%d].parent", file, i),
+				})
+				fileStatus = 400
+			}
+		}
+		if len(fileDetails) > 0 {
+			details = append(details, fileDetails...)
+			if fileStatus != 422 {
+				status = 400
+			}
+			continue
+		}
+		for i, procedure := range procedures {
+			checksum, err := fixtureChecksum(procedure)
+			if err != nil {
+				return nil, errors.NewError(
+					500,
+					errors.ErrorDetail{
+						Code:   errors.ServerErrorCode,
+						Detail: fmt.Sprintf("failed to hash fixture %s[%d]: %v", file, i, err),
+					},
+				)
+			}
+			fixtures = append(fixtures, models.ProcedureFixture{File: file, Procedure: procedure, Checksum: checksum})
+		}
+	}
+	if len(details) > 0 {
+		return nil, errors.NewError(status, details...)
+	}
+
+	rows, err := s.repo.Seed(fixtures, dryRun)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to seed procedures: " + err.Error(),
+			},
+		)
+	}
+	report := &models.SeedReport{DryRun: dryRun, Rows: rows}
+	for _, row := range rows {
+		switch row.Action {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		case models.ImportUnchanged:
+			report.Unchanged++
+		case models.ImportSkipped:
+			report.Skipped++
+		}
+	}
+	return report, nil
+}
+
+// fixtureChecksum — хеш фикстуры после нормализации, чтобы одинаковое
+// содержимое в разной записи (YAML или JSON, часовой пояс) давало один хеш
+func fixtureChecksum(procedure models.Procedure) (string, error) {
+	data, err := json.Marshal(models.NewProcedureRecord(procedure))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	stderrors "errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/fixtures"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestProcedureService_Seed(t *testing.T) {
+	var seeded []models.ProcedureFixture
+	repo := &mocks.ProcedureRepoMock{
+		SeedFn: func(fixtures []models.ProcedureFixture, dryRun bool) ([]models.SeedRow, error) {
+			seeded = fixtures
+			rows := make([]models.SeedRow, len(fixtures))
+			for i, fixture := range fixtures {
+				rows[i] = models.SeedRow{File: fixture.File, Slug: fixture.Procedure.Slug, Action: models.ImportSkipped}
+			}
+			return rows, nil
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock(
+		"search_without_status", "loss_or_damage_docs", "damage_additional_docs",
+		"loss_procedure", "damage_procedure", "recipient_info",
+	))
+
+	embedded, err := fs.Sub(fixtures.Procedures, "procedures")
+	require.NoError(t, err)
+	report, err := service.Seed(embedded, false)
+	require.NoError(t, err)
+	require.Equal(t, 6, report.Skipped)
+	require.Len(t, seeded, 6)
+
+	// Одинаковое содержимое в YAML и JSON даёт одну контрольную сумму
+	same := fstest.MapFS{
+		"a.yaml": {Data: []byte("- {slug: a, title: A, type: loss_procedure, status: published}\n")},
+		"b.json": {Data: []byte(`[{"slug": "b", "title": "A", "type": "loss_procedure", "status": "published"}]`)},
+	}
+	_, err = service.Seed(same, true)
+	require.NoError(t, err)
+	require.Len(t, seeded, 2)
+	seeded[1].Procedure.Slug = "a"
+	checksum, err := fixtureChecksum(seeded[1].Procedure)
+	require.NoError(t, err)
+	require.Equal(t, seeded[0].Checksum, checksum)
+}
+
+func TestProcedureService_SeedValidation(t *testing.T) {
+	repo := &mocks.ProcedureRepoMock{
+		SeedFn: func([]models.ProcedureFixture, bool) ([]models.SeedRow, error) {
+			t.Fatal("invalid fixtures must not reach the repository")
+			return nil, nil
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+	_, err := service.Seed(fstest.MapFS{
+		"01.yaml":   {Data: []byte("- {slug: a, title: A, type: manual}\n")},
+		"02.json":   {Data: []byte(`[{"slug": "a", "title": "A", "type": "manual"}, {"slug": "b", "type": "manual"}]`)},
+		"03.yml":    {Data: []byte("slug: [")},
+		"README.md": {Data: []byte("not a fixture")},
+	}, false)
+
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 400, appErr.StatusCode)
+	attrs := make([]string, 0, len(appErr.ErrorDetail))
+	for _, detail := range appErr.ErrorDetail {
+		attrs = append(attrs, detail.Attr)
+	}
+	require.Equal(t, []string{"02.json[0].slug", "02.json[1].title", "03.yml"}, attrs)
+}
@@ -1,17 +1,30 @@
 package services
 
 import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"tech-quest/internal/domain/models"
 	"tech-quest/internal/repository"
 	"tech-quest/pkg/errors"
+	"tech-quest/pkg/jsonpatch"
+	"time"
 )
 
 type ProcedureService struct {
-	repo repository.ProcedureRepos
+	repo  repository.ProcedureRepos
+	types repository.ProcedureTypeRepos
 }
 
-func NewProcedureService(repo repository.ProcedureRepos) *ProcedureService {
-	return &ProcedureService{repo: repo}
+func NewProcedureService(repo repository.ProcedureRepos, types repository.ProcedureTypeRepos) *ProcedureService {
+	return &ProcedureService{repo: repo, types: types}
 }
 
 func (s *ProcedureService) GetAll() ([]models.Procedure, error) {
@@ -28,7 +41,114 @@ func (s *ProcedureService) GetAll() ([]models.Procedure, error) {
 	return procedures, nil
 }
 
+// LastModified возвращает время последнего изменения процедур — Last-Modified их списков
+func (s *ProcedureService) LastModified() (time.Time, error) {
+	changed, err := s.repo.LastChanged()
+	if err != nil {
+		return time.Time{}, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedures modification time: " + err.Error(),
+			},
+		)
+	}
+	return changed, nil
+}
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// List возвращает страницу опубликованных процедур с фильтрами и сортировкой
+func (s *ProcedureService) List(filter models.ProcedureFilter) (*models.ProcedurePage, error) {
+	if filter.Limit < 0 || filter.Limit > maxPageLimit {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("limit must be between 1 and %d", maxPageLimit),
+				Attr:   "limit",
+			},
+		)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Sort == "" {
+		filter.Sort = "sort_order"
+	}
+	if !slices.Contains(models.ProcedureSortFields, strings.TrimPrefix(filter.Sort, "-")) {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("sort must be one of %s, optionally prefixed with \"-\"", strings.Join(models.ProcedureSortFields, ", ")),
+				Attr:   "sort",
+			},
+		)
+	}
+	if err := normalizeTagFilter(&filter.Tags); err != nil {
+		return nil, err
+	}
+	page, err := s.repo.List(filter)
+	if err != nil {
+		if err == repository.ErrInvalidCursor {
+			return nil, errors.NewError(
+				400,
+				errors.ErrorDetail{
+					Code:   errors.ValidationErrorCode,
+					Detail: "invalid cursor for this sort order",
+					Attr:   "after",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedures: " + err.Error(),
+			},
+		)
+	}
+	return page, nil
+}
+
 func (s *ProcedureService) GetByID(id int) (*models.Procedure, error) {
+	procedure, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure: " + err.Error(),
+			},
+		)
+	}
+	if procedure.Status != models.ProcedurePublished {
+		return nil, errors.NewError(
+			404,
+			errors.ErrorDetail{
+				Code:   errors.NotFoundCode,
+				Detail: "procedure not found",
+			},
+		)
+	}
+	return procedure, nil
+}
+
+// Preview возвращает процедуру в любом статусе для редакторов
+func (s *ProcedureService) Preview(id int) (*models.Procedure, error) {
 	procedure, err := s.repo.GetByID(id)
 	if err != nil {
 		if err == errors.ErrNotFound {
@@ -51,8 +171,30 @@ func (s *ProcedureService) GetByID(id int) (*models.Procedure, error) {
 	return procedure, nil
 }
 
-func (s *ProcedureService) GetByType(procedureType string) ([]models.Procedure, error) {
-	procedures, err := s.repo.GetByType(procedureType)
+// PreviewAll возвращает процедуры во всех статусах для редакторов
+func (s *ProcedureService) PreviewAll() ([]models.Procedure, error) {
+	procedures, err := s.repo.GetAllAnyStatus()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedures: " + err.Error(),
+			},
+		)
+	}
+	return procedures, nil
+}
+
+// GetByType возвращает опубликованные процедуры типа, отобранные по тегам
+func (s *ProcedureService) GetByType(procedureType string, tags models.TagFilter) ([]models.Procedure, error) {
+	if err := checkProcedureType(s.types, procedureType, 404); err != nil {
+		return nil, err
+	}
+	if err := normalizeTagFilter(&tags); err != nil {
+		return nil, err
+	}
+	procedures, err := s.repo.GetByType(procedureType, tags)
 	if err != nil {
 		return nil, errors.NewError(
 			500,
@@ -65,29 +207,94 @@ func (s *ProcedureService) GetByType(procedureType string) ([]models.Procedure,
 	return procedures, nil
 }
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+// Search выполняет полнотекстовый поиск по опубликованным процедурам.
+// Совпадения ищутся в исходном тексте и в переводе на locale.
+func (s *ProcedureService) Search(query, locale string, limit int) ([]models.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "q is required",
+				Attr:   "q",
+			},
+		)
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit),
+				Attr:   "limit",
+			},
+		)
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	results, err := s.repo.Search(query, locale, limit)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to search procedures: " + err.Error(),
+			},
+		)
+	}
+	return results, nil
+}
+
 func (s *ProcedureService) Create(procedure *models.Procedure) error {
-	if procedure.Title == "" {
+	if err := s.validate(procedure); err != nil {
+		return err
+	}
+	if procedure.Status == "" {
+		procedure.Status = models.ProcedureDraft
+	}
+	if procedure.Status != models.ProcedureDraft && procedure.Status != models.ProcedureInReview {
 		return errors.NewError(
 			400,
 			errors.ErrorDetail{
 				Code:   errors.ValidationErrorCode,
-				Detail: "title is required",
-				Attr:   "title",
+				Detail: "new procedure must be created as draft or in_review",
+				Attr:   "status",
 			},
 		)
 	}
-	if procedure.Type == "" {
+	if procedure.ParentID != nil && *procedure.ParentID <= 0 {
 		return errors.NewError(
 			400,
 			errors.ErrorDetail{
 				Code:   errors.ValidationErrorCode,
-				Detail: "type is required",
-				Attr:   "type",
+				Detail: "parent_id must be a positive procedure id",
+				Attr:   "parent_id",
 			},
 		)
 	}
 	err := s.repo.Create(procedure)
 	if err != nil {
+		if err == repository.ErrSlugTaken {
+			return slugTakenError(procedure.Slug)
+		}
+		if hierarchyErr := hierarchyError(err); hierarchyErr != nil {
+			return hierarchyErr
+		}
+		var unknown *repository.UnknownTagsError
+		if stderrors.As(err, &unknown) {
+			return unknownTagsError(unknown)
+		}
+		var missing *repository.UnknownAttachmentsError
+		if stderrors.As(err, &missing) {
+			return unknownAttachmentsError(missing)
+		}
 		return errors.NewError(
 			500,
 			errors.ErrorDetail{
@@ -110,20 +317,147 @@ func (s *ProcedureService) Update(procedure *models.Procedure) error {
 			},
 		)
 	}
-	if procedure.Title == "" {
+	if err := s.validate(procedure); err != nil {
+		return err
+	}
+	err := s.repo.Update(procedure)
+	if err != nil {
+		if err == repository.ErrSlugTaken {
+			return slugTakenError(procedure.Slug)
+		}
+		var unknown *repository.UnknownTagsError
+		if stderrors.As(err, &unknown) {
+			return unknownTagsError(unknown)
+		}
+		var missing *repository.UnknownAttachmentsError
+		if stderrors.As(err, &missing) {
+			return unknownAttachmentsError(missing)
+		}
+		if err == errors.ErrPreconditionFailed {
+			return preconditionFailedError()
+		}
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found",
+				},
+			)
+		}
 		return errors.NewError(
-			400,
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to update procedure: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// Patch применяет к сохранённой процедуре JSON Merge Patch (RFC 7396) или
+// JSON Patch (RFC 6902), проверяет результат теми же правилами, что и Update,
+// и записывает только изменившиеся колонки. Ненулевая version должна совпасть с текущей.
+func (s *ProcedureService) Patch(id, version int, contentType string, patch []byte) (*models.Procedure, error) {
+	current, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure: " + err.Error(),
+			},
+		)
+	}
+	if version != 0 && version != current.Version {
+		return nil, preconditionFailedError()
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to encode procedure: " + err.Error(),
+			},
+		)
+	}
+	switch contentType {
+	case jsonpatch.MergePatchContentType:
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case jsonpatch.JSONPatchContentType:
+		doc, err = jsonpatch.Apply(doc, patch)
+	default:
+		return nil, errors.NewError(
+			415,
 			errors.ErrorDetail{
 				Code:   errors.ValidationErrorCode,
-				Detail: "title is required",
-				Attr:   "title",
+				Detail: fmt.Sprintf("unsupported patch content type %q", contentType),
 			},
 		)
 	}
-	err := s.repo.Update(procedure)
 	if err != nil {
+		return nil, patchError(err)
+	}
+
+	var patched models.Procedure
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "patched procedure is invalid: " + err.Error(),
+			},
+		)
+	}
+	if detail := checkReadOnlyFields(current, &patched); detail != nil {
+		return nil, errors.NewError(400, *detail)
+	}
+	// Как и в Update, пустой slug означает прежний; удалённый список тегов — пустой
+	if patched.Slug == "" {
+		patched.Slug = current.Slug
+	}
+	if patched.Tags == nil {
+		patched.Tags = []string{}
+	}
+	if err := s.validate(&patched); err != nil {
+		return nil, err
+	}
+
+	columns := changedColumns(current, &patched)
+	if len(columns) == 0 {
+		return current, nil
+	}
+	if err := s.repo.Patch(&patched, columns); err != nil {
+		if err == repository.ErrSlugTaken {
+			return nil, slugTakenError(patched.Slug)
+		}
+		var unknown *repository.UnknownTagsError
+		if stderrors.As(err, &unknown) {
+			return nil, unknownTagsError(unknown)
+		}
+		var missing *repository.UnknownAttachmentsError
+		if stderrors.As(err, &missing) {
+			return nil, unknownAttachmentsError(missing)
+		}
+		if err == errors.ErrPreconditionFailed {
+			return nil, preconditionFailedError()
+		}
 		if err == errors.ErrNotFound {
-			return errors.NewError(
+			return nil, errors.NewError(
 				404,
 				errors.ErrorDetail{
 					Code:   errors.NotFoundCode,
@@ -131,18 +465,68 @@ func (s *ProcedureService) Update(procedure *models.Procedure) error {
 				},
 			)
 		}
-		return errors.NewError(
+		return nil, errors.NewError(
 			500,
 			errors.ErrorDetail{
 				Code:   errors.ServerErrorCode,
-				Detail: "failed to update procedure: " + err.Error(),
+				Detail: "failed to patch procedure: " + err.Error(),
+			},
+		)
+	}
+	return &patched, nil
+}
+
+// validate проверяет редактируемые поля процедуры
+func (s *ProcedureService) validate(procedure *models.Procedure) error {
+	if procedure.Slug != "" && (len(procedure.Slug) > 100 || !procedureSlugPattern.MatchString(procedure.Slug)) {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "slug must contain only lowercase latin letters, digits, '-' and '_' and be at most 100 characters",
+				Attr:   "slug",
+			},
+		)
+	}
+	if procedure.Title == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "title is required",
+				Attr:   "title",
+			},
+		)
+	}
+	if procedure.Type == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "type is required",
+				Attr:   "type",
 			},
 		)
 	}
+	if err := checkProcedureType(s.types, procedure.Type, 422); err != nil {
+		return err
+	}
+	if details := validateContent(procedure.Content); len(details) > 0 {
+		return errors.NewError(400, details...)
+	}
+	normalizeSchedule(procedure)
+	if detail := validateSchedule(procedure); detail != nil {
+		return errors.NewError(400, *detail)
+	}
+	if detail := normalizeTags(procedure); detail != nil {
+		return errors.NewError(400, *detail)
+	}
 	return nil
 }
 
-func (s *ProcedureService) Delete(id int) error {
+// Delete удаляет процедуру. Ненулевая version должна совпасть с текущей версией.
+// children определяет судьбу подпроцедур; по умолчанию удаление с ними запрещено.
+func (s *ProcedureService) Delete(id, version int, children models.ChildrenOnDelete) error {
 	if id == 0 {
 		return errors.NewError(
 			400,
@@ -153,9 +537,35 @@ func (s *ProcedureService) Delete(id int) error {
 			},
 		)
 	}
+	if children == "" {
+		children = models.ChildrenReject
+	}
+	if !children.IsValid() {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("unknown children mode %q, expected reject, cascade or reparent", children),
+				Attr:   "children",
+			},
+		)
+	}
 
-	err := s.repo.Delete(id)
+	err := s.repo.Delete(id, version, children)
 	if err != nil {
+		if err == errors.ErrPreconditionFailed {
+			return preconditionFailedError()
+		}
+		if err == repository.ErrHasChildren {
+			return errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "procedure has children, delete them with children=cascade or move them up with children=reparent",
+					Attr:   "children",
+				},
+			)
+		}
 		if err == errors.ErrNotFound {
 			return errors.NewError(
 				404,
@@ -175,3 +585,741 @@ func (s *ProcedureService) Delete(id int) error {
 	}
 	return nil
 }
+
+// Trash возвращает процедуры в корзине
+func (s *ProcedureService) Trash() ([]models.Procedure, error) {
+	procedures, err := s.repo.GetTrash()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get trash: " + err.Error(),
+			},
+		)
+	}
+	return procedures, nil
+}
+
+// Restore возвращает процедуру из корзины
+func (s *ProcedureService) Restore(id int) (*models.Procedure, error) {
+	procedure, err := s.repo.Restore(id)
+	if err != nil {
+		if err == repository.ErrParentDeleted {
+			return nil, errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "parent procedure is in trash, restore it first",
+					Attr:   "parent_id",
+				},
+			)
+		}
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found in trash",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to restore procedure: " + err.Error(),
+			},
+		)
+	}
+	return procedure, nil
+}
+
+// Purge окончательно удаляет процедуру из корзины
+func (s *ProcedureService) Purge(id int) error {
+	err := s.repo.Purge(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found in trash",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to purge procedure: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// Move переносит процедуру вместе с подпроцедурами к другому родителю;
+// parentID == nil переносит её на верхний уровень. Ненулевая version должна
+// совпасть с текущей, перенос к прежнему родителю ничего не меняет.
+func (s *ProcedureService) Move(id, version int, parentID *int) (*models.Procedure, error) {
+	if parentID != nil && *parentID == id {
+		return nil, hierarchyError(repository.ErrParentCycle)
+	}
+	current, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure: " + err.Error(),
+			},
+		)
+	}
+	if version != 0 && version != current.Version {
+		return nil, preconditionFailedError()
+	}
+	if sameParent(current.ParentID, parentID) {
+		return current, nil
+	}
+	procedure, err := s.repo.Move(id, parentID, version)
+	if err != nil {
+		if hierarchyErr := hierarchyError(err); hierarchyErr != nil {
+			return nil, hierarchyErr
+		}
+		if err == errors.ErrPreconditionFailed {
+			return nil, preconditionFailedError()
+		}
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to move procedure: " + err.Error(),
+			},
+		)
+	}
+	return procedure, nil
+}
+
+// BuildProcedureTree собирает дерево из списка процедур, упорядоченного по sort_order.
+// Процедуры, родителя которых нет в списке (например, он не опубликован), в дерево не попадают.
+func BuildProcedureTree(procedures []models.Procedure) []models.ProcedureNode {
+	present := make(map[int]bool, len(procedures))
+	for _, procedure := range procedures {
+		present[procedure.ID] = true
+	}
+	children := make(map[int][]models.Procedure)
+	var roots []models.Procedure
+	for _, procedure := range procedures {
+		switch {
+		case procedure.ParentID == nil:
+			roots = append(roots, procedure)
+		case present[*procedure.ParentID]:
+			children[*procedure.ParentID] = append(children[*procedure.ParentID], procedure)
+		}
+	}
+	var build func(level []models.Procedure) []models.ProcedureNode
+	build = func(level []models.Procedure) []models.ProcedureNode {
+		nodes := make([]models.ProcedureNode, len(level))
+		for i, procedure := range level {
+			nodes[i] = models.ProcedureNode{Procedure: procedure, Children: build(children[procedure.ID])}
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// hierarchyError переводит ошибки проверки дерева процедур в ответ API;
+// для остальных ошибок возвращает nil
+func hierarchyError(err error) error {
+	var depth *repository.DepthLimitError
+	switch {
+	case err == repository.ErrParentNotFound:
+		return errors.NewError(
+			422,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "parent procedure not found",
+				Attr:   "parent_id",
+			},
+		)
+	case err == repository.ErrParentCycle:
+		return errors.NewError(
+			422,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "procedure cannot be moved under itself or its descendant",
+				Attr:   "parent_id",
+			},
+		)
+	case stderrors.As(err, &depth):
+		return errors.NewError(
+			422,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("procedure tree would be %d levels deep, at most %d allowed", depth.Depth, depth.MaxDepth),
+				Attr:   "parent_id",
+			},
+		)
+	}
+	return nil
+}
+
+// Transition переводит процедуру в новый статус, если переход разрешён
+func (s *ProcedureService) Transition(id int, to models.ProcedureStatus) (*models.Procedure, error) {
+	if !to.IsValid() {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("unknown status %q", to),
+				Attr:   "status",
+			},
+		)
+	}
+	current, err := s.Preview(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransition(current.Status, to) {
+		return nil, errors.NewError(
+			409,
+			errors.ErrorDetail{
+				Code:   errors.InvalidTransitionCode,
+				Detail: fmt.Sprintf("transition from %s to %s is not allowed", current.Status, to),
+				Attr:   "status",
+			},
+		)
+	}
+	procedure, err := s.repo.UpdateStatus(id, current.Status, to)
+	if err != nil {
+		if err == errors.ErrConflict {
+			return nil, errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "procedure status was changed concurrently",
+					Attr:   "status",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to change procedure status: " + err.Error(),
+			},
+		)
+	}
+	return procedure, nil
+}
+
+// Reorder меняет порядок процедур списком IDs или перемещением одной процедуры
+func (s *ProcedureService) Reorder(req *models.ReorderRequest) ([]models.ProcedureOrder, error) {
+	if (req.Move == nil) == (len(req.IDs) == 0) {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "either ids or move must be provided",
+			},
+		)
+	}
+	if req.Type != "" {
+		if err := checkProcedureType(s.types, req.Type, 422); err != nil {
+			return nil, err
+		}
+	}
+	ids := req.IDs
+	if req.Move != nil {
+		var err error
+		if ids, err = s.applyMove(req.Move, req.Type); err != nil {
+			return nil, err
+		}
+	}
+	order, err := s.repo.Reorder(ids, req.Type)
+	if err != nil {
+		var mismatch *repository.ReorderMismatchError
+		if stderrors.As(err, &mismatch) {
+			return nil, reorderMismatchError(mismatch)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to reorder procedures: " + err.Error(),
+			},
+		)
+	}
+	return order, nil
+}
+
+// applyMove строит новый порядок из текущего, перемещая move.ID перед или после опорной процедуры
+func (s *ProcedureService) applyMove(move *models.MoveOperation, procedureType string) ([]int, error) {
+	anchor, after := move.Before, false
+	if move.After != 0 {
+		anchor, after = move.After, true
+	}
+	if (move.Before == 0) == (move.After == 0) || move.ID == 0 || move.ID == anchor {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "move requires id and exactly one of before or after referencing another procedure",
+				Attr:   "move",
+			},
+		)
+	}
+	current, err := s.repo.GetOrder(procedureType)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure order: " + err.Error(),
+			},
+		)
+	}
+	ids := make([]int, 0, len(current))
+	found := false
+	for _, row := range current {
+		if row.ID == move.ID {
+			found = true
+			continue
+		}
+		ids = append(ids, row.ID)
+	}
+	position := -1
+	for i, id := range ids {
+		if id == anchor {
+			position = i
+			break
+		}
+	}
+	var details []errors.ErrorDetail
+	if !found {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("procedure %d is not in the reorder scope", move.ID),
+			Attr:   "move.id",
+		})
+	}
+	if position < 0 {
+		attr := "move.before"
+		if after {
+			attr = "move.after"
+		}
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("procedure %d is not in the reorder scope", anchor),
+			Attr:   attr,
+		})
+	}
+	if len(details) > 0 {
+		return nil, errors.NewError(422, details...)
+	}
+	if after {
+		position++
+	}
+	ids = append(ids[:position], append([]int{move.ID}, ids[position:]...)...)
+	return ids, nil
+}
+
+func reorderMismatchError(mismatch *repository.ReorderMismatchError) error {
+	var details []errors.ErrorDetail
+	if len(mismatch.Unknown) > 0 {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("unknown procedure ids: %s", joinInts(mismatch.Unknown)),
+			Attr:   "ids",
+		})
+	}
+	if len(mismatch.Missing) > 0 {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("missing procedure ids: %s", joinInts(mismatch.Missing)),
+			Attr:   "ids",
+		})
+	}
+	if len(mismatch.Duplicate) > 0 {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("duplicate procedure ids: %s", joinInts(mismatch.Duplicate)),
+			Attr:   "ids",
+		})
+	}
+	return errors.NewError(422, details...)
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// slugTakenError сообщает, что slug занят другой процедурой
+func slugTakenError(slug string) error {
+	return errors.NewError(
+		409,
+		errors.ErrorDetail{
+			Code:   errors.ConflictCode,
+			Detail: fmt.Sprintf("slug %q is already taken", slug),
+			Attr:   "slug",
+		},
+	)
+}
+
+// preconditionFailedError сообщает, что версия из If-Match устарела
+func preconditionFailedError() error {
+	return errors.NewError(
+		412,
+		errors.ErrorDetail{
+			Code:   errors.PreconditionFailedCode,
+			Detail: "procedure was modified by another request, reload it and retry",
+		},
+	)
+}
+
+// unknownTagsError сообщает о тегах процедуры, которых нет в справочнике
+func unknownTagsError(unknown *repository.UnknownTagsError) error {
+	return errors.NewError(
+		422,
+		errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("unknown tags: %s", strings.Join(unknown.Tags, ", ")),
+			Attr:   "tags",
+		},
+	)
+}
+
+// patchError переводит ошибки применения патча в ответы API
+func patchError(err error) error {
+	switch {
+	case stderrors.Is(err, jsonpatch.ErrTestFailed):
+		return errors.NewError(
+			409,
+			errors.ErrorDetail{
+				Code:   errors.ConflictCode,
+				Detail: err.Error(),
+			},
+		)
+	case stderrors.Is(err, jsonpatch.ErrPathNotFound):
+		return errors.NewError(
+			422,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: err.Error(),
+			},
+		)
+	default:
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: err.Error(),
+			},
+		)
+	}
+}
+
+// checkReadOnlyFields запрещает менять через патч служебные поля.
+// Статус меняется только через POST /procedures/:id/status,
+// родитель — через POST /procedures/:id/move.
+func checkReadOnlyFields(current, patched *models.Procedure) *errors.ErrorDetail {
+	var field string
+	switch {
+	case patched.ID != current.ID:
+		field = "id"
+	case patched.Status != current.Status:
+		field = "status"
+	case !sameParent(patched.ParentID, current.ParentID):
+		field = "parent_id"
+	case patched.Version != current.Version:
+		field = "version"
+	case !patched.CreatedAt.Equal(current.CreatedAt):
+		field = "created_at"
+	case !patched.UpdatedAt.Equal(current.UpdatedAt):
+		field = "updated_at"
+	case patched.Locale != current.Locale:
+		field = "locale"
+	case !sameTime(patched.DeletedAt, current.DeletedAt):
+		field = "deleted_at"
+	default:
+		return nil
+	}
+	return &errors.ErrorDetail{
+		Code:   errors.ValidationErrorCode,
+		Detail: field + " is read-only",
+		Attr:   field,
+	}
+}
+
+// changedColumns возвращает колонки, значения которых отличаются после патча
+func changedColumns(current, patched *models.Procedure) []string {
+	var columns []string
+	if patched.Slug != current.Slug {
+		columns = append(columns, "slug")
+	}
+	if patched.Title != current.Title {
+		columns = append(columns, "title")
+	}
+	if patched.Type != current.Type {
+		columns = append(columns, "type")
+	}
+	currentContent, _ := json.Marshal(current.Content)
+	patchedContent, _ := json.Marshal(patched.Content)
+	if !bytes.Equal(currentContent, patchedContent) {
+		columns = append(columns, "content")
+	}
+	if patched.SortOrder != current.SortOrder {
+		columns = append(columns, "sort_order")
+	}
+	if patched.IsExpanded != current.IsExpanded {
+		columns = append(columns, "is_expanded")
+	}
+	if !sameTime(patched.PublishAt, current.PublishAt) {
+		columns = append(columns, "publish_at")
+	}
+	if !sameTime(patched.UnpublishAt, current.UnpublishAt) {
+		columns = append(columns, "unpublish_at")
+	}
+	if !slices.Equal(patched.Tags, current.Tags) {
+		columns = append(columns, "tags")
+	}
+	return columns
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// procedureTransitions — разрешённые переходы между статусами процедуры
+var procedureTransitions = map[models.ProcedureStatus][]models.ProcedureStatus{
+	models.ProcedureDraft:     {models.ProcedureInReview, models.ProcedureArchived},
+	models.ProcedureInReview:  {models.ProcedureDraft, models.ProcedurePublished},
+	models.ProcedurePublished: {models.ProcedureDraft, models.ProcedureArchived},
+	models.ProcedureArchived:  {models.ProcedureDraft},
+}
+
+func canTransition(from, to models.ProcedureStatus) bool {
+	for _, allowed := range procedureTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSchedule приводит время публикации к UTC: колонки хранятся без часового пояса
+func normalizeSchedule(procedure *models.Procedure) {
+	if procedure.PublishAt != nil {
+		t := procedure.PublishAt.UTC()
+		procedure.PublishAt = &t
+	}
+	if procedure.UnpublishAt != nil {
+		t := procedure.UnpublishAt.UTC()
+		procedure.UnpublishAt = &t
+	}
+}
+
+func validateSchedule(procedure *models.Procedure) *errors.ErrorDetail {
+	if procedure.PublishAt != nil && procedure.UnpublishAt != nil &&
+		!procedure.UnpublishAt.After(*procedure.PublishAt) {
+		return &errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: "unpublish_at must be after publish_at",
+			Attr:   "unpublish_at",
+		}
+	}
+	return nil
+}
+
+// normalizeTags проверяет теги процедуры, убирает повторы и сортирует их.
+// Tags == nil не меняется: это означает, что теги не передавались.
+func normalizeTags(procedure *models.Procedure) *errors.ErrorDetail {
+	if procedure.Tags == nil {
+		return nil
+	}
+	for i, tag := range procedure.Tags {
+		if len(tag) > 100 || !procedureSlugPattern.MatchString(tag) {
+			return &errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("invalid tag %q", tag),
+				Attr:   fmt.Sprintf("tags[%d]", i),
+			}
+		}
+	}
+	tags := slices.Clone(procedure.Tags)
+	slices.Sort(tags)
+	procedure.Tags = slices.Compact(tags)
+	return nil
+}
+
+// normalizeTagFilter проверяет фильтр по тегам и подставляет режим all по умолчанию
+func normalizeTagFilter(filter *models.TagFilter) error {
+	if filter.Mode == "" {
+		filter.Mode = models.TagMatchAll
+	}
+	if filter.Mode != models.TagMatchAll && filter.Mode != models.TagMatchAny {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("tag_mode must be %s or %s", models.TagMatchAll, models.TagMatchAny),
+				Attr:   "tag_mode",
+			},
+		)
+	}
+	for _, tag := range filter.Slugs {
+		if !procedureSlugPattern.MatchString(tag) {
+			return errors.NewError(
+				400,
+				errors.ErrorDetail{
+					Code:   errors.ValidationErrorCode,
+					Detail: fmt.Sprintf("invalid tag %q", tag),
+					Attr:   "tag",
+				},
+			)
+		}
+	}
+	slugs := slices.Clone(filter.Slugs)
+	slices.Sort(slugs)
+	filter.Slugs = slices.Compact(slugs)
+	return nil
+}
+
+// validateContent проверяет элементы содержимого и возвращает ошибки
+// с путями вида content[2].url. Пустой тип элемента трактуется как text.
+func validateContent(content models.ProcedureContent) []errors.ErrorDetail {
+	var details []errors.ErrorDetail
+	requirementCodes := map[string]bool{}
+	for i := range content {
+		item := &content[i]
+		if item.Type == "" {
+			item.Type = models.ContentItemText
+		}
+		attr := fmt.Sprintf("content[%d]", i)
+		if !item.Type.IsValid() {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("unknown content item type %q", item.Type),
+				Attr:   attr + ".type",
+			})
+			continue
+		}
+		if strings.TrimSpace(item.Value) == "" {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "value is required",
+				Attr:   attr + ".value",
+			})
+		}
+		if item.Type == models.ContentItemLink && item.URL == "" {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "url is required for link items",
+				Attr:   attr + ".url",
+			})
+			continue
+		}
+		if item.AttachmentID != 0 && (item.Type != models.ContentItemDocument || item.AttachmentID < 0) {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "attachment_id must reference an attachment and is allowed only in document items",
+				Attr:   attr + ".attachment_id",
+			})
+		}
+		if item.URL != "" && !isValidContentURL(item.URL) {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "url must be an absolute http(s) url or a path starting with /",
+				Attr:   attr + ".url",
+			})
+		}
+		if item.Requirement != nil {
+			details = append(details, validateRequirement(item, attr+".requirement", requirementCodes)...)
+		}
+	}
+	return details
+}
+
+// requirementCodePattern — код требования к документу, например recipient_passport
+var requirementCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// validateRequirement проверяет требование к документу заявления; коды
+// требований в пределах одной процедуры не повторяются
+func validateRequirement(item *models.ContentItem, attr string, codes map[string]bool) []errors.ErrorDetail {
+	var details []errors.ErrorDetail
+	invalid := func(attr, detail string) {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: detail,
+			Attr:   attr,
+		})
+	}
+	if item.Type != models.ContentItemDocument {
+		invalid(attr, "requirement is allowed only in document items")
+		return details
+	}
+	requirement := item.Requirement
+	switch {
+	case !requirementCodePattern.MatchString(requirement.Code):
+		invalid(attr+".code", "code must start with a latin letter and contain 2 to 50 lowercase latin letters, digits and underscores")
+	case codes[requirement.Code]:
+		invalid(attr+".code", fmt.Sprintf("requirement %s is already listed", requirement.Code))
+	}
+	codes[requirement.Code] = true
+	for i, kind := range requirement.ClaimKinds {
+		if !kind.IsValid() {
+			invalid(fmt.Sprintf("%s.claim_kinds[%d]", attr, i), "claim kind must be loss or damage")
+		}
+	}
+	for i, contentType := range requirement.ContentTypes {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil || mediaType != contentType || len(params) > 0 {
+			invalid(fmt.Sprintf("%s.content_types[%d]", attr, i), "content type must be a lowercase MIME type without parameters, e.g. image/jpeg")
+		}
+	}
+	return details
+}
+
+func isValidContentURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(u.Path, "/")
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -2,15 +2,38 @@ package services
 
 import (
 	stderrors "errors"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
 	"tech-quest/internal/services/mocks"
 	appErrors "tech-quest/pkg/errors"
 )
 
+func newTypeRepoMock(slugs ...string) *mocks.ProcedureTypeRepoMock {
+	return &mocks.ProcedureTypeRepoMock{
+		GetAllFn: func() ([]models.ProcedureType, error) {
+			types := make([]models.ProcedureType, 0, len(slugs))
+			for _, slug := range slugs {
+				types = append(types, models.ProcedureType{Slug: slug})
+			}
+			return types, nil
+		},
+		GetBySlugFn: func(slug string) (*models.ProcedureType, error) {
+			for _, known := range slugs {
+				if known == slug {
+					return &models.ProcedureType{Slug: slug}, nil
+				}
+			}
+			return nil, appErrors.ErrNotFound
+		},
+	}
+}
+
 func TestProcedureService_GetAll(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -41,7 +64,7 @@ func TestProcedureService_GetAll(t *testing.T) {
 			repo := &mocks.ProcedureRepoMock{
 				GetAllFn: tt.mockFn,
 			}
-			service := NewProcedureService(repo)
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
 			res, err := service.GetAll()
 			if tt.wantErr {
 				require.Error(t, err)
@@ -61,11 +84,18 @@ func TestProcedureService_GetAll(t *testing.T) {
 func TestProcedureService_GetByID(t *testing.T) {
 	tests := []struct {
 		name       string
+		status     models.ProcedureStatus
 		repoErr    error
 		wantStatus int
 	}{
 		{
-			name: "success",
+			name:   "success",
+			status: models.ProcedurePublished,
+		},
+		{
+			name:       "not published",
+			status:     models.ProcedureDraft,
+			wantStatus: 404,
 		},
 		{
 			name:       "not found",
@@ -86,10 +116,10 @@ func TestProcedureService_GetByID(t *testing.T) {
 					if tt.repoErr != nil {
 						return nil, tt.repoErr
 					}
-					return &models.Procedure{ID: id}, nil
+					return &models.Procedure{ID: id, Status: tt.status}, nil
 				},
 			}
-			service := NewProcedureService(repo)
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
 			res, err := service.GetByID(1)
 			if tt.wantStatus != 0 {
 				require.Error(t, err)
@@ -109,22 +139,31 @@ func TestProcedureService_GetByID(t *testing.T) {
 }
 
 func TestProcedureService_GetByType(t *testing.T) {
+	var gotTags models.TagFilter
 	repo := &mocks.ProcedureRepoMock{
-		GetByTypeFn: func(t string) ([]models.Procedure, error) {
+		GetByTypeFn: func(t string, tags models.TagFilter) ([]models.Procedure, error) {
+			gotTags = tags
 			return []models.Procedure{
 				{ID: 1, Type: t},
 			}, nil
 		},
 	}
-	service := NewProcedureService(repo)
-	res, err := service.GetByType("manual")
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+	res, err := service.GetByType("manual", models.TagFilter{Slugs: []string{"urgent", "courier", "urgent"}})
 	require.NoError(t, err)
 	require.Len(t, res, 1)
 	require.Equal(t, "manual", res[0].Type)
+	require.Equal(t, models.TagFilter{Slugs: []string{"courier", "urgent"}, Mode: models.TagMatchAll}, gotTags)
+
+	_, err = service.GetByType("manual", models.TagFilter{Slugs: []string{"urgent"}, Mode: "some"})
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 400, appErr.StatusCode)
+	require.Equal(t, "tag_mode", appErr.ErrorDetail[0].Attr)
 }
 
 func TestProcedureService_Create_Validation(t *testing.T) {
-	service := NewProcedureService(&mocks.ProcedureRepoMock{})
+	service := NewProcedureService(&mocks.ProcedureRepoMock{}, newTypeRepoMock("manual"))
 	err := service.Create(&models.Procedure{
 		Type: "manual",
 	})
@@ -136,6 +175,27 @@ func TestProcedureService_Create_Validation(t *testing.T) {
 	require.Equal(t, "title", appErr.ErrorDetail[0].Attr)
 }
 
+func TestProcedureService_Create_SlugValidation(t *testing.T) {
+	for _, slug := range []string{"Loss Procedure", "loss--procedure", "-loss", strings.Repeat("a", 101)} {
+		t.Run(slug, func(t *testing.T) {
+			repo := &mocks.ProcedureRepoMock{
+				CreateFn: func(*models.Procedure) error {
+					t.Fatal("invalid slug must not reach the repository")
+					return nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+			err := service.Create(&models.Procedure{Slug: slug, Title: "Test", Type: "manual"})
+
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, 400, appErr.StatusCode)
+			require.Equal(t, "slug", appErr.ErrorDetail[0].Attr)
+		})
+	}
+}
+
 func TestProcedureService_Create_OK(t *testing.T) {
 	called := false
 	repo := &mocks.ProcedureRepoMock{
@@ -145,7 +205,7 @@ func TestProcedureService_Create_OK(t *testing.T) {
 			return nil
 		},
 	}
-	service := NewProcedureService(repo)
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
 	err := service.Create(&models.Procedure{
 		Title: "Test",
 		Type:  "manual",
@@ -154,6 +214,115 @@ func TestProcedureService_Create_OK(t *testing.T) {
 	require.True(t, called)
 }
 
+func TestProcedureService_Create_ContentValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   models.ProcedureContent
+		wantAttrs []string
+	}{
+		{
+			name: "valid items",
+			content: models.ProcedureContent{
+				{Type: models.ContentItemText, Value: "накладная"},
+				{Type: models.ContentItemLink, Value: "форма", URL: "https://example.com/form.pdf"},
+				{Type: models.ContentItemDocument, Value: "заявление", URL: "/files/claim.pdf"},
+			},
+		},
+		{
+			name: "unknown type",
+			content: models.ProcedureContent{
+				{Type: "video", Value: "x"},
+			},
+			wantAttrs: []string{"content[0].type"},
+		},
+		{
+			name: "link without url and empty value",
+			content: models.ProcedureContent{
+				{Type: models.ContentItemStep, Value: "шаг"},
+				{Type: models.ContentItemWarning, Value: " "},
+				{Type: models.ContentItemLink, Value: "форма"},
+			},
+			wantAttrs: []string{"content[1].value", "content[2].url"},
+		},
+		{
+			name: "invalid url",
+			content: models.ProcedureContent{
+				{Type: models.ContentItemDocument, Value: "форма", URL: "javascript:alert(1)"},
+			},
+			wantAttrs: []string{"content[0].url"},
+		},
+		{
+			name: "document requirements",
+			content: models.ProcedureContent{
+				{Type: models.ContentItemDocument, Value: "паспорт", Requirement: &models.DocumentRequirement{
+					Code: "recipient_passport", Required: true, ClaimKinds: []models.ClaimKind{models.ClaimLoss, models.ClaimDamage},
+				}},
+				{Type: models.ContentItemDocument, Value: "опись", Requirement: &models.DocumentRequirement{Code: "inventory"}},
+			},
+		},
+		{
+			name: "invalid requirements",
+			content: models.ProcedureContent{
+				{Type: models.ContentItemText, Value: "паспорт", Requirement: &models.DocumentRequirement{Code: "passport"}},
+				{Type: models.ContentItemDocument, Value: "паспорт", Requirement: &models.DocumentRequirement{
+					Code: "Passport", ClaimKinds: []models.ClaimKind{"theft"},
+				}},
+				{Type: models.ContentItemDocument, Value: "опись", Requirement: &models.DocumentRequirement{Code: "inventory"}},
+				{Type: models.ContentItemDocument, Value: "опись", Requirement: &models.DocumentRequirement{Code: "inventory"}},
+			},
+			wantAttrs: []string{
+				"content[0].requirement",
+				"content[1].requirement.code",
+				"content[1].requirement.claim_kinds[0]",
+				"content[3].requirement.code",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.ProcedureRepoMock{
+				CreateFn: func(p *models.Procedure) error {
+					return nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+			err := service.Create(&models.Procedure{
+				Title:   "Test",
+				Type:    "manual",
+				Content: tt.content,
+			})
+			if len(tt.wantAttrs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, 400, appErr.StatusCode)
+			attrs := make([]string, 0, len(appErr.ErrorDetail))
+			for _, d := range appErr.ErrorDetail {
+				attrs = append(attrs, d.Attr)
+			}
+			require.Equal(t, tt.wantAttrs, attrs)
+		})
+	}
+}
+
+func TestProcedureService_Create_DefaultsContentType(t *testing.T) {
+	service := NewProcedureService(&mocks.ProcedureRepoMock{
+		CreateFn: func(p *models.Procedure) error {
+			return nil
+		},
+	}, newTypeRepoMock("manual"))
+	procedure := &models.Procedure{
+		Title:   "Test",
+		Type:    "manual",
+		Content: models.ProcedureContent{{Value: "накладная"}},
+	}
+	require.NoError(t, service.Create(procedure))
+	require.Equal(t, models.ContentItemText, procedure.Content[0].Type)
+}
+
 func TestProcedureService_Update(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -165,6 +334,7 @@ func TestProcedureService_Update(t *testing.T) {
 			name: "missing id",
 			procedure: models.Procedure{
 				Title: "Test",
+				Type:  "manual",
 			},
 			wantStatus: 400,
 		},
@@ -173,15 +343,26 @@ func TestProcedureService_Update(t *testing.T) {
 			procedure: models.Procedure{
 				ID:    1,
 				Title: "Test",
+				Type:  "manual",
 			},
 			repoErr:    appErrors.ErrNotFound,
 			wantStatus: 404,
 		},
+		{
+			name: "unknown type",
+			procedure: models.Procedure{
+				ID:    1,
+				Title: "Test",
+				Type:  "manul",
+			},
+			wantStatus: 422,
+		},
 		{
 			name: "success",
 			procedure: models.Procedure{
 				ID:    1,
 				Title: "Test",
+				Type:  "manual",
 			},
 		},
 	}
@@ -193,7 +374,7 @@ func TestProcedureService_Update(t *testing.T) {
 					return tt.repoErr
 				},
 			}
-			service := NewProcedureService(repo)
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
 			err := service.Update(&tt.procedure)
 			if tt.wantStatus != 0 {
 				require.Error(t, err)
@@ -212,10 +393,12 @@ func TestProcedureService_Update(t *testing.T) {
 
 func TestProcedureService_Delete(t *testing.T) {
 	tests := []struct {
-		name       string
-		id         int
-		repoErr    error
-		wantStatus int
+		name         string
+		id           int
+		children     models.ChildrenOnDelete
+		repoErr      error
+		wantStatus   int
+		wantChildren models.ChildrenOnDelete
 	}{
 		{
 			name:       "invalid id",
@@ -223,39 +406,740 @@ func TestProcedureService_Delete(t *testing.T) {
 			wantStatus: 400,
 		},
 		{
-			name:       "not found",
+			name:         "not found",
+			id:           1,
+			repoErr:      appErrors.ErrNotFound,
+			wantStatus:   404,
+			wantChildren: models.ChildrenReject,
+		},
+		{
+			name:         "stale version",
+			id:           1,
+			repoErr:      appErrors.ErrPreconditionFailed,
+			wantStatus:   412,
+			wantChildren: models.ChildrenReject,
+		},
+		{
+			name:       "unknown children mode",
 			id:         1,
+			children:   "orphan",
+			wantStatus: 400,
+		},
+		{
+			name:         "has children",
+			id:           1,
+			repoErr:      repository.ErrHasChildren,
+			wantStatus:   409,
+			wantChildren: models.ChildrenReject,
+		},
+		{
+			name:         "success",
+			id:           1,
+			wantChildren: models.ChildrenReject,
+		},
+		{
+			name:         "cascade",
+			id:           1,
+			children:     models.ChildrenCascade,
+			wantChildren: models.ChildrenCascade,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotChildren models.ChildrenOnDelete
+			repo := &mocks.ProcedureRepoMock{
+				DeleteFn: func(id, version int, children models.ChildrenOnDelete) error {
+					gotChildren = children
+					return tt.repoErr
+				},
+			}
+
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+			err := service.Delete(tt.id, 0, tt.children)
+			require.Equal(t, tt.wantChildren, gotChildren)
+
+			if tt.wantStatus != 0 {
+				require.Error(t, err)
+
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProcedureService_RestorePurge(t *testing.T) {
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name:       "not in trash",
 			repoErr:    appErrors.ErrNotFound,
 			wantStatus: 404,
 		},
 		{
 			name: "success",
-			id:   1,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mocks.ProcedureRepoMock{
-				DeleteFn: func(id int) error {
+				RestoreFn: func(id int) (*models.Procedure, error) {
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.Procedure{ID: id, Status: models.ProcedurePublished}, nil
+				},
+				PurgeFn: func(id int) error {
 					return tt.repoErr
 				},
 			}
 
-			service := NewProcedureService(repo)
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
 
-			err := service.Delete(tt.id)
+			restored, restoreErr := service.Restore(1)
+			purgeErr := service.Purge(1)
 
 			if tt.wantStatus != 0 {
-				require.Error(t, err)
+				for _, err := range []error{restoreErr, purgeErr} {
+					var appErr *appErrors.Error
+					require.True(t, stderrors.As(err, &appErr))
+					require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				}
+				return
+			}
 
+			require.NoError(t, restoreErr)
+			require.NoError(t, purgeErr)
+			require.Equal(t, 1, restored.ID)
+		})
+	}
+}
+
+func TestProcedureService_Restore_ParentInTrash(t *testing.T) {
+	repo := &mocks.ProcedureRepoMock{
+		RestoreFn: func(int) (*models.Procedure, error) {
+			return nil, repository.ErrParentDeleted
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+	_, err := service.Restore(2)
+
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 409, appErr.StatusCode)
+}
+
+func TestProcedureService_Move(t *testing.T) {
+	parent := func(id int) *int { return &id }
+	tests := []struct {
+		name       string
+		parentID   *int
+		version    int
+		repoErr    error
+		wantMove   bool
+		wantStatus int
+	}{
+		{
+			name:     "under another procedure",
+			parentID: parent(5),
+			wantMove: true,
+		},
+		{
+			name:     "to top level",
+			wantMove: true,
+		},
+		{
+			name:     "same parent is a no-op",
+			parentID: parent(3),
+		},
+		{
+			name:       "under itself",
+			parentID:   parent(1),
+			wantStatus: 422,
+		},
+		{
+			name:       "under descendant",
+			parentID:   parent(7),
+			repoErr:    repository.ErrParentCycle,
+			wantMove:   true,
+			wantStatus: 422,
+		},
+		{
+			name:       "unknown parent",
+			parentID:   parent(42),
+			repoErr:    repository.ErrParentNotFound,
+			wantMove:   true,
+			wantStatus: 422,
+		},
+		{
+			name:       "too deep",
+			parentID:   parent(9),
+			repoErr:    &repository.DepthLimitError{Depth: 6, MaxDepth: 5},
+			wantMove:   true,
+			wantStatus: 422,
+		},
+		{
+			name:       "stale version",
+			parentID:   parent(5),
+			version:    1,
+			wantStatus: 412,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moved := false
+			repo := &mocks.ProcedureRepoMock{
+				GetByIDFn: func(id int) (*models.Procedure, error) {
+					return &models.Procedure{ID: id, ParentID: parent(3), Version: 2}, nil
+				},
+				MoveFn: func(id int, parentID *int, version int) (*models.Procedure, error) {
+					moved = true
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.Procedure{ID: id, ParentID: parentID, Version: 3}, nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+			procedure, err := service.Move(1, tt.version, tt.parentID)
+			require.Equal(t, tt.wantMove, moved)
+
+			if tt.wantStatus != 0 {
 				var appErr *appErrors.Error
 				require.True(t, stderrors.As(err, &appErr))
 				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.parentID, procedure.ParentID)
+		})
+	}
+}
+
+func TestBuildProcedureTree(t *testing.T) {
+	parent := func(id int) *int { return &id }
+	procedures := []models.Procedure{
+		{ID: 1, Title: "Утрата посылки"},
+		{ID: 2, Title: "Подать заявление", ParentID: parent(1)},
+		{ID: 3, Title: "Приложить опись", ParentID: parent(2)},
+		{ID: 4, Title: "Получить ответ", ParentID: parent(1)},
+		{ID: 5, Title: "Повреждение посылки"},
+		// Родитель не опубликован, поэтому подпроцедура скрыта
+		{ID: 6, Title: "Черновой шаг", ParentID: parent(99)},
+	}
+
+	tree := BuildProcedureTree(procedures)
 
+	require.Len(t, tree, 2)
+	require.Equal(t, 1, tree[0].ID)
+	require.Len(t, tree[0].Children, 2)
+	require.Equal(t, 2, tree[0].Children[0].ID)
+	require.Equal(t, 4, tree[0].Children[1].ID)
+	require.Len(t, tree[0].Children[0].Children, 1)
+	require.Equal(t, 3, tree[0].Children[0].Children[0].ID)
+	require.Empty(t, tree[0].Children[1].Children)
+	require.NotNil(t, tree[0].Children[1].Children)
+	require.Equal(t, 5, tree[1].ID)
+}
+
+func TestProcedureService_Create_Status(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     models.ProcedureStatus
+		wantStatus models.ProcedureStatus
+		wantErr    bool
+	}{
+		{
+			name:       "defaults to draft",
+			wantStatus: models.ProcedureDraft,
+		},
+		{
+			name:       "in review",
+			status:     models.ProcedureInReview,
+			wantStatus: models.ProcedureInReview,
+		},
+		{
+			name:    "published is rejected",
+			status:  models.ProcedurePublished,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewProcedureService(&mocks.ProcedureRepoMock{
+				CreateFn: func(p *models.Procedure) error {
+					return nil
+				},
+			}, newTypeRepoMock("manual"))
+			procedure := &models.Procedure{Title: "Test", Type: "manual", Status: tt.status}
+			err := service.Create(procedure)
+			if tt.wantErr {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, 400, appErr.StatusCode)
+				require.Equal(t, "status", appErr.ErrorDetail[0].Attr)
 				return
 			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, procedure.Status)
+		})
+	}
+}
 
+func TestProcedureService_Transition(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       models.ProcedureStatus
+		to         models.ProcedureStatus
+		repoErr    error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "draft to review",
+			from: models.ProcedureDraft,
+			to:   models.ProcedureInReview,
+		},
+		{
+			name: "review to published",
+			from: models.ProcedureInReview,
+			to:   models.ProcedurePublished,
+		},
+		{
+			name:       "draft to published is not allowed",
+			from:       models.ProcedureDraft,
+			to:         models.ProcedurePublished,
+			wantStatus: 409,
+			wantCode:   appErrors.InvalidTransitionCode,
+		},
+		{
+			name:       "unknown status",
+			from:       models.ProcedureDraft,
+			to:         "deleted",
+			wantStatus: 400,
+			wantCode:   appErrors.ValidationErrorCode,
+		},
+		{
+			name:       "concurrent change",
+			from:       models.ProcedurePublished,
+			to:         models.ProcedureArchived,
+			repoErr:    appErrors.ErrConflict,
+			wantStatus: 409,
+			wantCode:   appErrors.ConflictCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.ProcedureRepoMock{
+				GetByIDFn: func(id int) (*models.Procedure, error) {
+					return &models.Procedure{ID: id, Status: tt.from}, nil
+				},
+				UpdateStatusFn: func(id int, from, to models.ProcedureStatus) (*models.Procedure, error) {
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					require.Equal(t, tt.from, from)
+					return &models.Procedure{ID: id, Status: to}, nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+			res, err := service.Transition(1, tt.to)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				require.Equal(t, tt.wantCode, appErr.ErrorDetail[0].Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.to, res.Status)
+		})
+	}
+}
+
+func TestProcedureService_UnknownTags(t *testing.T) {
+	var created *models.Procedure
+	repo := &mocks.ProcedureRepoMock{
+		CreateFn: func(p *models.Procedure) error {
+			created = p
+			if slices.Contains(p.Tags, "missing") {
+				return &repository.UnknownTagsError{Tags: []string{"missing"}}
+			}
+			return nil
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+	err := service.Create(&models.Procedure{Title: "Test", Type: "manual", Tags: []string{"urgent", "courier", "urgent"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"courier", "urgent"}, created.Tags)
+
+	err = service.Create(&models.Procedure{Title: "Test", Type: "manual", Tags: []string{"urgent", "missing"}})
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 422, appErr.StatusCode)
+	require.Equal(t, "tags", appErr.ErrorDetail[0].Attr)
+}
+
+func TestProcedureService_UnknownType(t *testing.T) {
+	service := NewProcedureService(&mocks.ProcedureRepoMock{}, newTypeRepoMock("loss_procedure", "damage_procedure"))
+
+	err := service.Create(&models.Procedure{Title: "Test", Type: "loss_procedur"})
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 422, appErr.StatusCode)
+	require.Equal(t, "type", appErr.ErrorDetail[0].Attr)
+	require.Equal(t, []string{"loss_procedure", "damage_procedure"}, appErr.Errors["valid_types"])
+
+	_, err = service.GetByType("loss_procedur", models.TagFilter{})
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 404, appErr.StatusCode)
+	require.Equal(t, appErrors.NotFoundCode, appErr.ErrorDetail[0].Code)
+}
+
+func TestProcedureService_Reorder(t *testing.T) {
+	current := []models.ProcedureOrder{
+		{ID: 1, SortOrder: 1},
+		{ID: 2, SortOrder: 2},
+		{ID: 3, SortOrder: 3},
+		{ID: 4, SortOrder: 4},
+	}
+	tests := []struct {
+		name       string
+		req        models.ReorderRequest
+		repoErr    error
+		wantIDs    []int
+		wantStatus int
+	}{
+		{
+			name:    "explicit ids",
+			req:     models.ReorderRequest{IDs: []int{4, 3, 2, 1}},
+			wantIDs: []int{4, 3, 2, 1},
+		},
+		{
+			name:    "move before",
+			req:     models.ReorderRequest{Move: &models.MoveOperation{ID: 4, Before: 2}},
+			wantIDs: []int{1, 4, 2, 3},
+		},
+		{
+			name:    "move after last",
+			req:     models.ReorderRequest{Move: &models.MoveOperation{ID: 1, After: 4}},
+			wantIDs: []int{2, 3, 4, 1},
+		},
+		{
+			name:       "move unknown anchor",
+			req:        models.ReorderRequest{Move: &models.MoveOperation{ID: 1, After: 9}},
+			wantStatus: 422,
+		},
+		{
+			name:       "ids and move together",
+			req:        models.ReorderRequest{IDs: []int{1}, Move: &models.MoveOperation{ID: 1, After: 2}},
+			wantStatus: 400,
+		},
+		{
+			name:       "mismatched ids",
+			req:        models.ReorderRequest{IDs: []int{1, 2, 9}},
+			repoErr:    &repository.ReorderMismatchError{Unknown: []int{9}, Missing: []int{3, 4}},
+			wantStatus: 422,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []int
+			repo := &mocks.ProcedureRepoMock{
+				GetOrderFn: func(string) ([]models.ProcedureOrder, error) {
+					return current, nil
+				},
+				ReorderFn: func(ids []int, procedureType string) ([]models.ProcedureOrder, error) {
+					gotIDs = ids
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return nil, nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+			_, err := service.Reorder(&tt.req)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestProcedureService_Search(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		limit      int
+		wantQuery  string
+		wantLimit  int
+		wantStatus int
+	}{
+		{name: "default limit", query: "  полис ", wantQuery: "полис", wantLimit: 20},
+		{name: "explicit limit", query: "полис", limit: 5, wantQuery: "полис", wantLimit: 5},
+		{name: "empty query", query: "   ", wantStatus: 400},
+		{name: "limit too big", query: "полис", limit: 51, wantStatus: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotQuery, gotLocale string
+			var gotLimit int
+			repo := &mocks.ProcedureRepoMock{
+				SearchFn: func(query, locale string, limit int) ([]models.SearchResult, error) {
+					gotQuery, gotLocale, gotLimit = query, locale, limit
+					return []models.SearchResult{{Procedure: models.Procedure{ID: 1}, Rank: 0.5}}, nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock())
+			results, err := service.Search(tt.query, "en", tt.limit)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Equal(t, tt.wantQuery, gotQuery)
+			require.Equal(t, "en", gotLocale)
+			require.Equal(t, tt.wantLimit, gotLimit)
+		})
+	}
+}
+
+func TestProcedureService_List(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     models.ProcedureFilter
+		repoErr    error
+		wantFilter models.ProcedureFilter
+		wantStatus int
+	}{
+		{
+			name:       "defaults",
+			wantFilter: models.ProcedureFilter{Limit: 50, Sort: "sort_order", Tags: models.TagFilter{Mode: "all"}},
+		},
+		{
+			name:   "descending title",
+			filter: models.ProcedureFilter{Limit: 10, Sort: "-title", Types: []string{"manual"}},
+			wantFilter: models.ProcedureFilter{
+				Limit: 10, Sort: "-title", Types: []string{"manual"}, Tags: models.TagFilter{Mode: "all"},
+			},
+		},
+		{
+			name: "any of tags",
+			filter: models.ProcedureFilter{
+				Tags: models.TagFilter{Slugs: []string{"urgent", "courier"}, Mode: "any"},
+			},
+			wantFilter: models.ProcedureFilter{
+				Limit: 50, Sort: "sort_order", Tags: models.TagFilter{Slugs: []string{"courier", "urgent"}, Mode: "any"},
+			},
+		},
+		{
+			name:       "unknown tag mode",
+			filter:     models.ProcedureFilter{Tags: models.TagFilter{Slugs: []string{"urgent"}, Mode: "none"}},
+			wantStatus: 400,
+		},
+		{
+			name:       "invalid tag",
+			filter:     models.ProcedureFilter{Tags: models.TagFilter{Slugs: []string{"Срочно"}}},
+			wantStatus: 400,
+		},
+		{
+			name:       "unknown sort field",
+			filter:     models.ProcedureFilter{Sort: "content"},
+			wantStatus: 400,
+		},
+		{
+			name:       "limit too big",
+			filter:     models.ProcedureFilter{Limit: 101},
+			wantStatus: 400,
+		},
+		{
+			name:       "invalid cursor",
+			filter:     models.ProcedureFilter{After: "garbage"},
+			repoErr:    repository.ErrInvalidCursor,
+			wantStatus: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFilter models.ProcedureFilter
+			repo := &mocks.ProcedureRepoMock{
+				ListFn: func(filter models.ProcedureFilter) (*models.ProcedurePage, error) {
+					gotFilter = filter
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.ProcedurePage{Items: []models.Procedure{}}, nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock())
+			_, err := service.List(tt.filter)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFilter, gotFilter)
+		})
+	}
+}
+
+func TestProcedureService_Patch(t *testing.T) {
+	stored := models.Procedure{
+		ID:      1,
+		Title:   "Потеря багажа",
+		Type:    "manual",
+		Status:  models.ProcedurePublished,
+		Version: 2,
+		Content: models.ProcedureContent{
+			{Type: models.ContentItemText, Value: "Шаг 1"},
+		},
+		SortOrder: 3,
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		version     int
+		patch       string
+		wantColumns []string
+		wantStatus  int
+	}{
+		{
+			name:        "merge patch keeps other fields",
+			contentType: "application/merge-patch+json",
+			patch:       `{"is_expanded": true}`,
+			wantColumns: []string{"is_expanded"},
+		},
+		{
+			name:        "matching version",
+			contentType: "application/merge-patch+json",
+			version:     2,
+			patch:       `{"sort_order": 4}`,
+			wantColumns: []string{"sort_order"},
+		},
+		{
+			name:        "stale version",
+			contentType: "application/merge-patch+json",
+			version:     1,
+			patch:       `{"sort_order": 4}`,
+			wantStatus:  412,
+		},
+		{
+			name:        "version is read-only",
+			contentType: "application/merge-patch+json",
+			patch:       `{"version": 7}`,
+			wantStatus:  400,
+		},
+		{
+			name:        "parent is changed only by move",
+			contentType: "application/merge-patch+json",
+			patch:       `{"parent_id": 5}`,
+			wantStatus:  400,
+		},
+		{
+			name:        "json patch appends content",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op": "add", "path": "/content/-", "value": {"type": "text", "value": "Шаг 2"}}]`,
+			wantColumns: []string{"content"},
+		},
+		{
+			name:        "merge patch sets tags",
+			contentType: "application/merge-patch+json",
+			patch:       `{"tags": ["urgent", "courier", "urgent"]}`,
+			wantColumns: []string{"tags"},
+		},
+		{
+			name:        "invalid tag",
+			contentType: "application/merge-patch+json",
+			patch:       `{"tags": ["Срочно"]}`,
+			wantStatus:  400,
+		},
+		{
+			name:        "no changes",
+			contentType: "application/merge-patch+json",
+			patch:       `{"title": "Потеря багажа"}`,
+		},
+		{
+			name:        "removing title fails validation",
+			contentType: "application/merge-patch+json",
+			patch:       `{"title": null}`,
+			wantStatus:  400,
+		},
+		{
+			name:        "status is read-only",
+			contentType: "application/merge-patch+json",
+			patch:       `{"status": "draft"}`,
+			wantStatus:  400,
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/merge-patch+json",
+			patch:       `{"color": "red"}`,
+			wantStatus:  400,
+		},
+		{
+			name:        "failed test operation",
+			contentType: "application/json-patch+json",
+			patch:       `[{"op": "test", "path": "/title", "value": "Другое"}]`,
+			wantStatus:  409,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "application/json",
+			patch:       `{"is_expanded": true}`,
+			wantStatus:  415,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotColumns []string
+			patched := false
+			repo := &mocks.ProcedureRepoMock{
+				GetByIDFn: func(int) (*models.Procedure, error) {
+					procedure := stored
+					return &procedure, nil
+				},
+				PatchFn: func(procedure *models.Procedure, columns []string) error {
+					patched = true
+					gotColumns = columns
+					return nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+			procedure, err := service.Patch(1, tt.version, tt.contentType, []byte(tt.patch))
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				require.False(t, patched)
+				return
+			}
 			require.NoError(t, err)
+			require.Equal(t, stored.Title, procedure.Title)
+			require.Equal(t, tt.wantColumns != nil, patched)
+			require.Equal(t, tt.wantColumns, gotColumns)
 		})
 	}
 }
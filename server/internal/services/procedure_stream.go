@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+)
+
+const (
+	procedureStreamQueueSize      = 1024
+	procedureStreamSubscriberSize = 64
+)
+
+// ProcedureStream превращает изменения процедур в события для SSE-клиентов.
+// Последние события хранятся в кольцевом буфере, чтобы переподключившийся клиент
+// мог продолжить с Last-Event-ID. ID события содержит идентификатор экземпляра:
+// после переподключения к другой реплике клиент получает resync.
+type ProcedureStream struct {
+	repo     repository.ProcedureRepos
+	instance string
+	changes  chan models.ProcedureChange
+
+	mu          sync.Mutex
+	seq         uint64
+	buffer      []models.ProcedureEvent
+	bufferSize  int
+	subscribers map[chan models.ProcedureEvent]struct{}
+	closed      bool
+}
+
+func NewProcedureStream(repo repository.ProcedureRepos, bufferSize int) *ProcedureStream {
+	instance := make([]byte, 4)
+	_, _ = rand.Read(instance)
+	return &ProcedureStream{
+		repo:        repo,
+		instance:    hex.EncodeToString(instance),
+		changes:     make(chan models.ProcedureChange, procedureStreamQueueSize),
+		bufferSize:  bufferSize,
+		subscribers: make(map[chan models.ProcedureEvent]struct{}),
+	}
+}
+
+// OnChange ставит изменение в очередь на обработку, не блокируя слушателя Postgres.
+// Если очередь переполнена, изменение заменяется на resync.
+func (s *ProcedureStream) OnChange(change models.ProcedureChange) {
+	select {
+	case s.changes <- change:
+	default:
+		log.Printf("procedure stream: queue is full, dropping %s %d", change.Op, change.ID)
+		s.publish(models.ProcedureEventResync, []byte("{}"))
+	}
+}
+
+// Start обрабатывает очередь изменений до отмены ctx, после чего закрывает
+// потоки всех подписчиков
+func (s *ProcedureStream) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				s.close()
+				return
+			case change := <-s.changes:
+				s.handle(change)
+			}
+		}
+	}()
+}
+
+// Subscribe подписывает клиента на события. backlog — события после lastEventID
+// из буфера; если продолжить с lastEventID нельзя, backlog состоит из одного resync.
+// unsubscribe нужно вызвать при отключении клиента.
+func (s *ProcedureStream) Subscribe(lastEventID string) (events <-chan models.ProcedureEvent, backlog []models.ProcedureEvent, unsubscribe func()) {
+	ch := make(chan models.ProcedureEvent, procedureStreamSubscriberSize)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		close(ch)
+		return ch, nil, func() {}
+	}
+	if lastEventID != "" {
+		backlog = s.backlogAfter(lastEventID)
+	}
+	s.subscribers[ch] = struct{}{}
+	return ch, backlog, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (s *ProcedureStream) handle(change models.ProcedureChange) {
+	if change.Op == models.ProcedureChangeResync {
+		s.publish(models.ProcedureEventResync, []byte("{}"))
+		return
+	}
+	if change.Op == models.ProcedureChangeDelete && change.Table == "procedures" {
+		s.publishDeleted(change.ID)
+		return
+	}
+
+	procedure, err := s.repo.GetByID(change.ID)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			s.publishDeleted(change.ID)
+			return
+		}
+		log.Printf("procedure stream: failed to get procedure %d: %v", change.ID, err)
+		s.publish(models.ProcedureEventResync, []byte("{}"))
+		return
+	}
+	// Поток публичный: снятая с публикации процедура для клиентов удалена
+	if procedure.Status != models.ProcedurePublished {
+		if change.Op != models.ProcedureChangeInsert {
+			s.publishDeleted(change.ID)
+		}
+		return
+	}
+
+	eventType := models.ProcedureEventUpdated
+	if change.Table == "procedures" {
+		switch change.Op {
+		case models.ProcedureChangeInsert:
+			eventType = models.ProcedureEventCreated
+		case models.ProcedureChangeReorder:
+			eventType = models.ProcedureEventReordered
+		}
+	}
+	data, err := json.Marshal(procedure)
+	if err != nil {
+		log.Printf("procedure stream: failed to encode procedure %d: %v", change.ID, err)
+		return
+	}
+	s.publish(eventType, data)
+}
+
+func (s *ProcedureStream) publishDeleted(id int) {
+	s.publish(models.ProcedureEventDeleted, []byte(fmt.Sprintf(`{"id":%d}`, id)))
+}
+
+// publish добавляет событие в буфер и рассылает подписчикам. Подписчик, который
+// не успевает читать, отключается и продолжит с Last-Event-ID после переподключения.
+func (s *ProcedureStream) publish(eventType models.ProcedureEventType, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.seq++
+	event := models.ProcedureEvent{
+		ID:   s.instance + "-" + strconv.FormatUint(s.seq, 10),
+		Type: eventType,
+		Data: data,
+	}
+	s.buffer = append(s.buffer, event)
+	if len(s.buffer) > s.bufferSize {
+		s.buffer = s.buffer[len(s.buffer)-s.bufferSize:]
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (s *ProcedureStream) backlogAfter(lastEventID string) []models.ProcedureEvent {
+	resync := []models.ProcedureEvent{{
+		ID:   s.instance + "-" + strconv.FormatUint(s.seq, 10),
+		Type: models.ProcedureEventResync,
+		Data: []byte("{}"),
+	}}
+	instance, rawSeq, ok := strings.Cut(lastEventID, "-")
+	if !ok || instance != s.instance {
+		return resync
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil || seq > s.seq {
+		return resync
+	}
+	if seq == s.seq {
+		return nil
+	}
+	// Буфер хранит события с номерами s.seq-len(buffer)+1 .. s.seq
+	oldest := s.seq - uint64(len(s.buffer)) + 1
+	if seq+1 < oldest {
+		return resync
+	}
+	backlog := s.buffer[seq+1-oldest:]
+	return append([]models.ProcedureEvent(nil), backlog...)
+}
+
+func (s *ProcedureStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
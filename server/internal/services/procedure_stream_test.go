@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestProcedureStream_Events(t *testing.T) {
+	repo := &mocks.ProcedureRepoMock{
+		GetByIDFn: func(id int) (*models.Procedure, error) {
+			switch id {
+			case 1:
+				return &models.Procedure{ID: 1, Status: models.ProcedurePublished}, nil
+			case 2:
+				return &models.Procedure{ID: 2, Status: models.ProcedureDraft}, nil
+			}
+			return nil, appErrors.ErrNotFound
+		},
+	}
+	stream := NewProcedureStream(repo, 10)
+	events, backlog, unsubscribe := stream.Subscribe("")
+	defer unsubscribe()
+	require.Empty(t, backlog)
+
+	stream.handle(models.ProcedureChange{Table: "procedures", Op: models.ProcedureChangeInsert, ID: 1})
+	stream.handle(models.ProcedureChange{Table: "procedures", Op: models.ProcedureChangeReorder, ID: 1})
+	stream.handle(models.ProcedureChange{Table: "procedure_translations", Op: models.ProcedureChangeInsert, ID: 1})
+	stream.handle(models.ProcedureChange{Table: "procedures", Op: models.ProcedureChangeInsert, ID: 2})
+	stream.handle(models.ProcedureChange{Table: "procedures", Op: models.ProcedureChangeUpdate, ID: 2})
+	stream.handle(models.ProcedureChange{Table: "procedures", Op: models.ProcedureChangeDelete, ID: 3})
+
+	var got []models.ProcedureEventType
+	for len(events) > 0 {
+		event := <-events
+		got = append(got, event.Type)
+	}
+	require.Equal(t, []models.ProcedureEventType{
+		models.ProcedureEventCreated,
+		models.ProcedureEventReordered,
+		models.ProcedureEventUpdated,
+		models.ProcedureEventDeleted,
+		models.ProcedureEventDeleted,
+	}, got)
+}
+
+func TestProcedureStream_Resume(t *testing.T) {
+	stream := NewProcedureStream(&mocks.ProcedureRepoMock{}, 3)
+	for id := 1; id <= 5; id++ {
+		stream.publishDeleted(id)
+	}
+	id := func(seq string) string { return stream.instance + "-" + seq }
+
+	_, backlog, unsubscribe := stream.Subscribe(id("3"))
+	unsubscribe()
+	require.Len(t, backlog, 2)
+	require.Equal(t, id("4"), backlog[0].ID)
+	require.Equal(t, `{"id":5}`, string(backlog[1].Data))
+
+	_, backlog, unsubscribe = stream.Subscribe(id("5"))
+	unsubscribe()
+	require.Empty(t, backlog)
+
+	for _, lastEventID := range []string{id("1"), id("9"), "other-3", "garbage"} {
+		_, backlog, unsubscribe = stream.Subscribe(lastEventID)
+		unsubscribe()
+		require.Len(t, backlog, 1, lastEventID)
+		require.Equal(t, models.ProcedureEventResync, backlog[0].Type)
+	}
+}
+
+func TestProcedureStream_DropsSlowSubscriber(t *testing.T) {
+	stream := NewProcedureStream(&mocks.ProcedureRepoMock{}, 10)
+	events, _, unsubscribe := stream.Subscribe("")
+	defer unsubscribe()
+	for id := 0; id <= procedureStreamSubscriberSize; id++ {
+		stream.publishDeleted(id)
+	}
+	for range procedureStreamSubscriberSize {
+		<-events
+	}
+	_, ok := <-events
+	require.False(t, ok)
+}
@@ -0,0 +1,521 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Форматы импорта и экспорта процедур
+const (
+	TransferJSON = "json"
+	TransferYAML = "yaml"
+	TransferCSV  = "csv"
+)
+
+// TransferContentTypes — Content-Type файла для каждого формата
+var TransferContentTypes = map[string]string{
+	TransferJSON: "application/json",
+	TransferYAML: "application/yaml",
+	TransferCSV:  "text/csv",
+}
+
+// procedureSlugPattern — slug процедуры: латиница в нижнем регистре, цифры, '-' и '_'
+var procedureSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+
+// procedureCSVColumns — колонки CSV в порядке экспорта; content хранится как JSON
+var procedureCSVColumns = []string{
+	"slug", "parent", "title", "type", "status", "sort_order", "is_expanded", "publish_at", "unpublish_at", "content",
+}
+
+// Export выгружает процедуры во всех статусах, кроме удалённых в корзину
+func (s *ProcedureService) Export(format string) ([]byte, error) {
+	if err := checkTransferFormat(format); err != nil {
+		return nil, err
+	}
+	procedures, err := s.repo.GetAllAnyStatus()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedures: " + err.Error(),
+			},
+		)
+	}
+	slugs := make(map[int]string, len(procedures))
+	for _, procedure := range procedures {
+		slugs[procedure.ID] = procedure.Slug
+	}
+	records := make([]models.ProcedureRecord, 0, len(procedures))
+	for _, procedure := range procedures {
+		record := models.NewProcedureRecord(procedure)
+		if procedure.ParentID != nil {
+			record.Parent = slugs[*procedure.ParentID]
+		}
+		records = append(records, record)
+	}
+
+	var data []byte
+	switch format {
+	case TransferJSON:
+		data, err = json.MarshalIndent(records, "", "  ")
+	case TransferYAML:
+		data, err = encodeYAML(records)
+	case TransferCSV:
+		data, err = encodeCSV(records)
+	}
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to encode procedures: " + err.Error(),
+			},
+		)
+	}
+	return data, nil
+}
+
+// Import создаёт или обновляет процедуры из файла по slug. Файл применяется целиком
+// или не применяется вовсе: ошибки всех строк возвращаются одним ответом с attr
+// вида rows[<индекс>].<поле>. Статусы проверяются по процессу публикации, см.
+// checkImportStatuses. При dryRun изменения проверяются, но не сохраняются.
+func (s *ProcedureService) Import(format string, data []byte, dryRun bool) (*models.ImportReport, error) {
+	if err := checkTransferFormat(format); err != nil {
+		return nil, err
+	}
+	var (
+		records []models.ProcedureRecord
+		details []errors.ErrorDetail
+		err     error
+	)
+	switch format {
+	case TransferJSON:
+		records, err = decodeJSONRecords(data)
+	case TransferYAML:
+		records, err = decodeYAMLRecords(data)
+	case TransferCSV:
+		records, details, err = decodeCSVRecords(data)
+	}
+	if err != nil {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ParseErrorCode,
+				Detail: fmt.Sprintf("invalid %s: %v", format, err),
+			},
+		)
+	}
+	if len(records) == 0 && len(details) == 0 {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "file contains no procedures",
+			},
+		)
+	}
+
+	procedures, rowDetails, status := s.validateRecords(records, func(i int) string {
+		return fmt.Sprintf("rows[%d]", i)
+	}, map[string]string{})
+	details = append(details, rowDetails...)
+	if len(details) > 0 {
+		if len(rowDetails) == 0 {
+			status = 400
+		}
+		return nil, errors.NewError(status, details...)
+	}
+
+	if details, err := s.checkImportStatuses(procedures); err != nil {
+		return nil, err
+	} else if len(details) > 0 {
+		return nil, errors.NewError(409, details...)
+	}
+
+	rows, err := s.repo.Import(procedures, dryRun)
+	if err != nil {
+		var rowErr *repository.ImportRowError
+		if stderrors.As(err, &rowErr) {
+			if hierarchyErr, ok := hierarchyError(rowErr.Err).(*errors.Error); ok {
+				for i := range hierarchyErr.ErrorDetail {
+					hierarchyErr.ErrorDetail[i].Attr = importRowAttr(rowErr.Index, "parent")
+				}
+				return nil, hierarchyErr
+			}
+		}
+		var missing *repository.UnknownAttachmentsError
+		if stderrors.As(err, &missing) {
+			return nil, errors.NewError(
+				422,
+				errors.ErrorDetail{
+					Code:   errors.ValidationErrorCode,
+					Detail: err.Error(),
+					Attr:   "content",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to import procedures: " + err.Error(),
+			},
+		)
+	}
+	report := &models.ImportReport{DryRun: dryRun, Rows: rows}
+	for _, row := range rows {
+		switch row.Action {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		case models.ImportUnchanged:
+			report.Unchanged++
+		}
+	}
+	return report, nil
+}
+
+// checkImportStatuses не даёт импорту обойти процесс публикации: статус существующей
+// процедуры меняется только по разрешённому переходу, а новая, как и при создании,
+// может быть только черновиком или на проверке
+func (s *ProcedureService) checkImportStatuses(procedures []models.Procedure) ([]errors.ErrorDetail, error) {
+	existing, err := s.repo.GetAllAnyStatus()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedures: " + err.Error(),
+			},
+		)
+	}
+	current := make(map[string]models.ProcedureStatus, len(existing))
+	for _, procedure := range existing {
+		current[procedure.Slug] = procedure.Status
+	}
+	var details []errors.ErrorDetail
+	for i, procedure := range procedures {
+		from, ok := current[procedure.Slug]
+		switch {
+		case !ok && procedure.Status != models.ProcedureDraft && procedure.Status != models.ProcedureInReview:
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.InvalidTransitionCode,
+				Detail: "new procedure must be imported as draft or in_review",
+				Attr:   importRowAttr(i, "status"),
+			})
+		case ok && from != procedure.Status && !canTransition(from, procedure.Status):
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.InvalidTransitionCode,
+				Detail: fmt.Sprintf("transition from %s to %s is not allowed", from, procedure.Status),
+				Attr:   importRowAttr(i, "status"),
+			})
+		}
+	}
+	return details, nil
+}
+
+// validateRecords проверяет записи файла и возвращает процедуры для сохранения.
+// location(i) — адрес записи в attr ошибок, seen — уже встреченные slug с адресами,
+// чтобы находить повторы в том числе между файлами. Статус ошибок — 422, если все они
+// смысловые (например, неизвестный тип), иначе 400.
+func (s *ProcedureService) validateRecords(
+	records []models.ProcedureRecord,
+	location func(int) string,
+	seen map[string]string,
+) ([]models.Procedure, []errors.ErrorDetail, int) {
+	procedures := make([]models.Procedure, len(records))
+	var details []errors.ErrorDetail
+	semantic := true
+	for i, record := range records {
+		procedures[i] = record.Procedure()
+		rowDetails, status := s.validateImportRow(&procedures[i])
+		if first, ok := seen[record.Slug]; ok && record.Slug != "" {
+			rowDetails = append(rowDetails, errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("slug %q is already used in %s", record.Slug, first),
+				Attr:   "slug",
+			})
+			status = 400
+		} else {
+			seen[record.Slug] = location(i)
+		}
+		if len(rowDetails) > 0 && status != 422 {
+			semantic = false
+		}
+		for _, detail := range rowDetails {
+			attr := location(i)
+			if detail.Attr != "" {
+				attr += "." + detail.Attr
+			}
+			detail.Attr = attr
+			details = append(details, detail)
+		}
+	}
+	if semantic {
+		return procedures, details, 422
+	}
+	return procedures, details, 400
+}
+
+// validateImportRow проверяет строку импорта и возвращает ошибки вместе с их статусом
+func (s *ProcedureService) validateImportRow(procedure *models.Procedure) ([]errors.ErrorDetail, int) {
+	var details []errors.ErrorDetail
+	if procedure.Slug == "" {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: "slug is required",
+			Attr:   "slug",
+		})
+	}
+	if procedure.ParentSlug != "" && !procedureSlugPattern.MatchString(procedure.ParentSlug) {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("invalid parent slug %q", procedure.ParentSlug),
+			Attr:   "parent",
+		})
+	}
+	if procedure.Status == "" {
+		procedure.Status = models.ProcedureDraft
+	}
+	if !procedure.Status.IsValid() {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("unknown status %q", procedure.Status),
+			Attr:   "status",
+		})
+	}
+	status := 400
+	if err := s.validate(procedure); err != nil {
+		appErr, ok := err.(*errors.Error)
+		if !ok {
+			return append(details, errors.ErrorDetail{Code: errors.ServerErrorCode, Detail: err.Error()}), 500
+		}
+		if len(details) == 0 {
+			status = appErr.StatusCode
+		}
+		details = append(details, appErr.ErrorDetail...)
+	}
+	return details, status
+}
+
+func importRowAttr(index int, attr string) string {
+	if attr == "" {
+		return fmt.Sprintf("rows[%d]", index)
+	}
+	return fmt.Sprintf("rows[%d].%s", index, attr)
+}
+
+func checkTransferFormat(format string) error {
+	if _, ok := TransferContentTypes[format]; ok {
+		return nil
+	}
+	return errors.NewError(
+		400,
+		errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("unknown format %q, expected json, yaml or csv", format),
+			Attr:   "format",
+		},
+	)
+}
+
+func decodeJSONRecords(data []byte) ([]models.ProcedureRecord, error) {
+	var records []models.ProcedureRecord
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&records); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after procedures list")
+	}
+	return records, nil
+}
+
+// decodeYAMLRecords разбирает YAML через JSON, чтобы имена полей и проверки
+// совпадали с JSON-форматом
+func decodeYAMLRecords(data []byte) ([]models.ProcedureRecord, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	converted, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONRecords(converted)
+}
+
+// encodeYAML переводит JSON-представление в блочный YAML с сохранением порядка полей
+func encodeYAML(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+func encodeCSV(records []models.ProcedureRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(procedureCSVColumns); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		content, err := json.Marshal(record.Content)
+		if err != nil {
+			return nil, err
+		}
+		err = w.Write([]string{
+			record.Slug,
+			record.Parent,
+			record.Title,
+			record.Type,
+			string(record.Status),
+			strconv.Itoa(record.SortOrder),
+			strconv.FormatBool(record.IsExpanded),
+			formatCSVTime(record.PublishAt),
+			formatCSVTime(record.UnpublishAt),
+			string(content),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// decodeCSVRecords разбирает CSV с заголовком. Ошибки структуры файла возвращаются
+// как error, ошибки значений в ячейках — как детали с attr по строке и колонке.
+func decodeCSVRecords(data []byte) ([]models.ProcedureRecord, []errors.ErrorDetail, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !slices.Contains(procedureCSVColumns, name) {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, nil, fmt.Errorf("duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"slug", "title", "type"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var (
+		records []models.ProcedureRecord
+		details []errors.ErrorDetail
+	)
+	for index := 0; ; index++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		invalid := func(name string, err error) {
+			details = append(details, errors.ErrorDetail{
+				Code:   errors.ParseErrorCode,
+				Detail: fmt.Sprintf("invalid %s: %v", name, err),
+				Attr:   importRowAttr(index, name),
+			})
+		}
+
+		record := models.ProcedureRecord{
+			Slug:   cell("slug"),
+			Parent: cell("parent"),
+			Title:  cell("title"),
+			Type:   cell("type"),
+			Status: models.ProcedureStatus(cell("status")),
+		}
+		if raw := cell("sort_order"); raw != "" {
+			if record.SortOrder, err = strconv.Atoi(raw); err != nil {
+				invalid("sort_order", err)
+			}
+		}
+		if raw := cell("is_expanded"); raw != "" {
+			if record.IsExpanded, err = strconv.ParseBool(raw); err != nil {
+				invalid("is_expanded", err)
+			}
+		}
+		if record.PublishAt, err = parseCSVTime(cell("publish_at")); err != nil {
+			invalid("publish_at", err)
+		}
+		if record.UnpublishAt, err = parseCSVTime(cell("unpublish_at")); err != nil {
+			invalid("unpublish_at", err)
+		}
+		record.Content = models.ProcedureContent{}
+		if raw := cell("content"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &record.Content); err != nil {
+				invalid("content", err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, details, nil
+}
+
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseCSVTime(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestProcedureService_ExportImportRoundTrip(t *testing.T) {
+	publishAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	parentID := 1
+	stored := []models.Procedure{
+		{
+			ID:        1,
+			Slug:      "loss_procedure",
+			Title:     "Порядок действий: утрата",
+			Type:      "manual",
+			Status:    models.ProcedurePublished,
+			SortOrder: 1,
+			PublishAt: &publishAt,
+			Content: models.ProcedureContent{
+				{Type: models.ContentItemText, Value: "обратиться в поддержку\nи подать заявление"},
+				{Type: models.ContentItemLink, Value: "Форма", URL: "https://example.com/form"},
+			},
+		},
+		{
+			ID:         2,
+			Slug:       "true",
+			Title:      "123",
+			Type:       "manual",
+			Status:     models.ProcedureDraft,
+			SortOrder:  2,
+			IsExpanded: true,
+			Content:    models.ProcedureContent{},
+		},
+		{
+			ID:        3,
+			Slug:      "loss_photos",
+			ParentID:  &parentID,
+			Title:     "Фотографии",
+			Type:      "manual",
+			Status:    models.ProcedureDraft,
+			SortOrder: 3,
+			Content:   models.ProcedureContent{},
+		},
+	}
+	var imported []models.Procedure
+	repo := &mocks.ProcedureRepoMock{
+		GetAllAnyStatusFn: func() ([]models.Procedure, error) {
+			return stored, nil
+		},
+		ImportFn: func(procedures []models.Procedure, dryRun bool) ([]models.ImportRow, error) {
+			require.True(t, dryRun)
+			imported = procedures
+			rows := make([]models.ImportRow, len(procedures))
+			for i, procedure := range procedures {
+				rows[i] = models.ImportRow{Index: i, Slug: procedure.Slug, ID: i + 1, Action: models.ImportUnchanged}
+			}
+			return rows, nil
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+	for _, format := range []string{TransferJSON, TransferYAML, TransferCSV} {
+		t.Run(format, func(t *testing.T) {
+			data, err := service.Export(format)
+			require.NoError(t, err)
+
+			report, err := service.Import(format, data, true)
+			require.NoError(t, err)
+			require.True(t, report.DryRun)
+			require.Equal(t, 3, report.Unchanged)
+
+			require.Len(t, imported, 3)
+			for i := range stored {
+				require.Equal(t, models.NewProcedureRecord(stored[i]), models.NewProcedureRecord(imported[i]))
+			}
+			require.Empty(t, imported[0].ParentSlug)
+			require.Equal(t, "loss_procedure", imported[2].ParentSlug)
+		})
+	}
+}
+
+func TestProcedureService_ImportValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		data       string
+		wantStatus int
+		wantAttrs  []string
+	}{
+		{
+			name:       "unknown format",
+			format:     "xml",
+			data:       `<procedures/>`,
+			wantStatus: 400,
+			wantAttrs:  []string{"format"},
+		},
+		{
+			name:       "malformed json",
+			format:     TransferJSON,
+			data:       `[{"slug": "a"`,
+			wantStatus: 400,
+		},
+		{
+			name:       "unknown field",
+			format:     TransferYAML,
+			data:       "- slug: a\n  title: A\n  type: manual\n  id: 5\n",
+			wantStatus: 400,
+		},
+		{
+			name:   "errors of every row",
+			format: TransferJSON,
+			data: `[
+				{"slug": "a", "title": "A", "type": "manual"},
+				{"slug": "", "title": "", "type": "manual"},
+				{"slug": "a", "title": "A2", "type": "manual", "status": "gone"}
+			]`,
+			wantStatus: 400,
+			wantAttrs:  []string{"rows[1].slug", "rows[1].title", "rows[2].status", "rows[2].slug"},
+		},
+		{
+			name:       "unknown type only",
+			format:     TransferJSON,
+			data:       `[{"slug": "a", "title": "A", "type": "missing"}]`,
+			wantStatus: 422,
+			wantAttrs:  []string{"rows[0].type"},
+		},
+		{
+			name:   "bad csv cells",
+			format: TransferCSV,
+			data: "slug,title,type,sort_order,publish_at\n" +
+				"a,A,manual,first,\n" +
+				"b,B,manual,2,yesterday\n",
+			wantStatus: 400,
+			wantAttrs:  []string{"rows[0].sort_order", "rows[1].publish_at"},
+		},
+		{
+			name:       "csv without required column",
+			format:     TransferCSV,
+			data:       "slug,title\na,A\n",
+			wantStatus: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.ProcedureRepoMock{
+				ImportFn: func([]models.Procedure, bool) ([]models.ImportRow, error) {
+					t.Fatal("invalid file must not reach the repository")
+					return nil, nil
+				},
+			}
+			service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+			_, err := service.Import(tt.format, []byte(tt.data), false)
+
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, tt.wantStatus, appErr.StatusCode)
+			attrs := make([]string, 0, len(appErr.ErrorDetail))
+			for _, detail := range appErr.ErrorDetail {
+				if detail.Attr != "" {
+					attrs = append(attrs, detail.Attr)
+				}
+			}
+			if tt.wantAttrs != nil {
+				require.Equal(t, tt.wantAttrs, attrs)
+			}
+		})
+	}
+}
+
+func TestProcedureService_ImportStatuses(t *testing.T) {
+	repo := &mocks.ProcedureRepoMock{
+		GetAllAnyStatusFn: func() ([]models.Procedure, error) {
+			return []models.Procedure{
+				{ID: 1, Slug: "archived", Status: models.ProcedureArchived},
+				{ID: 2, Slug: "draft", Status: models.ProcedureDraft},
+			}, nil
+		},
+		ImportFn: func([]models.Procedure, bool) ([]models.ImportRow, error) {
+			t.Fatal("file with forbidden statuses must not reach the repository")
+			return nil, nil
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+	_, err := service.Import(TransferJSON, []byte(`[
+		{"slug": "archived", "title": "A", "type": "manual", "status": "published"},
+		{"slug": "draft", "title": "D", "type": "manual", "status": "in_review"},
+		{"slug": "new", "title": "N", "type": "manual", "status": "published"}
+	]`), false)
+
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 409, appErr.StatusCode)
+	require.Len(t, appErr.ErrorDetail, 2)
+	require.Equal(t, "rows[0].status", appErr.ErrorDetail[0].Attr)
+	require.Equal(t, "rows[2].status", appErr.ErrorDetail[1].Attr)
+	require.Equal(t, appErrors.InvalidTransitionCode, appErr.ErrorDetail[0].Code)
+}
+
+func TestProcedureService_ImportParentError(t *testing.T) {
+	repo := &mocks.ProcedureRepoMock{
+		GetAllAnyStatusFn: func() ([]models.Procedure, error) {
+			return nil, nil
+		},
+		ImportFn: func([]models.Procedure, bool) ([]models.ImportRow, error) {
+			return nil, &repository.ImportRowError{Index: 1, Err: repository.ErrParentCycle}
+		},
+	}
+	service := NewProcedureService(repo, newTypeRepoMock("manual"))
+
+	_, err := service.Import(TransferJSON, []byte(`[
+		{"slug": "a", "parent": "b", "title": "A", "type": "manual"},
+		{"slug": "b", "parent": "a", "title": "B", "type": "manual"}
+	]`), false)
+
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 422, appErr.StatusCode)
+	require.Equal(t, "rows[1].parent", appErr.ErrorDetail[0].Attr)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+)
+
+var procedureTypeSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:_[a-z0-9]+)*$`)
+
+type ProcedureTypeService struct {
+	repo repository.ProcedureTypeRepos
+}
+
+func NewProcedureTypeService(repo repository.ProcedureTypeRepos) *ProcedureTypeService {
+	return &ProcedureTypeService{repo: repo}
+}
+
+func (s *ProcedureTypeService) GetAll() ([]models.ProcedureType, error) {
+	types, err := s.repo.GetAll()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure types: " + err.Error(),
+			},
+		)
+	}
+	return types, nil
+}
+
+func (s *ProcedureTypeService) GetBySlug(slug string) (*models.ProcedureType, error) {
+	procedureType, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure type not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure type: " + err.Error(),
+			},
+		)
+	}
+	return procedureType, nil
+}
+
+func (s *ProcedureTypeService) Create(procedureType *models.ProcedureType) error {
+	if !procedureTypeSlugPattern.MatchString(procedureType.Slug) {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "slug must contain lowercase latin letters, digits and underscores",
+				Attr:   "slug",
+			},
+		)
+	}
+	if procedureType.DisplayName == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "display_name is required",
+				Attr:   "display_name",
+			},
+		)
+	}
+	err := s.repo.Create(procedureType)
+	if err != nil {
+		if err == errors.ErrConflict {
+			return errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "procedure type already exists",
+					Attr:   "slug",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to create procedure type: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+func (s *ProcedureTypeService) Update(procedureType *models.ProcedureType) error {
+	if procedureType.DisplayName == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "display_name is required",
+				Attr:   "display_name",
+			},
+		)
+	}
+	err := s.repo.Update(procedureType)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure type not found",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to update procedure type: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+func (s *ProcedureTypeService) Delete(slug string) error {
+	err := s.repo.Delete(slug)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure type not found",
+				},
+			)
+		}
+		if err == errors.ErrConflict {
+			return errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "procedure type is used by procedures",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to delete procedure type: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// checkProcedureType проверяет, что slug есть в справочнике типов.
+// Для неизвестного типа возвращает ошибку со статусом status и списком
+// допустимых значений в Errors["valid_types"].
+func checkProcedureType(types repository.ProcedureTypeRepos, slug string, status int) error {
+	_, err := types.GetBySlug(slug)
+	if err == nil {
+		return nil
+	}
+	if err != errors.ErrNotFound {
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure type: " + err.Error(),
+			},
+		)
+	}
+	all, err := types.GetAll()
+	if err != nil {
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedure types: " + err.Error(),
+			},
+		)
+	}
+	slugs := make([]string, 0, len(all))
+	for _, t := range all {
+		slugs = append(slugs, t.Slug)
+	}
+	code := errors.ValidationErrorCode
+	if status == 404 {
+		code = errors.NotFoundCode
+	}
+	appErr := errors.NewError(
+		status,
+		errors.ErrorDetail{
+			Code:   code,
+			Detail: fmt.Sprintf("unknown procedure type %q, valid types: %s", slug, strings.Join(slugs, ", ")),
+			Attr:   "type",
+		},
+	)
+	appErr.Errors["valid_types"] = slugs
+	return appErr
+}
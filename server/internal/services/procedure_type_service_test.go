@@ -0,0 +1,74 @@
+package services
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestProcedureTypeService_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		procType   models.ProcedureType
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name:     "success",
+			procType: models.ProcedureType{Slug: "customs_docs", DisplayName: "Таможенные документы"},
+		},
+		{
+			name:       "invalid slug",
+			procType:   models.ProcedureType{Slug: "Customs Docs", DisplayName: "Таможенные документы"},
+			wantStatus: 400,
+		},
+		{
+			name:       "missing display name",
+			procType:   models.ProcedureType{Slug: "customs_docs"},
+			wantStatus: 400,
+		},
+		{
+			name:       "duplicate slug",
+			procType:   models.ProcedureType{Slug: "loss_procedure", DisplayName: "Утрата"},
+			repoErr:    appErrors.ErrConflict,
+			wantStatus: 409,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.ProcedureTypeRepoMock{
+				CreateFn: func(*models.ProcedureType) error {
+					return tt.repoErr
+				},
+			}
+			service := NewProcedureTypeService(repo)
+			err := service.Create(&tt.procType)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProcedureTypeService_Delete_InUse(t *testing.T) {
+	repo := &mocks.ProcedureTypeRepoMock{
+		DeleteFn: func(string) error {
+			return appErrors.ErrConflict
+		},
+	}
+	service := NewProcedureTypeService(repo)
+	err := service.Delete("loss_procedure")
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 409, appErr.StatusCode)
+	require.Equal(t, appErrors.ConflictCode, appErr.ErrorDetail[0].Code)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"reflect"
+	"sort"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"time"
+)
+
+// revisionDiffIgnored — служебные поля, не участвующие в сравнении ревизий
+var revisionDiffIgnored = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+	"deleted_at": true,
+}
+
+type RevisionService struct {
+	repo       repository.RevisionRepos
+	procedures repository.ProcedureRepos
+}
+
+func NewRevisionService(repo repository.RevisionRepos, procedures repository.ProcedureRepos) *RevisionService {
+	return &RevisionService{repo: repo, procedures: procedures}
+}
+
+func (s *RevisionService) GetByProcedure(procedureID int) ([]models.ProcedureRevision, error) {
+	revisions, err := s.repo.GetByProcedure(procedureID)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get revisions: " + err.Error(),
+			},
+		)
+	}
+	if len(revisions) == 0 {
+		return nil, errors.NewError(
+			404,
+			errors.ErrorDetail{
+				Code:   errors.NotFoundCode,
+				Detail: "procedure not found",
+			},
+		)
+	}
+	return revisions, nil
+}
+
+func (s *RevisionService) Get(procedureID, revision int) (*models.ProcedureRevision, error) {
+	rev, err := s.repo.Get(procedureID, revision)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "revision not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get revision: " + err.Error(),
+			},
+		)
+	}
+	return rev, nil
+}
+
+// Diff возвращает список полей, отличающихся между ревизиями from и to
+func (s *RevisionService) Diff(procedureID, from, to int) (*models.RevisionDiff, error) {
+	fromRev, err := s.Get(procedureID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.Get(procedureID, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diffSnapshots(fromRev.Snapshot.Procedure, toRev.Snapshot.Procedure)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to diff revisions: " + err.Error(),
+			},
+		)
+	}
+	return &models.RevisionDiff{
+		ProcedureID: procedureID,
+		From:        from,
+		To:          to,
+		Changes:     changes,
+	}, nil
+}
+
+func (s *RevisionService) Restore(procedureID, revision int) (*models.Procedure, error) {
+	procedure, err := s.procedures.RestoreRevision(procedureID, revision)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "revision not found",
+				},
+			)
+		}
+		var missing *repository.UnknownAttachmentsError
+		if stderrors.As(err, &missing) {
+			return nil, unknownAttachmentsError(missing)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to restore revision: " + err.Error(),
+			},
+		)
+	}
+	return procedure, nil
+}
+
+func (s *RevisionService) GetAllAsOf(asOf time.Time) ([]models.Procedure, error) {
+	procedures, err := s.repo.GetAllAsOf(asOf.UTC())
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get procedures as of " + asOf.Format(time.RFC3339) + ": " + err.Error(),
+			},
+		)
+	}
+	return procedures, nil
+}
+
+func diffSnapshots(from, to models.Procedure) ([]models.FieldChange, error) {
+	fromFields, err := snapshotFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := snapshotFields(to)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(toFields))
+	for name := range toFields {
+		names = append(names, name)
+	}
+	for name := range fromFields {
+		if _, ok := toFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]models.FieldChange, 0)
+	for _, name := range names {
+		if revisionDiffIgnored[name] {
+			continue
+		}
+		if !reflect.DeepEqual(fromFields[name], toFields[name]) {
+			changes = append(changes, models.FieldChange{
+				Field: name,
+				From:  fromFields[name],
+				To:    toFields[name],
+			})
+		}
+	}
+	return changes, nil
+}
+
+func snapshotFields(procedure models.Procedure) (map[string]interface{}, error) {
+	data, err := json.Marshal(procedure)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
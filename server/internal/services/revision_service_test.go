@@ -0,0 +1,151 @@
+package services
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestRevisionService_GetByProcedure(t *testing.T) {
+	tests := []struct {
+		name       string
+		revisions  []models.ProcedureRevision
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name: "success",
+			revisions: []models.ProcedureRevision{
+				{ProcedureID: 1, Revision: 2, Action: models.RevisionUpdate},
+				{ProcedureID: 1, Revision: 1, Action: models.RevisionCreate},
+			},
+		},
+		{
+			name:       "no revisions",
+			wantStatus: 404,
+		},
+		{
+			name:       "repository error",
+			repoErr:    stderrors.New("db error"),
+			wantStatus: 500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.RevisionRepoMock{
+				GetByProcedureFn: func(id int) ([]models.ProcedureRevision, error) {
+					return tt.revisions, tt.repoErr
+				},
+			}
+			service := NewRevisionService(repo, &mocks.ProcedureRepoMock{})
+			res, err := service.GetByProcedure(1)
+			if tt.wantStatus != 0 {
+				require.Error(t, err)
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, res, len(tt.revisions))
+		})
+	}
+}
+
+func TestRevisionService_Diff(t *testing.T) {
+	snapshots := map[int]models.Procedure{
+		1: {
+			ID:      1,
+			Title:   "Порядок действий",
+			Type:    "loss_procedure",
+			Content: models.ProcedureContent{{Type: models.ContentItemStep, Value: "обратиться в службу поддержки"}},
+		},
+		2: {
+			ID:         1,
+			Title:      "Порядок действий в случае утраты",
+			Type:       "loss_procedure",
+			Content:    models.ProcedureContent{{Type: models.ContentItemStep, Value: "подать заявление на розыск"}},
+			IsExpanded: true,
+		},
+	}
+	repo := &mocks.RevisionRepoMock{
+		GetFn: func(procedureID, revision int) (*models.ProcedureRevision, error) {
+			snapshot, ok := snapshots[revision]
+			if !ok {
+				return nil, appErrors.ErrNotFound
+			}
+			return &models.ProcedureRevision{
+				ProcedureID: procedureID,
+				Revision:    revision,
+				Snapshot:    models.ProcedureSnapshot{Procedure: snapshot},
+			}, nil
+		},
+	}
+	service := NewRevisionService(repo, &mocks.ProcedureRepoMock{})
+
+	diff, err := service.Diff(1, 1, 2)
+	require.NoError(t, err)
+	fields := make([]string, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		fields = append(fields, change.Field)
+	}
+	require.Equal(t, []string{"content", "is_expanded", "title"}, fields)
+	require.Equal(t, "Порядок действий", diff.Changes[2].From)
+	require.Equal(t, "Порядок действий в случае утраты", diff.Changes[2].To)
+
+	_, err = service.Diff(1, 1, 3)
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 404, appErr.StatusCode)
+}
+
+func TestRevisionService_Restore(t *testing.T) {
+	tests := []struct {
+		name       string
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:       "revision not found",
+			repoErr:    appErrors.ErrNotFound,
+			wantStatus: 404,
+		},
+		{
+			name:       "attachment deleted since the revision",
+			repoErr:    &repository.UnknownAttachmentsError{IDs: []int{4}},
+			wantStatus: 422,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procedures := &mocks.ProcedureRepoMock{
+				RestoreRevisionFn: func(procedureID, revision int) (*models.Procedure, error) {
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.Procedure{ID: procedureID}, nil
+				},
+			}
+			service := NewRevisionService(&mocks.RevisionRepoMock{}, procedures)
+			res, err := service.Restore(1, 2)
+			if tt.wantStatus != 0 {
+				require.Error(t, err)
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, res.ID)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"log"
+	"tech-quest/internal/repository"
+	"time"
+)
+
+// SearchRequestMonitor периодически отмечает просроченные запросы на розыск и
+// эскалирует их: первый раз — как только истёк срок ответа, затем каждые
+// escalationInterval, пока запрос открыт. Нулевой escalationInterval оставляет
+// одну эскалацию.
+type SearchRequestMonitor struct {
+	repo               repository.SearchRequestRepos
+	interval           time.Duration
+	escalationInterval time.Duration
+}
+
+func NewSearchRequestMonitor(repo repository.SearchRequestRepos, interval, escalationInterval time.Duration) *SearchRequestMonitor {
+	return &SearchRequestMonitor{repo: repo, interval: interval, escalationInterval: escalationInterval}
+}
+
+// Start запускает проверку в отдельной горутине до отмены ctx
+func (m *SearchRequestMonitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		m.RunOnce(time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				m.RunOnce(now)
+			}
+		}
+	}()
+}
+
+func (m *SearchRequestMonitor) RunOnce(now time.Time) {
+	escalated, err := m.repo.Escalate(now.UTC(), m.escalationInterval)
+	if err != nil {
+		log.Printf("search request monitor: %v", err)
+		return
+	}
+	for _, request := range escalated {
+		log.Printf(
+			"search request monitor: request %d for %s is overdue since %s, escalation level %d",
+			request.ID,
+			request.TrackingNumber,
+			request.DueAt.Format(time.RFC3339),
+			request.EscalationLevel,
+		)
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+)
+
+func TestSearchRequestMonitor_RunOnce(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	var gotNow time.Time
+	var gotInterval time.Duration
+	repo := &mocks.SearchRequestRepoMock{
+		EscalateFn: func(now time.Time, interval time.Duration) ([]models.SearchRequest, error) {
+			gotNow, gotInterval = now, interval
+			return []models.SearchRequest{{ID: 1, TrackingNumber: "RA123456789RU", EscalationLevel: 1}}, nil
+		},
+	}
+
+	NewSearchRequestMonitor(repo, time.Minute, 72*time.Hour).RunOnce(now)
+	require.Equal(t, now.UTC(), gotNow)
+	require.Equal(t, time.UTC, gotNow.Location())
+	require.Equal(t, 72*time.Hour, gotInterval)
+}
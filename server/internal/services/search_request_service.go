@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"time"
+	"unicode/utf8"
+)
+
+const maxSearchResolutionChars = 2000
+
+type SearchRequestService struct {
+	repo repository.SearchRequestRepos
+	// sla — срок ответа клиенту для каждого вида розыска
+	sla map[models.SearchRequestKind]time.Duration
+}
+
+func NewSearchRequestService(repo repository.SearchRequestRepos, sla map[models.SearchRequestKind]time.Duration) *SearchRequestService {
+	return &SearchRequestService{repo: repo, sla: sla}
+}
+
+// List возвращает страницу запросов на розыск с фильтрами по статусу,
+// трек-номеру и просрочке
+func (s *SearchRequestService) List(filter models.SearchRequestFilter) (*models.SearchRequestPage, error) {
+	if filter.Limit < 0 || filter.Limit > maxPageLimit {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("limit must be between 1 and %d", maxPageLimit),
+				Attr:   "limit",
+			},
+		)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "status must be open, found or not_found",
+				Attr:   "status",
+			},
+		)
+	}
+	filter.TrackingNumber = normalizeTrackingNumber(filter.TrackingNumber)
+	page, err := s.repo.List(filter)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get search requests: " + err.Error(),
+			},
+		)
+	}
+	return page, nil
+}
+
+func (s *SearchRequestService) GetByID(id int) (*models.SearchRequest, error) {
+	request, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, searchRequestNotFoundError()
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get search request: " + err.Error(),
+			},
+		)
+	}
+	return request, nil
+}
+
+// Create регистрирует запрос на розыск. Срок ответа рассчитывается от момента
+// подачи по SLA вида розыска.
+func (s *SearchRequestService) Create(request *models.SearchRequest) error {
+	request.TrackingNumber = normalizeTrackingNumber(request.TrackingNumber)
+	request.ContactEmail = strings.TrimSpace(request.ContactEmail)
+	request.ContactPhone = phoneSeparators.Replace(strings.TrimSpace(request.ContactPhone))
+	if details := s.validate(request); len(details) > 0 {
+		return errors.NewError(400, details...)
+	}
+	if err := s.repo.Create(request, s.sla[request.Kind]); err != nil {
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to create search request: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// Resolve завершает открытый розыск результатом found или not_found.
+// Итог обязателен: его сообщают клиенту.
+func (s *SearchRequestService) Resolve(id int, resolution models.SearchRequestResolution) (*models.SearchRequest, error) {
+	text := strings.TrimSpace(resolution.Resolution)
+	if resolution.Status != models.SearchFound && resolution.Status != models.SearchNotFound {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "status must be found or not_found",
+				Attr:   "status",
+			},
+		)
+	}
+	switch {
+	case text == "":
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "resolution is required",
+				Attr:   "resolution",
+			},
+		)
+	case utf8.RuneCountInString(text) > maxSearchResolutionChars:
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("resolution must be at most %d characters", maxSearchResolutionChars),
+				Attr:   "resolution",
+			},
+		)
+	}
+	request, err := s.repo.Resolve(id, resolution.Status, text)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, searchRequestNotFoundError()
+		}
+		if err == errors.ErrConflict {
+			return nil, errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.InvalidTransitionCode,
+					Detail: "search request is already resolved",
+					Attr:   "status",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to resolve search request: " + err.Error(),
+			},
+		)
+	}
+	return request, nil
+}
+
+// validate возвращает ошибки по каждому неверному полю запроса на розыск
+func (s *SearchRequestService) validate(request *models.SearchRequest) []errors.ErrorDetail {
+	var details []errors.ErrorDetail
+	invalid := func(attr, detail string) {
+		details = append(details, errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: detail,
+			Attr:   attr,
+		})
+	}
+	if !trackingNumberPattern.MatchString(request.TrackingNumber) {
+		invalid("tracking_number", "tracking_number must contain 8 to 30 latin letters and digits")
+	}
+	if !request.Kind.IsValid() {
+		invalid("kind", "kind must be domestic or international")
+	}
+	if request.ContactEmail == "" && request.ContactPhone == "" {
+		invalid("contact_email", "contact_email or contact_phone is required")
+	}
+	if request.ContactEmail != "" && !validEmail(request.ContactEmail) {
+		invalid("contact_email", "contact_email must be a valid email address")
+	}
+	if request.ContactPhone != "" && !phonePattern.MatchString(request.ContactPhone) {
+		invalid("contact_phone", "contact_phone must contain 10 to 15 digits")
+	}
+	return details
+}
+
+func searchRequestNotFoundError() error {
+	return errors.NewError(
+		404,
+		errors.ErrorDetail{
+			Code:   errors.NotFoundCode,
+			Detail: "search request not found",
+		},
+	)
+}
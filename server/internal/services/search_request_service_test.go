@@ -0,0 +1,188 @@
+package services
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+var testSearchSLA = map[models.SearchRequestKind]time.Duration{
+	models.SearchDomestic:      30 * 24 * time.Hour,
+	models.SearchInternational: 60 * 24 * time.Hour,
+}
+
+func TestSearchRequestService_Create(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   models.SearchRequest
+		wantSLA   time.Duration
+		wantAttrs []string
+	}{
+		{
+			name: "domestic",
+			request: models.SearchRequest{
+				TrackingNumber: "1234 5678 9012 34",
+				Kind:           models.SearchDomestic,
+				ContactPhone:   "+7 (912) 345-67-89",
+			},
+			wantSLA: 30 * 24 * time.Hour,
+		},
+		{
+			name: "international",
+			request: models.SearchRequest{
+				TrackingNumber: "ra123456789ru",
+				Kind:           models.SearchInternational,
+				ContactEmail:   "client@example.com",
+			},
+			wantSLA: 60 * 24 * time.Hour,
+		},
+		{
+			name: "invalid fields",
+			request: models.SearchRequest{
+				TrackingNumber: "RA-1",
+				Kind:           "express",
+			},
+			wantAttrs: []string{"tracking_number", "kind", "contact_email"},
+		},
+		{
+			name: "invalid contacts",
+			request: models.SearchRequest{
+				TrackingNumber: "RA123456789RU",
+				Kind:           models.SearchDomestic,
+				ContactEmail:   "Client <client@example.com>",
+				ContactPhone:   "12345",
+			},
+			wantAttrs: []string{"contact_email", "contact_phone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSLA time.Duration
+			repo := &mocks.SearchRequestRepoMock{
+				CreateFn: func(r *models.SearchRequest, sla time.Duration) error {
+					gotSLA = sla
+					r.ID = 1
+					r.Status = models.SearchOpen
+					return nil
+				},
+			}
+			service := NewSearchRequestService(repo, testSearchSLA)
+
+			request := tt.request
+			err := service.Create(&request)
+			if len(tt.wantAttrs) == 0 {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantSLA, gotSLA)
+				require.Regexp(t, `^[A-Z0-9]+$`, request.TrackingNumber)
+				return
+			}
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, 400, appErr.StatusCode)
+			attrs := make([]string, len(appErr.ErrorDetail))
+			for i, detail := range appErr.ErrorDetail {
+				attrs[i] = detail.Attr
+			}
+			require.Equal(t, tt.wantAttrs, attrs)
+			require.Zero(t, gotSLA)
+		})
+	}
+}
+
+func TestSearchRequestService_ListOverdue(t *testing.T) {
+	var got models.SearchRequestFilter
+	repo := &mocks.SearchRequestRepoMock{
+		ListFn: func(filter models.SearchRequestFilter) (*models.SearchRequestPage, error) {
+			got = filter
+			return &models.SearchRequestPage{Items: []models.SearchRequest{}}, nil
+		},
+	}
+	service := NewSearchRequestService(repo, testSearchSLA)
+
+	_, err := service.List(models.SearchRequestFilter{Overdue: true, TrackingNumber: " ra 123456789 ru"})
+	require.NoError(t, err)
+	require.Equal(t, models.SearchRequestFilter{
+		Limit:          defaultPageLimit,
+		Overdue:        true,
+		TrackingNumber: "RA123456789RU",
+	}, got)
+
+	_, err = service.List(models.SearchRequestFilter{Status: "lost"})
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 400, appErr.StatusCode)
+}
+
+func TestSearchRequestService_Resolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolution models.SearchRequestResolution
+		repoErr    error
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "found",
+			resolution: models.SearchRequestResolution{Status: models.SearchFound, Resolution: "Отправление передано в доставку"},
+			wantCalled: true,
+		},
+		{
+			name:       "cannot reopen",
+			resolution: models.SearchRequestResolution{Status: models.SearchOpen, Resolution: "ещё ищем"},
+			wantStatus: 400,
+		},
+		{
+			name:       "resolution required",
+			resolution: models.SearchRequestResolution{Status: models.SearchNotFound, Resolution: " "},
+			wantStatus: 400,
+		},
+		{
+			name:       "already resolved",
+			resolution: models.SearchRequestResolution{Status: models.SearchNotFound, Resolution: "Не найдено"},
+			repoErr:    appErrors.ErrConflict,
+			wantStatus: 409,
+			wantCalled: true,
+		},
+		{
+			name:       "not found",
+			resolution: models.SearchRequestResolution{Status: models.SearchFound, Resolution: "Найдено"},
+			repoErr:    appErrors.ErrNotFound,
+			wantStatus: 404,
+			wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			repo := &mocks.SearchRequestRepoMock{
+				ResolveFn: func(id int, status models.SearchRequestStatus, resolution string) (*models.SearchRequest, error) {
+					called = true
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return &models.SearchRequest{ID: id, Status: status, Resolution: resolution}, nil
+				},
+			}
+			service := NewSearchRequestService(repo, testSearchSLA)
+
+			request, err := service.Resolve(1, tt.resolution)
+			require.Equal(t, tt.wantCalled, called)
+			if tt.wantStatus == 0 {
+				require.NoError(t, err)
+				require.Equal(t, tt.resolution.Status, request.Status)
+				return
+			}
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, tt.wantStatus, appErr.StatusCode)
+		})
+	}
+}
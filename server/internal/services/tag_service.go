@@ -0,0 +1,153 @@
+package services
+
+import (
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+)
+
+type TagService struct {
+	repo repository.TagRepos
+}
+
+func NewTagService(repo repository.TagRepos) *TagService {
+	return &TagService{repo: repo}
+}
+
+func (s *TagService) GetAll() ([]models.Tag, error) {
+	tags, err := s.repo.GetAll()
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get tags: " + err.Error(),
+			},
+		)
+	}
+	return tags, nil
+}
+
+func (s *TagService) GetBySlug(slug string) (*models.Tag, error) {
+	tag, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "tag not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get tag: " + err.Error(),
+			},
+		)
+	}
+	return tag, nil
+}
+
+func (s *TagService) Create(tag *models.Tag) error {
+	if len(tag.Slug) > 100 || !procedureSlugPattern.MatchString(tag.Slug) {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "slug must contain lowercase latin letters, digits, hyphens and underscores",
+				Attr:   "slug",
+			},
+		)
+	}
+	if tag.Name == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "name is required",
+				Attr:   "name",
+			},
+		)
+	}
+	err := s.repo.Create(tag)
+	if err != nil {
+		if err == errors.ErrConflict {
+			return errors.NewError(
+				409,
+				errors.ErrorDetail{
+					Code:   errors.ConflictCode,
+					Detail: "tag already exists",
+					Attr:   "slug",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to create tag: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+func (s *TagService) Update(tag *models.Tag) error {
+	if tag.Name == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "name is required",
+				Attr:   "name",
+			},
+		)
+	}
+	err := s.repo.Update(tag)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "tag not found",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to update tag: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// Delete удаляет тег; процедуры, которым он был назначен, теряют только этот тег
+func (s *TagService) Delete(slug string) error {
+	err := s.repo.Delete(slug)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "tag not found",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to delete tag: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
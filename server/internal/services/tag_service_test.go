@@ -0,0 +1,74 @@
+package services
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+)
+
+func TestTagService_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		tag        models.Tag
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name: "success",
+			tag:  models.Tag{Slug: "courier-delivery", Name: "Курьерская доставка"},
+		},
+		{
+			name:       "invalid slug",
+			tag:        models.Tag{Slug: "Courier Delivery", Name: "Курьерская доставка"},
+			wantStatus: 400,
+		},
+		{
+			name:       "missing name",
+			tag:        models.Tag{Slug: "urgent"},
+			wantStatus: 400,
+		},
+		{
+			name:       "duplicate slug",
+			tag:        models.Tag{Slug: "urgent", Name: "Срочно"},
+			repoErr:    appErrors.ErrConflict,
+			wantStatus: 409,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.TagRepoMock{
+				CreateFn: func(*models.Tag) error {
+					return tt.repoErr
+				},
+			}
+			service := NewTagService(repo)
+			err := service.Create(&tt.tag)
+			if tt.wantStatus != 0 {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, tt.wantStatus, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTagService_Delete_NotFound(t *testing.T) {
+	repo := &mocks.TagRepoMock{
+		DeleteFn: func(string) error {
+			return appErrors.ErrNotFound
+		},
+	}
+	service := NewTagService(repo)
+	err := service.Delete("urgent")
+	var appErr *appErrors.Error
+	require.True(t, stderrors.As(err, &appErr))
+	require.Equal(t, 404, appErr.StatusCode)
+	require.Equal(t, appErrors.NotFoundCode, appErr.ErrorDetail[0].Code)
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	stderrors "errors"
+	"fmt"
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/repository"
+	"tech-quest/pkg/errors"
+	"tech-quest/pkg/i18n"
+)
+
+type TranslationService struct {
+	repo       repository.TranslationRepos
+	negotiator *i18n.Negotiator
+}
+
+func NewTranslationService(repo repository.TranslationRepos, negotiator *i18n.Negotiator) *TranslationService {
+	return &TranslationService{repo: repo, negotiator: negotiator}
+}
+
+// Negotiate возвращает цепочку локалей для ответа. Неподдерживаемый ?lang= отклоняется.
+func (s *TranslationService) Negotiate(lang, acceptLanguage string) ([]string, error) {
+	if lang != "" && !s.negotiator.IsSupported(lang) {
+		return nil, errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: fmt.Sprintf("unsupported language %q", lang),
+				Attr:   "lang",
+			},
+		)
+	}
+	return s.negotiator.Chain(lang, acceptLanguage), nil
+}
+
+// DefaultLocale возвращает исходную локаль процедур
+func (s *TranslationService) DefaultLocale() string {
+	return s.negotiator.DefaultLocale()
+}
+
+// Localize подменяет title и content процедур первым найденным переводом из цепочки.
+// Если раньше перевода в цепочке встречается локаль по умолчанию, остаётся исходный текст.
+func (s *TranslationService) Localize(procedures []models.Procedure, chain []string) error {
+	defaultLocale := s.negotiator.DefaultLocale()
+	var locales []string
+	for _, locale := range chain {
+		if locale == defaultLocale {
+			break
+		}
+		locales = append(locales, locale)
+	}
+	if len(procedures) == 0 || len(locales) == 0 {
+		for i := range procedures {
+			procedures[i].Locale = defaultLocale
+		}
+		return nil
+	}
+
+	ids := make([]int, 0, len(procedures))
+	for _, procedure := range procedures {
+		ids = append(ids, procedure.ID)
+	}
+	translations, err := s.repo.GetForProcedures(ids, locales)
+	if err != nil {
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get translations: " + err.Error(),
+			},
+		)
+	}
+	byProcedure := make(map[int]map[string]models.ProcedureTranslation)
+	for _, translation := range translations {
+		if byProcedure[translation.ProcedureID] == nil {
+			byProcedure[translation.ProcedureID] = make(map[string]models.ProcedureTranslation)
+		}
+		byProcedure[translation.ProcedureID][translation.Locale] = translation
+	}
+
+	for i := range procedures {
+		procedures[i].Locale = defaultLocale
+		for _, locale := range locales {
+			translation, ok := byProcedure[procedures[i].ID][locale]
+			if !ok {
+				continue
+			}
+			procedures[i].Title = translation.Title
+			procedures[i].Content = translation.Content
+			procedures[i].Locale = locale
+			break
+		}
+	}
+	return nil
+}
+
+func (s *TranslationService) GetByProcedure(procedureID int) ([]models.ProcedureTranslation, error) {
+	translations, err := s.repo.GetByProcedure(procedureID)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get translations: " + err.Error(),
+			},
+		)
+	}
+	return translations, nil
+}
+
+func (s *TranslationService) Get(procedureID int, locale string) (*models.ProcedureTranslation, error) {
+	translation, err := s.repo.Get(procedureID, i18n.Normalize(locale))
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "translation not found",
+				},
+			)
+		}
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get translation: " + err.Error(),
+			},
+		)
+	}
+	return translation, nil
+}
+
+func (s *TranslationService) Upsert(translation *models.ProcedureTranslation) error {
+	translation.Locale = i18n.Normalize(translation.Locale)
+	if detail := s.validateLocale(translation.Locale); detail != nil {
+		return errors.NewError(400, *detail)
+	}
+	if translation.Title == "" {
+		return errors.NewError(
+			400,
+			errors.ErrorDetail{
+				Code:   errors.ValidationErrorCode,
+				Detail: "title is required",
+				Attr:   "title",
+			},
+		)
+	}
+	if details := validateContent(translation.Content); len(details) > 0 {
+		return errors.NewError(400, details...)
+	}
+	err := s.repo.Upsert(translation)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "procedure not found",
+				},
+			)
+		}
+		var missing *repository.UnknownAttachmentsError
+		if stderrors.As(err, &missing) {
+			return unknownAttachmentsError(missing)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to save translation: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+func (s *TranslationService) Delete(procedureID int, locale string) error {
+	err := s.repo.Delete(procedureID, i18n.Normalize(locale))
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewError(
+				404,
+				errors.ErrorDetail{
+					Code:   errors.NotFoundCode,
+					Detail: "translation not found",
+				},
+			)
+		}
+		return errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to delete translation: " + err.Error(),
+			},
+		)
+	}
+	return nil
+}
+
+// Missing возвращает отчёт о непереведённых процедурах по каждой поддерживаемой локали
+func (s *TranslationService) Missing() ([]models.MissingTranslations, error) {
+	var locales []string
+	for _, locale := range s.negotiator.Supported() {
+		if locale != s.negotiator.DefaultLocale() {
+			locales = append(locales, locale)
+		}
+	}
+	missing, err := s.repo.GetMissing(locales)
+	if err != nil {
+		return nil, errors.NewError(
+			500,
+			errors.ErrorDetail{
+				Code:   errors.ServerErrorCode,
+				Detail: "failed to get missing translations: " + err.Error(),
+			},
+		)
+	}
+	report := make([]models.MissingTranslations, 0, len(locales))
+	for _, locale := range locales {
+		procedures := missing[locale]
+		if procedures == nil {
+			procedures = []models.ProcedureRef{}
+		}
+		report = append(report, models.MissingTranslations{Locale: locale, Procedures: procedures})
+	}
+	return report, nil
+}
+
+func (s *TranslationService) validateLocale(locale string) *errors.ErrorDetail {
+	if !s.negotiator.IsSupported(locale) {
+		return &errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("unsupported locale %q", locale),
+			Attr:   "locale",
+		}
+	}
+	if locale == s.negotiator.DefaultLocale() {
+		return &errors.ErrorDetail{
+			Code:   errors.ValidationErrorCode,
+			Detail: fmt.Sprintf("%q is the source locale, edit the procedure itself", locale),
+			Attr:   "locale",
+		}
+	}
+	return nil
+}
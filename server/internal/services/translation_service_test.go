@@ -0,0 +1,143 @@
+package services
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"tech-quest/internal/domain/models"
+	"tech-quest/internal/services/mocks"
+	appErrors "tech-quest/pkg/errors"
+	"tech-quest/pkg/i18n"
+)
+
+func newTestNegotiator() *i18n.Negotiator {
+	return i18n.NewNegotiator("ru", []string{"ru", "en", "kk", "uz"}, []string{"en"})
+}
+
+func TestTranslationService_Negotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		lang           string
+		acceptLanguage string
+		want           []string
+		wantErr        bool
+	}{
+		{
+			name: "no preferences",
+			want: []string{"en", "ru"},
+		},
+		{
+			name:           "accept-language with weights and regions",
+			acceptLanguage: "de;q=0.9, kk-KZ, uz;q=0.5, *;q=0.1",
+			want:           []string{"kk", "uz", "en", "ru"},
+		},
+		{
+			name:           "lang overrides accept-language",
+			lang:           "uz",
+			acceptLanguage: "kk",
+			want:           []string{"uz", "en", "ru"},
+		},
+		{
+			name:    "unsupported lang",
+			lang:    "de",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTranslationService(&mocks.TranslationRepoMock{}, newTestNegotiator())
+			chain, err := service.Negotiate(tt.lang, tt.acceptLanguage)
+			if tt.wantErr {
+				var appErr *appErrors.Error
+				require.True(t, stderrors.As(err, &appErr))
+				require.Equal(t, 400, appErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, chain)
+		})
+	}
+}
+
+func TestTranslationService_Localize(t *testing.T) {
+	repo := &mocks.TranslationRepoMock{
+		GetForProceduresFn: func(ids []int, locales []string) ([]models.ProcedureTranslation, error) {
+			require.Equal(t, []string{"kk", "en"}, locales)
+			return []models.ProcedureTranslation{
+				{ProcedureID: 1, Locale: "en", Title: "Lost parcel"},
+				{ProcedureID: 1, Locale: "kk", Title: "Жоғалған сәлемдеме"},
+				{ProcedureID: 2, Locale: "en", Title: "Damaged parcel"},
+			}, nil
+		},
+	}
+	service := NewTranslationService(repo, newTestNegotiator())
+	procedures := []models.Procedure{
+		{ID: 1, Title: "Утрата"},
+		{ID: 2, Title: "Повреждение"},
+		{ID: 3, Title: "Информация"},
+	}
+	require.NoError(t, service.Localize(procedures, []string{"kk", "en", "ru"}))
+	require.Equal(t, "Жоғалған сәлемдеме", procedures[0].Title)
+	require.Equal(t, "kk", procedures[0].Locale)
+	require.Equal(t, "Damaged parcel", procedures[1].Title)
+	require.Equal(t, "en", procedures[1].Locale)
+	require.Equal(t, "Информация", procedures[2].Title)
+	require.Equal(t, "ru", procedures[2].Locale)
+}
+
+func TestTranslationService_Upsert_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		title    string
+		wantAttr string
+	}{
+		{
+			name:     "source locale",
+			locale:   "ru",
+			title:    "Утрата",
+			wantAttr: "locale",
+		},
+		{
+			name:     "unsupported locale",
+			locale:   "de",
+			title:    "Verlust",
+			wantAttr: "locale",
+		},
+		{
+			name:     "missing title",
+			locale:   "en",
+			wantAttr: "title",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewTranslationService(&mocks.TranslationRepoMock{}, newTestNegotiator())
+			err := service.Upsert(&models.ProcedureTranslation{ProcedureID: 1, Locale: tt.locale, Title: tt.title})
+			var appErr *appErrors.Error
+			require.True(t, stderrors.As(err, &appErr))
+			require.Equal(t, 400, appErr.StatusCode)
+			require.Equal(t, tt.wantAttr, appErr.ErrorDetail[0].Attr)
+		})
+	}
+}
+
+func TestTranslationService_Missing(t *testing.T) {
+	repo := &mocks.TranslationRepoMock{
+		GetMissingFn: func(locales []string) (map[string][]models.ProcedureRef, error) {
+			require.Equal(t, []string{"en", "kk", "uz"}, locales)
+			return map[string][]models.ProcedureRef{
+				"kk": {{ID: 1, Title: "Утрата", Type: "loss_procedure"}},
+			}, nil
+		},
+	}
+	service := NewTranslationService(repo, newTestNegotiator())
+	report, err := service.Missing()
+	require.NoError(t, err)
+	require.Len(t, report, 3)
+	require.Empty(t, report[0].Procedures)
+	require.Equal(t, "kk", report[1].Locale)
+	require.Len(t, report[1].Procedures, 1)
+}
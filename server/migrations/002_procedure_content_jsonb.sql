@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION procedure_content_to_jsonb(raw TEXT) RETURNS JSONB AS $$
+DECLARE
+    parsed JSONB;
+BEGIN
+    BEGIN
+        parsed := raw::jsonb;
+    EXCEPTION WHEN others THEN
+        parsed := to_jsonb(raw);
+    END;
+
+    IF jsonb_typeof(parsed) = 'string' THEN
+        parsed := jsonb_build_array(parsed);
+    END IF;
+    IF jsonb_typeof(parsed) <> 'array' THEN
+        RETURN '[]'::jsonb;
+    END IF;
+
+    RETURN COALESCE((
+        SELECT jsonb_agg(
+            CASE
+                WHEN jsonb_typeof(elem) = 'string'
+                    THEN jsonb_build_object('type', 'text', 'value', elem #>> '{}')
+                ELSE jsonb_build_object('type', COALESCE(NULLIF(elem ->> 'type', ''), 'text'))
+                         || (elem - 'type')
+            END
+            ORDER BY ord
+        )
+        FROM jsonb_array_elements(parsed) WITH ORDINALITY AS t(elem, ord)
+        WHERE jsonb_typeof(elem) IN ('string', 'object')
+    ), '[]'::jsonb);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE procedures ALTER COLUMN content DROP DEFAULT;
+ALTER TABLE procedures ALTER COLUMN content TYPE JSONB USING procedure_content_to_jsonb(content);
+ALTER TABLE procedures ALTER COLUMN content SET DEFAULT '[]'::jsonb;
+ALTER TABLE procedures
+    ADD CONSTRAINT procedures_content_is_array CHECK (jsonb_typeof(content) = 'array');
+
+DROP FUNCTION procedure_content_to_jsonb(TEXT);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE procedures DROP CONSTRAINT IF EXISTS procedures_content_is_array;
+ALTER TABLE procedures ALTER COLUMN content DROP DEFAULT;
+ALTER TABLE procedures ALTER COLUMN content TYPE TEXT USING content::text;
+ALTER TABLE procedures ALTER COLUMN content SET DEFAULT '[]';
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS procedure_revisions (
+                                                   id SERIAL PRIMARY KEY,
+                                                   procedure_id INTEGER NOT NULL,
+                                                   revision INTEGER NOT NULL,
+                                                   action VARCHAR(20) NOT NULL,
+                                                   snapshot JSONB NOT NULL,
+                                                   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                   UNIQUE (procedure_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_procedure_revisions_created_at ON procedure_revisions(created_at);
+
+INSERT INTO procedure_revisions (procedure_id, revision, action, snapshot, created_at)
+SELECT p.id,
+       1,
+       'create',
+       jsonb_build_object(
+               'id', p.id,
+               'title', p.title,
+               'type', p.type,
+               'content', p.content,
+               'sort_order', p.sort_order,
+               'is_expanded', p.is_expanded,
+               'created_at', to_char(p.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+               'updated_at', to_char(p.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+       ),
+       p.updated_at
+FROM procedures p;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS procedure_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE procedures ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE procedures ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE procedures
+    ADD CONSTRAINT procedures_status_check CHECK (status IN ('draft', 'in_review', 'published', 'archived'));
+ALTER TABLE procedures ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP NULL;
+ALTER TABLE procedures ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_procedures_status ON procedures(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_procedures_status;
+ALTER TABLE procedures DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE procedures DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE procedures DROP CONSTRAINT IF EXISTS procedures_status_check;
+ALTER TABLE procedures DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS procedure_translations (
+                                                      procedure_id INTEGER NOT NULL REFERENCES procedures(id) ON DELETE CASCADE,
+                                                      locale VARCHAR(10) NOT NULL,
+                                                      title VARCHAR(500) NOT NULL,
+                                                      content JSONB NOT NULL DEFAULT '[]'::jsonb,
+                                                      created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                      updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                      PRIMARY KEY (procedure_id, locale),
+                                                      CONSTRAINT procedure_translations_content_is_array CHECK (jsonb_typeof(content) = 'array')
+);
+
+CREATE INDEX IF NOT EXISTS idx_procedure_translations_locale ON procedure_translations(locale);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS procedure_translations;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS procedure_types (
+                                               slug VARCHAR(100) PRIMARY KEY,
+                                               display_name VARCHAR(255) NOT NULL,
+                                               description TEXT NOT NULL DEFAULT '',
+                                               icon VARCHAR(100) NOT NULL DEFAULT '',
+                                               sort_order INTEGER NOT NULL DEFAULT 0,
+                                               created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO procedure_types (slug, display_name, description, icon, sort_order) VALUES
+                                                                                   ('search_without_status', 'Розыск без статуса доставки', 'Документы для розыска посылок без статуса доставки', 'search', 1),
+                                                                                   ('loss_or_damage_docs', 'Документы при утрате или повреждении', 'Документы, необходимые при утрате или повреждении посылки', 'file-text', 2),
+                                                                                   ('damage_additional_docs', 'Дополнительные документы при повреждении', 'Дополнительные документы в случае повреждения посылки', 'file-plus', 3),
+                                                                                   ('loss_procedure', 'Утрата посылки', 'Порядок действий в случае утраты посылки', 'package-x', 4),
+                                                                                   ('damage_procedure', 'Повреждение посылки', 'Порядок действий в случае повреждения посылки', 'package-open', 5),
+                                                                                   ('recipient_info', 'Информация для получателя', 'Важная информация для получателя', 'info', 6)
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO procedure_types (slug, display_name, sort_order)
+SELECT DISTINCT type, type, 100
+FROM procedures
+ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE procedures
+    ADD CONSTRAINT procedures_type_fkey FOREIGN KEY (type) REFERENCES procedure_types(slug)
+        ON UPDATE CASCADE ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE procedures DROP CONSTRAINT IF EXISTS procedures_type_fkey;
+DROP TABLE IF EXISTS procedure_types;
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION procedure_content_text(content JSONB) RETURNS TEXT AS $$
+SELECT COALESCE(string_agg(elem ->> 'value', ' ' ORDER BY ord), '')
+FROM jsonb_array_elements(content) WITH ORDINALITY AS t(elem, ord)
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Документ для ts_headline: заголовок и текст содержимого с экранированным HTML,
+-- чтобы в сниппете безопасно использовались только теги <mark>
+CREATE OR REPLACE FUNCTION procedure_search_document(title TEXT, content JSONB) RETURNS TEXT AS $$
+SELECT replace(replace(replace(title || '. ' || procedure_content_text(content), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION locale_search_config(locale TEXT) RETURNS regconfig AS $$
+SELECT CASE split_part(lower(locale), '-', 1)
+           WHEN 'ru' THEN 'pg_catalog.russian'::regconfig
+           WHEN 'en' THEN 'pg_catalog.english'::regconfig
+           ELSE 'pg_catalog.simple'::regconfig
+           END
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE procedures ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE procedure_translations ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION procedures_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+            setweight(to_tsvector('pg_catalog.russian', COALESCE(NEW.title, '')), 'A') ||
+            setweight(to_tsvector('pg_catalog.russian', procedure_content_text(NEW.content)), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION procedure_translations_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+            setweight(to_tsvector(locale_search_config(NEW.locale), COALESCE(NEW.title, '')), 'A') ||
+            setweight(to_tsvector(locale_search_config(NEW.locale), procedure_content_text(NEW.content)), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER procedures_search_vector
+    BEFORE INSERT OR UPDATE OF title, content ON procedures
+    FOR EACH ROW EXECUTE FUNCTION procedures_search_vector_update();
+
+CREATE TRIGGER procedure_translations_search_vector
+    BEFORE INSERT OR UPDATE OF locale, title, content ON procedure_translations
+    FOR EACH ROW EXECUTE FUNCTION procedure_translations_search_vector_update();
+
+UPDATE procedures SET title = title;
+UPDATE procedure_translations SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_procedures_search_vector ON procedures USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_procedure_translations_search_vector ON procedure_translations USING GIN(search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_procedure_translations_search_vector;
+DROP INDEX IF EXISTS idx_procedures_search_vector;
+DROP TRIGGER IF EXISTS procedure_translations_search_vector ON procedure_translations;
+DROP TRIGGER IF EXISTS procedures_search_vector ON procedures;
+DROP FUNCTION IF EXISTS procedure_translations_search_vector_update();
+DROP FUNCTION IF EXISTS procedures_search_vector_update();
+ALTER TABLE procedure_translations DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE procedures DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS locale_search_config(TEXT);
+DROP FUNCTION IF EXISTS procedure_search_document(TEXT, JSONB);
+DROP FUNCTION IF EXISTS procedure_content_text(JSONB);
+-- +goose StatementEnd
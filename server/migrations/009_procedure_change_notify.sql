@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Уведомление в канал procedure_changes на каждое изменение строки.
+-- TG_ARGV[0] — колонка с ID процедуры в таблице триггера.
+CREATE OR REPLACE FUNCTION notify_procedure_change() RETURNS trigger AS $$
+DECLARE
+    rec RECORD;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        rec := OLD;
+    ELSE
+        rec := NEW;
+    END IF;
+    PERFORM pg_notify('procedure_changes', json_build_object(
+            'table', TG_TABLE_NAME,
+            'op', lower(TG_OP),
+            'id', (to_jsonb(rec) ->> TG_ARGV[0])::integer
+        )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER procedures_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON procedures
+    FOR EACH ROW EXECUTE FUNCTION notify_procedure_change('id');
+
+CREATE TRIGGER procedure_translations_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON procedure_translations
+    FOR EACH ROW EXECUTE FUNCTION notify_procedure_change('procedure_id');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS procedure_translations_notify_change ON procedure_translations;
+DROP TRIGGER IF EXISTS procedures_notify_change ON procedures;
+DROP FUNCTION IF EXISTS notify_procedure_change();
+-- +goose StatementEnd
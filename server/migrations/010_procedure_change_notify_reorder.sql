@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Изменение только позиции процедуры отправляется как op = 'reorder',
+-- чтобы клиенты могли отличить перестановку от правки содержимого
+CREATE OR REPLACE FUNCTION notify_procedure_change() RETURNS trigger AS $$
+DECLARE
+    rec RECORD;
+    op  TEXT := lower(TG_OP);
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        rec := OLD;
+    ELSE
+        rec := NEW;
+    END IF;
+    IF TG_OP = 'UPDATE'
+        AND (to_jsonb(OLD) -> 'sort_order') IS DISTINCT FROM (to_jsonb(NEW) -> 'sort_order')
+        AND (to_jsonb(OLD) - ARRAY ['sort_order', 'version', 'updated_at'])
+            = (to_jsonb(NEW) - ARRAY ['sort_order', 'version', 'updated_at']) THEN
+        op := 'reorder';
+    END IF;
+    PERFORM pg_notify('procedure_changes', json_build_object(
+            'table', TG_TABLE_NAME,
+            'op', op,
+            'id', (to_jsonb(rec) ->> TG_ARGV[0])::integer
+        )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_procedure_change() RETURNS trigger AS $$
+DECLARE
+    rec RECORD;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        rec := OLD;
+    ELSE
+        rec := NEW;
+    END IF;
+    PERFORM pg_notify('procedure_changes', json_build_object(
+            'table', TG_TABLE_NAME,
+            'op', lower(TG_OP),
+            'id', (to_jsonb(rec) ->> TG_ARGV[0])::integer
+        )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
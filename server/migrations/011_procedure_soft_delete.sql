@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE procedures ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_procedures_deleted_at ON procedures(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Без колонки корзина неотличима от живых процедур, поэтому удалённые стираются
+DELETE FROM procedure_revisions WHERE procedure_id IN (SELECT id FROM procedures WHERE deleted_at IS NOT NULL);
+DELETE FROM procedures WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_procedures_deleted_at;
+ALTER TABLE procedures DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE procedures ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+
+-- Существующим процедурам достаётся slug по типу, если тип уникален, иначе тип с ID
+UPDATE procedures p
+SET slug = CASE
+    WHEN (SELECT COUNT(*) FROM procedures q WHERE q.type = p.type) = 1 THEN p.type
+    ELSE p.type || '-' || p.id
+END
+WHERE slug IS NULL;
+
+CREATE OR REPLACE FUNCTION default_procedure_slug() RETURNS trigger AS $$
+BEGIN
+    IF NEW.slug IS NULL OR NEW.slug = '' THEN
+        NEW.slug := NEW.type || '-' || NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER procedures_default_slug
+    BEFORE INSERT ON procedures
+    FOR EACH ROW EXECUTE FUNCTION default_procedure_slug();
+
+ALTER TABLE procedures ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE procedures ADD CONSTRAINT procedures_slug_key UNIQUE (slug);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE procedures DROP CONSTRAINT IF EXISTS procedures_slug_key;
+DROP TRIGGER IF EXISTS procedures_default_slug ON procedures;
+DROP FUNCTION IF EXISTS default_procedure_slug();
+ALTER TABLE procedures DROP COLUMN IF EXISTS slug;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал загруженных фикстур: версия процедуры после загрузки показывает,
+-- менял ли её кто-то с тех пор
+CREATE TABLE IF NOT EXISTS procedure_seeds (
+                                               slug VARCHAR(100) PRIMARY KEY,
+                                               checksum VARCHAR(64) NOT NULL,
+                                               version INTEGER NOT NULL,
+                                               seeded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Процедуры, вставленные прежней версией 001 и не менявшиеся с тех пор, считаются загруженными
+-- из фикстур; пустая контрольная сумма заставит seed один раз привести их к файлам фикстур
+INSERT INTO procedure_seeds (slug, checksum, version)
+SELECT slug, '', version
+FROM procedures
+WHERE slug IN (
+    'search_without_status', 'loss_or_damage_docs', 'damage_additional_docs',
+    'loss_procedure', 'damage_procedure', 'recipient_info'
+)
+  AND version = 1 AND updated_at = created_at AND deleted_at IS NULL
+ON CONFLICT (slug) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS procedure_seeds;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tags (
+                                    slug VARCHAR(100) PRIMARY KEY,
+                                    name VARCHAR(255) NOT NULL,
+                                    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS procedure_tags (
+                                              procedure_id INTEGER NOT NULL REFERENCES procedures(id) ON DELETE CASCADE,
+                                              tag_slug VARCHAR(100) NOT NULL REFERENCES tags(slug) ON DELETE CASCADE,
+                                              PRIMARY KEY (procedure_id, tag_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_procedure_tags_tag_slug ON procedure_tags(tag_slug);
+
+-- Теги входят в представление процедуры, поэтому их изменения тоже сбрасывают кеши
+CREATE TRIGGER procedure_tags_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON procedure_tags
+    FOR EACH ROW EXECUTE FUNCTION notify_procedure_change('procedure_id');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS procedure_tags_notify_change ON procedure_tags;
+DROP TABLE IF EXISTS procedure_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS attachments (
+                                           id SERIAL PRIMARY KEY,
+                                           file_name VARCHAR(255) NOT NULL,
+                                           content_type VARCHAR(255) NOT NULL,
+                                           size BIGINT NOT NULL,
+                                           checksum CHAR(64) NOT NULL,
+                                           storage_key VARCHAR(255) NOT NULL UNIQUE,
+                                           created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Элементы содержимого ссылаются на вложения через attachment_id;
+-- индекс ускоряет проверку, используется ли вложение, перед удалением
+CREATE INDEX IF NOT EXISTS idx_procedures_content ON procedures USING GIN (content jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_procedure_translations_content ON procedure_translations USING GIN (content jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_procedure_translations_content;
+DROP INDEX IF EXISTS idx_procedures_content;
+DROP TABLE IF EXISTS attachments;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Вложенные процедуры: корни имеют parent_id IS NULL. При окончательном удалении
+-- родителя его подпроцедуры в корзине становятся корнями.
+ALTER TABLE procedures
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER NULL REFERENCES procedures(id) ON DELETE SET NULL;
+
+ALTER TABLE procedures
+    ADD CONSTRAINT procedures_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_procedures_parent_id ON procedures(parent_id, sort_order);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_procedures_parent_id;
+ALTER TABLE procedures DROP CONSTRAINT IF EXISTS procedures_parent_not_self;
+ALTER TABLE procedures DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS claims (
+                                      id SERIAL PRIMARY KEY,
+                                      tracking_number VARCHAR(30) NOT NULL,
+                                      kind VARCHAR(20) NOT NULL CHECK (kind IN ('loss', 'damage')),
+                                      status VARCHAR(30) NOT NULL DEFAULT 'submitted',
+                                      claimant_name VARCHAR(255) NOT NULL,
+                                      claimant_email VARCHAR(255) NOT NULL DEFAULT '',
+                                      claimant_phone VARCHAR(20) NOT NULL DEFAULT '',
+                                      declared_value BIGINT NOT NULL DEFAULT 0 CHECK (declared_value >= 0),
+                                      currency CHAR(3) NOT NULL DEFAULT 'RUB',
+                                      description TEXT NOT NULL DEFAULT '',
+                                      created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                      updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                      CHECK (claimant_email <> '' OR claimant_phone <> '')
+);
+
+CREATE INDEX IF NOT EXISTS idx_claims_tracking_number ON claims(tracking_number);
+CREATE INDEX IF NOT EXISTS idx_claims_kind ON claims(kind, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS claims;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE claims
+    ADD CONSTRAINT claims_status_check CHECK (status IN (
+        'submitted', 'under_review', 'awaiting_documents', 'approved', 'rejected', 'compensated', 'closed'
+    ));
+
+CREATE TABLE IF NOT EXISTS claim_status_history (
+                                                    id SERIAL PRIMARY KEY,
+                                                    claim_id INTEGER NOT NULL REFERENCES claims(id) ON DELETE RESTRICT,
+                                                    from_status VARCHAR(30) NULL,
+                                                    to_status VARCHAR(30) NOT NULL,
+                                                    reason TEXT NOT NULL DEFAULT '',
+                                                    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_status_history_claim_id ON claim_status_history(claim_id, id);
+
+-- Уже поданные заявления получают начальную запись истории
+INSERT INTO claim_status_history (claim_id, from_status, to_status, created_at)
+SELECT id, NULL, status, created_at
+FROM claims
+WHERE NOT EXISTS (SELECT 1 FROM claim_status_history h WHERE h.claim_id = claims.id);
+
+-- История только дополняется: исправления оформляются новым переходом
+CREATE OR REPLACE FUNCTION claim_status_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'claim_status_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER claim_status_history_append_only
+    BEFORE UPDATE OR DELETE ON claim_status_history
+    FOR EACH ROW EXECUTE FUNCTION claim_status_history_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS claim_status_history_append_only ON claim_status_history;
+DROP FUNCTION IF EXISTS claim_status_history_append_only();
+DROP TABLE IF EXISTS claim_status_history;
+ALTER TABLE claims DROP CONSTRAINT IF EXISTS claims_status_check;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS search_requests (
+                                               id SERIAL PRIMARY KEY,
+                                               tracking_number VARCHAR(30) NOT NULL,
+                                               kind VARCHAR(20) NOT NULL CHECK (kind IN ('domestic', 'international')),
+                                               status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'found', 'not_found')),
+                                               contact_email VARCHAR(255) NOT NULL DEFAULT '',
+                                               contact_phone VARCHAR(20) NOT NULL DEFAULT '',
+                                               resolution TEXT NOT NULL DEFAULT '',
+                                               due_at TIMESTAMP NOT NULL,
+                                               overdue_at TIMESTAMP NULL,
+                                               escalation_level INTEGER NOT NULL DEFAULT 0,
+                                               escalated_at TIMESTAMP NULL,
+                                               resolved_at TIMESTAMP NULL,
+                                               created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                               CHECK (contact_email <> '' OR contact_phone <> '')
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_requests_tracking_number ON search_requests(tracking_number);
+-- Фоновая проверка просрочки и выборка для дашборда смотрят только на открытые запросы
+CREATE INDEX IF NOT EXISTS idx_search_requests_open_due_at ON search_requests(due_at) WHERE status = 'open';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS search_requests;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS claim_documents (
+                                               id SERIAL PRIMARY KEY,
+                                               claim_id INTEGER NOT NULL REFERENCES claims(id) ON DELETE RESTRICT,
+                                               code VARCHAR(50) NOT NULL,
+                                               file_name VARCHAR(255) NOT NULL,
+                                               content_type VARCHAR(255) NOT NULL,
+                                               size BIGINT NOT NULL,
+                                               checksum CHAR(64) NOT NULL,
+                                               storage_key VARCHAR(255) NOT NULL UNIQUE,
+                                               created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_claim_documents_claim_id ON claim_documents(claim_id, code);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS claim_documents;
+-- +goose StatementEnd
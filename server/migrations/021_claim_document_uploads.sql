@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE claim_documents ADD COLUMN IF NOT EXISTS thumbnail_key VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Повторная загрузка того же файла по тому же требованию возвращает уже загруженный документ
+CREATE UNIQUE INDEX IF NOT EXISTS idx_claim_documents_checksum ON claim_documents(claim_id, code, checksum);
+-- Уникальный индекс начинается с тех же колонок и заменяет прежний
+DROP INDEX IF EXISTS idx_claim_documents_claim_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_claim_documents_claim_id ON claim_documents(claim_id, code);
+DROP INDEX IF EXISTS idx_claim_documents_checksum;
+ALTER TABLE claim_documents DROP COLUMN IF EXISTS thumbnail_key;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- due_at считается часами базы, а Escalate сравнивает его с временем приложения:
+-- с часовым поясом сравнение не зависит от TimeZone сессии. Прежние значения записаны в UTC.
+ALTER TABLE search_requests
+    ALTER COLUMN due_at TYPE TIMESTAMPTZ USING due_at AT TIME ZONE 'UTC',
+    ALTER COLUMN overdue_at TYPE TIMESTAMPTZ USING overdue_at AT TIME ZONE 'UTC',
+    ALTER COLUMN escalated_at TYPE TIMESTAMPTZ USING escalated_at AT TIME ZONE 'UTC',
+    ALTER COLUMN resolved_at TYPE TIMESTAMPTZ USING resolved_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE search_requests
+    ALTER COLUMN due_at TYPE TIMESTAMP USING due_at AT TIME ZONE 'UTC',
+    ALTER COLUMN overdue_at TYPE TIMESTAMP USING overdue_at AT TIME ZONE 'UTC',
+    ALTER COLUMN escalated_at TYPE TIMESTAMP USING escalated_at AT TIME ZONE 'UTC',
+    ALTER COLUMN resolved_at TYPE TIMESTAMP USING resolved_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Время последнего изменения процедур, их переводов и тегов — Last-Modified списков.
+-- Максимум updated_at видимых строк для этого не годится: он не растёт, когда процедура
+-- удаляется, снимается с публикации или теряет тег.
+CREATE TABLE IF NOT EXISTS procedure_changes_clock (
+    id         BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO procedure_changes_clock DEFAULT VALUES ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION touch_procedure_changes_clock() RETURNS trigger AS $$
+BEGIN
+    UPDATE procedure_changes_clock SET changed_at = clock_timestamp();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER procedures_touch_changes_clock
+    AFTER INSERT OR UPDATE OR DELETE ON procedures
+    FOR EACH STATEMENT EXECUTE FUNCTION touch_procedure_changes_clock();
+
+CREATE TRIGGER procedure_translations_touch_changes_clock
+    AFTER INSERT OR UPDATE OR DELETE ON procedure_translations
+    FOR EACH STATEMENT EXECUTE FUNCTION touch_procedure_changes_clock();
+
+CREATE TRIGGER procedure_tags_touch_changes_clock
+    AFTER INSERT OR UPDATE OR DELETE ON procedure_tags
+    FOR EACH STATEMENT EXECUTE FUNCTION touch_procedure_changes_clock();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS procedure_tags_touch_changes_clock ON procedure_tags;
+DROP TRIGGER IF EXISTS procedure_translations_touch_changes_clock ON procedure_translations;
+DROP TRIGGER IF EXISTS procedures_touch_changes_clock ON procedures;
+DROP FUNCTION IF EXISTS touch_procedure_changes_clock();
+DROP TABLE IF EXISTS procedure_changes_clock;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Начальное содержимое загружается из fixtures командой quest seed. Процедуры, вставленные 001
+-- и не менявшиеся с тех пор (их записи в procedure_seeds оставила 013 с пустой контрольной
+-- суммой), удаляются вместе с ревизиями и записями журнала, чтобы новые и старые базы
+-- сошлись и seed создал их заново. Изменённые процедуры и процедуры с подпроцедурами остаются.
+WITH removed AS (
+    DELETE FROM procedures p
+    USING procedure_seeds s
+    WHERE s.slug = p.slug AND s.checksum = '' AND s.version = p.version
+      AND p.version = 1 AND p.updated_at = p.created_at AND p.deleted_at IS NULL
+      AND NOT EXISTS (SELECT 1 FROM procedures child WHERE child.parent_id = p.id)
+    RETURNING p.id, p.slug
+), revisions AS (
+    DELETE FROM procedure_revisions WHERE procedure_id IN (SELECT id FROM removed)
+)
+DELETE FROM procedure_seeds WHERE slug IN (SELECT slug FROM removed);
+-- +goose StatementEnd
+
+-- +goose Down
+-- Удалённые процедуры восстанавливает quest seed
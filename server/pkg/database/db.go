@@ -8,9 +8,10 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func NewDB() (*sqlx.DB, error) {
+// DSN собирает строку подключения к Postgres из конфигурации
+func DSN() string {
 	cfg := configs.Configs
-	dsn := fmt.Sprintf(
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.DBHost,
 		cfg.DBPort,
@@ -19,6 +20,10 @@ func NewDB() (*sqlx.DB, error) {
 		cfg.DBName,
 		cfg.DBSSLMode,
 	)
+}
+
+func NewDB() (*sqlx.DB, error) {
+	dsn := DSN()
 	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval — как часто проверять соединение, если уведомлений нет:
+	// без проверки обрыв по TCP может оставаться незамеченным очень долго
+	listenerPingInterval = 90 * time.Second
+)
+
+// Listener слушает канал Postgres через LISTEN на выделенном соединении
+// и переподключается при обрыве с экспоненциальной паузой
+type Listener struct {
+	channel  string
+	listener *pq.Listener
+}
+
+func NewListener(channel string) *Listener {
+	l := &Listener{channel: channel}
+	l.listener = pq.NewListener(DSN(), listenerMinReconnect, listenerMaxReconnect, l.logEvent)
+	return l
+}
+
+// Run подписывается на канал и до отмены ctx вызывает onNotify с payload каждого
+// уведомления. После восстановления соединения вызывается onReconnect: уведомления,
+// отправленные во время обрыва, не доставляются.
+func (l *Listener) Run(ctx context.Context, onNotify func(payload string), onReconnect func()) {
+	defer func() {
+		if err := l.listener.Close(); err != nil {
+			log.Printf("listener %s: close: %v", l.channel, err)
+		}
+	}()
+	if err := l.listener.Listen(l.channel); err != nil {
+		log.Printf("listener %s: listen: %v", l.channel, err)
+	}
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-l.listener.Notify:
+			if notification == nil {
+				onReconnect()
+				continue
+			}
+			onNotify(notification.Extra)
+		case <-ticker.C:
+			go func() {
+				if err := l.listener.Ping(); err != nil {
+					log.Printf("listener %s: ping: %v", l.channel, err)
+				}
+			}()
+		}
+	}
+}
+
+func (l *Listener) logEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventConnected:
+		log.Printf("listener %s: connected", l.channel)
+	case pq.ListenerEventDisconnected:
+		log.Printf("listener %s: disconnected: %v", l.channel, err)
+	case pq.ListenerEventReconnected:
+		log.Printf("listener %s: reconnected", l.channel)
+	case pq.ListenerEventConnectionAttemptFailed:
+		log.Printf("listener %s: connection attempt failed: %v", l.channel, err)
+	}
+}
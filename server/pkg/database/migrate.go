@@ -6,23 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"tech-quest/internal/configs"
 
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
 )
 
 func RunMigrations() error {
-	cfg := configs.Configs
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBName,
-		cfg.DBSSLMode,
-	)
+	dsn := DSN()
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open database connection: %w", err)
@@ -3,10 +3,17 @@ package errors
 import "errors"
 
 var ErrNotFound = errors.New("not found")
+var ErrConflict = errors.New("conflict")
+var ErrPreconditionFailed = errors.New("precondition failed")
 var ErrNegativeAmount = errors.New("cannot top up negative amount")
 var ParseErrorCode = "parse_error"
 var NotFoundCode = "not_found"
 var ServerErrorCode = "server_error"
 var ValidationErrorCode = "validation_error"
+var ConflictCode = "conflict"
+var InvalidTransitionCode = "invalid_transition"
+var PreconditionFailedCode = "precondition_failed"
+var PreconditionRequiredCode = "precondition_required"
+var MissingDocumentsCode = "missing_documents"
 var InvalidFormat = "invalid card format: %s"
 var InvalidJson = "invalid json"
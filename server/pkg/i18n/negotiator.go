@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Negotiator выбирает цепочку локалей для ответа по ?lang= и Accept-Language
+type Negotiator struct {
+	defaultLocale string
+	supported     map[string]bool
+	fallback      []string
+}
+
+// NewNegotiator создаёт Negotiator. defaultLocale — язык исходного контента,
+// fallback — локали, которые пробуются после запрошенных клиентом.
+func NewNegotiator(defaultLocale string, supported, fallback []string) *Negotiator {
+	n := &Negotiator{
+		defaultLocale: Normalize(defaultLocale),
+		supported:     make(map[string]bool),
+	}
+	n.supported[n.defaultLocale] = true
+	for _, locale := range supported {
+		if locale = Normalize(locale); locale != "" {
+			n.supported[locale] = true
+		}
+	}
+	for _, locale := range fallback {
+		if locale = Normalize(locale); n.supported[locale] {
+			n.fallback = append(n.fallback, locale)
+		}
+	}
+	return n
+}
+
+func (n *Negotiator) DefaultLocale() string {
+	return n.defaultLocale
+}
+
+func (n *Negotiator) IsSupported(locale string) bool {
+	return n.supported[Normalize(locale)]
+}
+
+// Supported возвращает поддерживаемые локали в алфавитном порядке
+func (n *Negotiator) Supported() []string {
+	locales := make([]string, 0, len(n.supported))
+	for locale := range n.supported {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Chain возвращает упорядоченный список локалей без повторов.
+// override (?lang=) имеет приоритет над Accept-Language, цепочка
+// всегда заканчивается локалью по умолчанию.
+func (n *Negotiator) Chain(override, acceptLanguage string) []string {
+	var requested []string
+	if override != "" {
+		requested = []string{override}
+	} else {
+		requested = ParseAcceptLanguage(acceptLanguage)
+	}
+	chain := make([]string, 0, len(requested)+len(n.fallback)+1)
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if n.supported[locale] && !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, tag := range requested {
+		tag = Normalize(tag)
+		add(tag)
+		if base, _, found := strings.Cut(tag, "-"); found {
+			add(base)
+		}
+	}
+	for _, locale := range n.fallback {
+		add(locale)
+	}
+	add(n.defaultLocale)
+	return chain
+}
+
+// Normalize приводит языковой тег к нижнему регистру с дефисом: en_US -> en-us
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// ParseAcceptLanguage разбирает заголовок Accept-Language и возвращает
+// теги в порядке убывания веса. Теги с q=0 и * отбрасываются.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, t.tag)
+	}
+	return result
+}
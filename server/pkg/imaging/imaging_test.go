@@ -0,0 +1,188 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// exifSegment собирает APP1 с EXIF в порядке байтов Intel: Orientation и ссылка на GPS IFD
+func exifSegment(orientation int) []byte {
+	tiff := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00}
+	entries := [][3]uint32{
+		{0x0112, 3, uint32(orientation)},
+		{0x8825, 4, 38},
+	}
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(len(entries)))
+	for _, entry := range entries {
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(entry[0]))
+		tiff = binary.LittleEndian.AppendUint16(tiff, uint16(entry[1]))
+		tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+		tiff = binary.LittleEndian.AppendUint32(tiff, entry[2])
+	}
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, []byte("GPS 55.7558N 37.6173E")...)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	length := len(payload) + 2
+	return append([]byte{0xFF, 0xE1, byte(length >> 8), byte(length)}, payload...)
+}
+
+func segment(marker byte, payload string) []byte {
+	length := len(payload) + 2
+	return append([]byte{0xFF, marker, byte(length >> 8), byte(length)}, payload...)
+}
+
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	return img
+}
+
+// testJPEG кодирует изображение и вставляет после SOI сегменты с метаданными
+func testJPEG(t *testing.T, width, height int, segments ...[]byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(width, height), nil))
+	encoded := buf.Bytes()
+	data := append([]byte{}, encoded[:2]...)
+	for _, s := range segments {
+		data = append(data, s...)
+	}
+	data = append(data, encoded[2:]...)
+	// Встроенное превью после EOI, как в MPF
+	return append(data, 0xFF, 0xD8, 'G', 'P', 'S', 0xFF, 0xD9)
+}
+
+func pngChunk(chunkType, data string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(chunkType+data)))
+}
+
+func TestStripMetadata_JPEG(t *testing.T) {
+	data := testJPEG(t, 40, 20,
+		segment(0xE0, "JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"),
+		exifSegment(6),
+		segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>GPS</x:xmpmeta>"),
+		segment(0xE2, "ICC_PROFILE\x00\x01\x01profile"),
+		segment(0xED, "Photoshop 3.0\x00GPS"),
+		segment(0xFE, "GPS comment"),
+	)
+	require.Equal(t, 6, Orientation(data))
+
+	stripped, err := StripMetadata(data)
+	require.NoError(t, err)
+	require.NotContains(t, string(stripped), "GPS")
+	require.NotContains(t, string(stripped), "xmpmeta")
+	require.Contains(t, string(stripped), "JFIF")
+	require.Contains(t, string(stripped), "ICC_PROFILE")
+	require.Equal(t, 6, Orientation(stripped))
+	require.Equal(t, []byte{0xFF, 0xD9}, stripped[len(stripped)-2:])
+
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+
+	// Без тега ориентации EXIF удаляется целиком
+	stripped, err = StripMetadata(testJPEG(t, 8, 8, exifSegment(1)))
+	require.NoError(t, err)
+	require.NotContains(t, string(stripped), "Exif")
+	require.Equal(t, 1, Orientation(stripped))
+}
+
+func TestStripMetadata_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(10, 10)))
+	encoded := buf.Bytes()
+	// Вспомогательные чанки вставляются сразу после IHDR: сигнатура 8 байт, IHDR 25 байт
+	data := append([]byte{}, encoded[:33]...)
+	data = append(data, pngChunk("tEXt", "Author\x00Ivan Ivanov")...)
+	data = append(data, pngChunk("eXIf", "MM\x00\x2aGPS")...)
+	data = append(data, pngChunk("pHYs", "\x00\x00\x0b\x13\x00\x00\x0b\x13\x01")...)
+	data = append(data, encoded[33:]...)
+	data = append(data, "trailing"...)
+
+	stripped, err := StripMetadata(data)
+	require.NoError(t, err)
+	require.NotContains(t, string(stripped), "Ivan")
+	require.NotContains(t, string(stripped), "GPS")
+	require.NotContains(t, string(stripped), "trailing")
+	require.Contains(t, string(stripped), "pHYs")
+	_, err = png.Decode(bytes.NewReader(stripped))
+	require.NoError(t, err)
+}
+
+func TestStripMetadata_Unsupported(t *testing.T) {
+	_, err := StripMetadata([]byte("%PDF-1.4"))
+	require.ErrorIs(t, err, ErrUnsupported)
+	_, err = StripMetadata([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x10})
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       func(t *testing.T) []byte
+		wantBounds image.Rectangle
+	}{
+		{
+			name:       "landscape",
+			data:       func(t *testing.T) []byte { return testJPEG(t, 400, 200) },
+			wantBounds: image.Rect(0, 0, 100, 50),
+		},
+		{
+			name:       "rotated by exif",
+			data:       func(t *testing.T) []byte { return testJPEG(t, 400, 200, exifSegment(6)) },
+			wantBounds: image.Rect(0, 0, 50, 100),
+		},
+		{
+			name:       "small image is not enlarged",
+			data:       func(t *testing.T) []byte { return testJPEG(t, 30, 60) },
+			wantBounds: image.Rect(0, 0, 30, 60),
+		},
+		{
+			name: "png",
+			data: func(t *testing.T) []byte {
+				var buf bytes.Buffer
+				require.NoError(t, png.Encode(&buf, testImage(120, 300)))
+				return buf.Bytes()
+			},
+			wantBounds: image.Rect(0, 0, 40, 100),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, err := Thumbnail(tt.data(t), 100)
+			require.NoError(t, err)
+			img, err := jpeg.Decode(bytes.NewReader(thumb))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBounds, img.Bounds())
+			require.NotContains(t, string(thumb), "Exif")
+		})
+	}
+
+	_, err := Thumbnail([]byte("not an image"), 100)
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestOrient(t *testing.T) {
+	// Снимок 3×2, сохранённый повёрнутым на 90° против часовой стрелки (Orientation 6):
+	// левый верхний угол показанного снимка — левый нижний угол сохранённого
+	x, y := orient(6, 0, 0, 3, 2)
+	require.Equal(t, [2]int{0, 1}, [2]int{x, y})
+	x, y = orient(8, 0, 0, 3, 2)
+	require.Equal(t, [2]int{2, 0}, [2]int{x, y})
+	x, y = orient(3, 0, 0, 3, 2)
+	require.Equal(t, [2]int{2, 1}, [2]int{x, y})
+}
@@ -0,0 +1,226 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrUnsupported — формат изображения не поддерживается или файл повреждён
+var ErrUnsupported = errors.New("imaging: unsupported or damaged image")
+
+var (
+	jpegSOI      = []byte{0xFF, 0xD8}
+	pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+	exifHeader   = []byte("Exif\x00\x00")
+	iccHeader    = []byte("ICC_PROFILE\x00")
+)
+
+const (
+	markerSOS   = 0xDA
+	markerEOI   = 0xD9
+	markerAPP0  = 0xE0
+	markerAPP1  = 0xE1
+	markerAPP2  = 0xE2
+	markerAPP14 = 0xEE
+	markerAPP15 = 0xEF
+	markerCOM   = 0xFE
+
+	orientationTag = 0x0112
+)
+
+// pngPrivateChunks — вспомогательные чанки PNG с текстом, EXIF и датой съёмки
+var pngPrivateChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// StripMetadata удаляет из JPEG или PNG метаданные: EXIF с координатами и моделью
+// камеры, XMP, IPTC, комментарии и встроенные превью. Пиксели не перекодируются.
+// Из EXIF JPEG сохраняется только ориентация, иначе снимок отобразится повёрнутым.
+// ICC-профиль остаётся, он нужен для правильной передачи цвета.
+func StripMetadata(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data)
+	}
+	return nil, ErrUnsupported
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(jpegSOI)
+	pos := len(jpegSOI)
+	for {
+		marker, next, err := readMarker(data, pos)
+		if err != nil {
+			return nil, err
+		}
+		pos = next
+		if marker == markerEOI {
+			// Данные после EOI (превью, карты глубины) отбрасываются
+			out.Write([]byte{0xFF, markerEOI})
+			return out.Bytes(), nil
+		}
+		if pos+2 > len(data) {
+			return nil, ErrUnsupported
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 || pos+length > len(data) {
+			return nil, ErrUnsupported
+		}
+		segment := data[pos-2 : pos+length]
+		payload := data[pos+2 : pos+length]
+		pos += length
+
+		switch {
+		case marker == markerSOS:
+			end := scanEntropy(data, pos)
+			out.Write(segment)
+			out.Write(data[pos:end])
+			pos = end
+		case marker == markerAPP1 && bytes.HasPrefix(payload, exifHeader):
+			if orientation := exifOrientation(payload[len(exifHeader):]); orientation > 1 {
+				out.Write(orientationSegment(orientation))
+			}
+		case marker == markerAPP2 && bytes.HasPrefix(payload, iccHeader),
+			marker == markerAPP0, marker == markerAPP14:
+			out.Write(segment)
+		case marker >= markerAPP1 && marker <= markerAPP15, marker == markerCOM:
+			// XMP, IPTC, MPF и прочие служебные сегменты не нужны для отображения
+		default:
+			out.Write(segment)
+		}
+	}
+}
+
+// readMarker пропускает заполняющие 0xFF и возвращает маркер сегмента и позицию после него
+func readMarker(data []byte, pos int) (byte, int, error) {
+	if pos >= len(data) || data[pos] != 0xFF {
+		return 0, 0, ErrUnsupported
+	}
+	for pos < len(data) && data[pos] == 0xFF {
+		pos++
+	}
+	if pos >= len(data) {
+		return 0, 0, ErrUnsupported
+	}
+	return data[pos], pos + 1, nil
+}
+
+// scanEntropy возвращает позицию первого маркера после сжатых данных скана.
+// 0xFF00 — экранированный байт, 0xFFD0–0xFFD7 — маркеры перезапуска внутри скана.
+func scanEntropy(data []byte, pos int) int {
+	for pos+1 < len(data) {
+		if data[pos] == 0xFF {
+			next := data[pos+1]
+			if next != 0x00 && (next < 0xD0 || next > 0xD7) && next != 0xFF {
+				return pos
+			}
+		}
+		pos++
+	}
+	return len(data)
+}
+
+// exifOrientation читает тег Orientation из IFD0 блока TIFF; 0 — тега нет
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == orientationTag && order.Uint16(tiff[entry+2:]) == 3 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationSegment собирает сегмент APP1 с EXIF из единственного тега Orientation
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	length := 2 + len(exifHeader) + len(tiff)
+	segment := []byte{0xFF, markerAPP1, byte(length >> 8), byte(length)}
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
+
+// Orientation возвращает ориентацию JPEG из EXIF (1–8); для PNG и снимков без тега — 1
+func Orientation(data []byte) int {
+	if !bytes.HasPrefix(data, jpegSOI) {
+		return 1
+	}
+	pos := len(jpegSOI)
+	for {
+		marker, next, err := readMarker(data, pos)
+		if err != nil || marker == markerSOS || marker == markerEOI || next+2 > len(data) {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[next:]))
+		if length < 2 || next+length > len(data) {
+			return 1
+		}
+		payload := data[next+2 : next+length]
+		if marker == markerAPP1 && bytes.HasPrefix(payload, exifHeader) {
+			if orientation := exifOrientation(payload[len(exifHeader):]); orientation > 0 {
+				return orientation
+			}
+			return 1
+		}
+		pos = next + length
+	}
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	pos := len(pngSignature)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrUnsupported
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if !pngPrivateChunks[chunkType] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+		if chunkType == "IEND" {
+			return out.Bytes(), nil
+		}
+	}
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// MaxPixels ограничивает размер декодируемого изображения, чтобы небольшой
+	// сжатый файл не занял гигабайты памяти при распаковке: 24 Мп — снимок 6000×4000
+	MaxPixels = 24_000_000
+	// MaxConcurrentDecodes — сколько изображений декодируется одновременно;
+	// остальные вызовы Thumbnail ждут своей очереди
+	MaxConcurrentDecodes = 2
+	// thumbnailSamples — сколько точек исходника усредняется по каждой оси на пиксель превью
+	thumbnailSamples = 4
+	thumbnailQuality = 80
+)
+
+// decodeSlots ограничивает память под распакованные изображения независимо
+// от числа параллельных загрузок
+var decodeSlots = make(chan struct{}, MaxConcurrentDecodes)
+
+// Thumbnail уменьшает JPEG или PNG так, чтобы большая сторона была не больше maxSide,
+// с учётом EXIF-ориентации, и возвращает превью в JPEG без метаданных.
+// Прозрачные области PNG заливаются белым. Маленькие изображения не увеличиваются.
+// Одновременно декодируется не больше MaxConcurrentDecodes изображений.
+func Thumbnail(data []byte, maxSide int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrUnsupported
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("imaging: image is %dx%d, at most %d pixels allowed", config.Width, config.Height, MaxPixels)
+	}
+	decodeSlots <- struct{}{}
+	defer func() { <-decodeSlots }()
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	orientation := Orientation(data)
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Ориентации 5–8 поворачивают снимок на 90°: стороны меняются местами
+	if orientation >= 5 {
+		width, height = height, width
+	}
+	thumbWidth, thumbHeight := width, height
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			thumbWidth, thumbHeight = maxSide, max(1, height*maxSide/width)
+		} else {
+			thumbWidth, thumbHeight = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	scaleX := float64(width) / float64(thumbWidth)
+	scaleY := float64(height) / float64(thumbHeight)
+	for ty := 0; ty < thumbHeight; ty++ {
+		for tx := 0; tx < thumbWidth; tx++ {
+			var r, g, b, a uint32
+			for sy := 0; sy < thumbnailSamples; sy++ {
+				for sx := 0; sx < thumbnailSamples; sx++ {
+					x := int((float64(tx) + (float64(sx)+0.5)/thumbnailSamples) * scaleX)
+					y := int((float64(ty) + (float64(sy)+0.5)/thumbnailSamples) * scaleY)
+					srcX, srcY := orient(orientation, min(x, width-1), min(y, height-1), bounds.Dx(), bounds.Dy())
+					pr, pg, pb, pa := src.At(bounds.Min.X+srcX, bounds.Min.Y+srcY).RGBA()
+					r, g, b, a = r+pr, g+pg, b+pb, a+pa
+				}
+			}
+			const n = thumbnailSamples * thumbnailSamples
+			// Цвета RGBA() уже умножены на альфу: добавляем белый фон пропорционально прозрачности
+			background := 0xFFFF - a/n
+			thumb.Set(tx, ty, color.RGBA64{
+				R: uint16(r/n + background),
+				G: uint16(g/n + background),
+				B: uint16(b/n + background),
+				A: 0xFFFF,
+			})
+		}
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, thumb, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// orient переводит координаты точки повёрнутого для показа снимка в координаты
+// исходника размером width×height по значению EXIF Orientation
+func orient(orientation, x, y, width, height int) (int, int) {
+	switch orientation {
+	case 2:
+		return width - 1 - x, y
+	case 3:
+		return width - 1 - x, height - 1 - y
+	case 4:
+		return x, height - 1 - y
+	case 5:
+		return y, x
+	case 6:
+		return y, height - 1 - x
+	case 7:
+		return width - 1 - y, height - 1 - x
+	case 8:
+		return width - 1 - y, x
+	}
+	return x, y
+}
@@ -0,0 +1,136 @@
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{
+			name:  "replace field",
+			doc:   `{"title":"a","is_expanded":false}`,
+			patch: `{"is_expanded":true}`,
+			want:  `{"title":"a","is_expanded":true}`,
+		},
+		{
+			name:  "null removes field",
+			doc:   `{"title":"a","publish_at":"2024-01-01T00:00:00Z"}`,
+			patch: `{"publish_at":null}`,
+			want:  `{"title":"a"}`,
+		},
+		{
+			name:  "nested objects merge",
+			doc:   `{"a":{"b":1,"c":2}}`,
+			patch: `{"a":{"c":null,"d":3}}`,
+			want:  `{"a":{"b":1,"d":3}}`,
+		},
+		{
+			name:  "arrays are replaced",
+			doc:   `{"content":[{"value":"x"},{"value":"y"}]}`,
+			patch: `{"content":[{"value":"z"}]}`,
+			want:  `{"content":[{"value":"z"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	doc := `{"title":"a","content":[{"value":"x"},{"value":"y"}],"a~b":{"c/d":1}}`
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/title","value":"b"}]`,
+			want:  `{"title":"b","content":[{"value":"x"},{"value":"y"}],"a~b":{"c/d":1}}`,
+		},
+		{
+			name:  "add to array end and insert",
+			patch: `[{"op":"add","path":"/content/-","value":{"value":"z"}},{"op":"add","path":"/content/0","value":{"value":"w"}}]`,
+			want:  `{"title":"a","content":[{"value":"w"},{"value":"x"},{"value":"y"},{"value":"z"}],"a~b":{"c/d":1}}`,
+		},
+		{
+			name:  "remove escaped path",
+			patch: `[{"op":"remove","path":"/a~0b/c~1d"}]`,
+			want:  `{"title":"a","content":[{"value":"x"},{"value":"y"}],"a~b":{}}`,
+		},
+		{
+			name:  "move array item",
+			patch: `[{"op":"move","from":"/content/0","path":"/content/1"}]`,
+			want:  `{"title":"a","content":[{"value":"y"},{"value":"x"}],"a~b":{"c/d":1}}`,
+		},
+		{
+			name:  "copy",
+			patch: `[{"op":"copy","from":"/content/1","path":"/content/0"},{"op":"replace","path":"/content/0/value","value":"z"}]`,
+			want:  `{"title":"a","content":[{"value":"z"},{"value":"x"},{"value":"y"}],"a~b":{"c/d":1}}`,
+		},
+		{
+			name:  "test passes",
+			patch: `[{"op":"test","path":"/title","value":"a"},{"op":"replace","path":"/title","value":"b"}]`,
+			want:  `{"title":"b","content":[{"value":"x"},{"value":"y"}],"a~b":{"c/d":1}}`,
+		},
+		{
+			name:    "test fails",
+			patch:   `[{"op":"replace","path":"/title","value":"b"},{"op":"test","path":"/title","value":"a"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "replace missing",
+			patch:   `[{"op":"replace","path":"/missing","value":1}]`,
+			wantErr: ErrPathNotFound,
+		},
+		{
+			name:    "index out of range",
+			patch:   `[{"op":"remove","path":"/content/2"}]`,
+			wantErr: ErrPathNotFound,
+		},
+		{
+			name:    "missing value",
+			patch:   `[{"op":"add","path":"/title"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "unknown op",
+			patch:   `[{"op":"merge","path":"/title","value":1}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "move into own child",
+			patch:   `[{"op":"move","from":"/a~0b","path":"/a~0b/e"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(doc), []byte(tt.patch))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestApply_NullValue(t *testing.T) {
+	got, err := Apply([]byte(`{"publish_at":"2024-01-01T00:00:00Z"}`), []byte(`[{"op":"replace","path":"/publish_at","value":null}]`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"publish_at":null}`, string(got))
+}
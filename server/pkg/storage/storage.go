@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound — объекта с таким ключом нет в хранилище
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage — хранилище файлов, адресуемых ключом вида "2026/10/3f2a…".
+// Ключи выдаёт вызывающий код; хранилище не интерпретирует их содержимое.
+type Storage interface {
+	// Put сохраняет size байт из body под ключом key, заменяя прежний объект
+	Put(key string, body io.Reader, size int64, contentType string) error
+	// Get открывает объект начиная с байта offset; length < 0 означает до конца объекта
+	Get(key string, offset, length int64) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта ошибкой не считается
+	Delete(key string) error
+}
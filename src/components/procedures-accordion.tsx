@@ -6,7 +6,7 @@ import {
   AccordionTrigger,
 } from "@/components/ui/accordion";
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
-import { fetchProcedures } from "@/lib/api";
+import { attachmentDownloadUrl, fetchProcedures, subscribeToProcedures } from "@/lib/api";
 import type { Procedure, ProcedureContentItem } from "@/types/procedure";
 import { Loader2 } from "lucide-react";
 
@@ -29,30 +29,23 @@ export function ProceduresAccordion() {
     }
 
     loadProcedures();
-  }, []);
 
-    const parseContent = (content: string): ProcedureContentItem[] => {
-        try {
-            const parsed = JSON.parse(content);
-            if (!Array.isArray(parsed)) return [];
-            return parsed.map((item) => {
-                if (typeof item === "string") {
-                    return { value: item };
-                }
-                if (typeof item === "object" && item !== null && "value" in item) {
-                    return item as ProcedureContentItem;
-                }
-                return null;
-            }).filter(Boolean) as ProcedureContentItem[];
-        } catch {
-            return content ? [{ value: content }] : [];
+    return subscribeToProcedures((event) => {
+      if (event.type === "resync") {
+        loadProcedures();
+        return;
+      }
+      setProcedures((current) => {
+        if (event.type === "deleted") {
+          return current.filter((procedure) => procedure.id !== event.id);
         }
-    };
-
-
-    const renderContent = (content: string) => {
-        const items = parseContent(content);
+        const rest = current.filter((procedure) => procedure.id !== event.procedure.id);
+        return [...rest, event.procedure].sort((a, b) => a.sort_order - b.sort_order);
+      });
+    });
+  }, []);
 
+    const renderContent = (items: ProcedureContentItem[]) => {
         if (items.length === 0) {
             return (
                 <p className="text-muted-foreground italic">
@@ -71,9 +64,9 @@ export function ProceduresAccordion() {
                         <span className="mt-1 text-primary">✔</span>
 
                         <span className="flex-1 leading-relaxed">
-            {item.url ? (
+            {item.attachment_id || item.url ? (
                 <a
-                    href={item.url}
+                    href={item.attachment_id ? attachmentDownloadUrl(item.attachment_id) : item.url}
                     target="_blank"
                     rel="noopener noreferrer"
                     className="text-primary font-medium hover:underline"
@@ -91,7 +84,31 @@ export function ProceduresAccordion() {
     };
 
 
-  const defaultValues = procedures
+  // Подпроцедуры выводятся внутри родителя; список уже упорядочен по sort_order
+  const childrenOf = (parentId: number) =>
+    procedures.filter((procedure) => procedure.parent_id === parentId);
+
+  const renderChildren = (parentId: number) => {
+    const children = childrenOf(parentId);
+    if (children.length === 0) {
+      return null;
+    }
+    return (
+      <ol className="mt-4 space-y-4 border-l-2 border-primary/20 pl-4">
+        {children.map((child) => (
+          <li key={child.id}>
+            <h4 className="mb-2 font-semibold text-foreground">{child.title}</h4>
+            {renderContent(child.content)}
+            {renderChildren(child.id)}
+          </li>
+        ))}
+      </ol>
+    );
+  };
+
+  const roots = procedures.filter((procedure) => procedure.parent_id === null);
+
+  const defaultValues = roots
     .filter((p) => p.is_expanded)
     .map((p) => `item-${p.id}`);
 
@@ -127,7 +144,7 @@ export function ProceduresAccordion() {
             defaultValue={defaultValues}
             className="w-full space-y-3"
           >
-            {procedures.map((procedure) => (
+            {roots.map((procedure) => (
               <AccordionItem
                 key={procedure.id}
                 value={`item-${procedure.id}`}
@@ -139,6 +156,7 @@ export function ProceduresAccordion() {
                 <AccordionContent className="text-sm sm:text-base pb-5 pt-2 text-muted-foreground">
                   <div className="pl-2">
                     {renderContent(procedure.content)}
+                    {renderChildren(procedure.id)}
                   </div>
                 </AccordionContent>
               </AccordionItem>
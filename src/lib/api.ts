@@ -1,13 +1,49 @@
-import type { Procedure } from "@/types/procedure";
+import type { Procedure, ProcedurePage } from "@/types/procedure";
 
 const API_BASE_URL = import.meta.env.VITE_API_URL || "http://localhost:8000/api/v1";
 
+export function attachmentDownloadUrl(id: number): string {
+  return `${API_BASE_URL}/attachments/${id}/download`;
+}
+
 export async function fetchProcedures(): Promise<Procedure[]> {
-  const response = await fetch(`${API_BASE_URL}/procedures`);
-  if (!response.ok) {
-    throw new Error(`Failed to fetch procedures: ${response.statusText}`);
-  }
-  
-  return response.json();
+  const procedures: Procedure[] = [];
+  let cursor: string | undefined;
+
+  do {
+    const params = new URLSearchParams({ limit: "100" });
+    if (cursor) {
+      params.set("after", cursor);
+    }
+    const response = await fetch(`${API_BASE_URL}/procedures?${params}`);
+    if (!response.ok) {
+      throw new Error(`Failed to fetch procedures: ${response.statusText}`);
+    }
+    const page: ProcedurePage = await response.json();
+    procedures.push(...page.items);
+    cursor = page.next_cursor;
+  } while (cursor);
+
+  return procedures;
 }
 
+export type ProcedureStreamEvent =
+  | { type: "created" | "updated" | "reordered"; procedure: Procedure }
+  | { type: "deleted"; id: number }
+  | { type: "resync" };
+
+export function subscribeToProcedures(onEvent: (event: ProcedureStreamEvent) => void): () => void {
+  const source = new EventSource(`${API_BASE_URL}/procedures/stream`);
+
+  for (const type of ["created", "updated", "reordered"] as const) {
+    source.addEventListener(type, (message) => {
+      onEvent({ type, procedure: JSON.parse((message as MessageEvent).data) });
+    });
+  }
+  source.addEventListener("deleted", (message) => {
+    onEvent({ type: "deleted", id: JSON.parse((message as MessageEvent).data).id });
+  });
+  source.addEventListener("resync", () => onEvent({ type: "resync" }));
+
+  return () => source.close();
+}
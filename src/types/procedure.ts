@@ -1,17 +1,50 @@
+export type ProcedureStatus = "draft" | "in_review" | "published" | "archived";
+
 export interface Procedure {
   id: number;
+  slug: string;
+  parent_id: number | null;
   title: string;
   type: string;
-  content: string;
+  content: ProcedureContentItem[];
   sort_order: number;
   is_expanded: boolean;
+  status: ProcedureStatus;
+  version: number;
+  publish_at?: string;
+  unpublish_at?: string;
   created_at: string;
   updated_at: string;
+  deleted_at?: string;
+  tags: string[];
 }
 
+export type ProcedureContentItemType =
+  | "text"
+  | "link"
+  | "document"
+  | "warning"
+  | "step";
+
 export interface ProcedureContentItem {
-  type?: string;
+  type: ProcedureContentItemType;
   value: string;
   url?: string;
+  attachment_id?: number;
+  requirement?: DocumentRequirement;
+}
+
+// Требование к документу заявления об утрате или повреждении
+export interface DocumentRequirement {
+  code: string;
+  required: boolean;
+  claim_kinds?: ("loss" | "damage")[];
+  content_types?: string[];
 }
 
+
+export interface ProcedurePage {
+  items: Procedure[];
+  next_cursor?: string;
+  total: number;
+}